@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+	"github.com/chetankale/wikipedia-extraction/internal/output"
+)
+
+// archivePageHTML writes the HTML of an extracted page to the --save-html
+// directory, returning the file's path, or "" when --save-html is not set.
+// Files are named after the page title and revision, so archiving a revision
+// again overwrites its file; pages without a revision ID are named after the
+// extraction time instead.
+func archivePageHTML(result *extractor.ExtractResult, extractedAt time.Time) (string, error) {
+	if saveHTML == "" || result.PageHTML == nil {
+		return "", nil
+	}
+	if err := os.MkdirAll(saveHTML, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create HTML archive directory: %w", err)
+	}
+
+	version := extractedAt.UTC().Format("20060102T150405Z")
+	if result.RevisionID != 0 {
+		version = strconv.FormatInt(result.RevisionID, 10)
+	}
+	path := filepath.Join(saveHTML, output.SanitizeFileName(result.Title)+"_"+version+".html")
+	if err := os.WriteFile(path, result.PageHTML, 0o644); err != nil {
+		return "", fmt.Errorf("failed to save page HTML: %w", err)
+	}
+	return path, nil
+}
+
+// archivePage archives the page's HTML with archivePageHTML, reporting where
+// it was saved. Failures exit the process.
+func archivePage(result *extractor.ExtractResult, extractedAt time.Time) {
+	path, err := archivePageHTML(result, extractedAt)
+	if err != nil {
+		log.Fatalf("Failed to archive page: %v", err)
+	}
+	if path != "" {
+		fmt.Printf("Saved page HTML to %s\n", path)
+	}
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+// checkpoint is a file listing the URLs a batch run has completed, one per
+// line, so that a rerun after an interruption can skip them
+type checkpoint struct {
+	path    string
+	done    map[string]bool
+	file    *os.File
+	skipped int
+}
+
+// openCheckpoint loads the URLs already recorded in the checkpoint file at
+// path, creating the file if it doesn't exist, and opens it for appending
+func openCheckpoint(path string) (*checkpoint, error) {
+	c := &checkpoint{path: path, done: make(map[string]bool)}
+
+	existing, err := os.Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to open checkpoint file: %w", err)
+	}
+	if err == nil {
+		scanner := bufio.NewScanner(existing)
+		for scanner.Scan() {
+			if url := strings.TrimSpace(scanner.Text()); url != "" {
+				c.done[url] = true
+			}
+		}
+		existing.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read checkpoint file: %w", err)
+		}
+	}
+
+	c.file, err = os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint file: %w", err)
+	}
+	return c, nil
+}
+
+// skip reports whether url was completed by an earlier run, counting it as
+// skipped if so. Mobile and desktop URLs of a page count as the same URL.
+func (c *checkpoint) skip(url string) bool {
+	if !c.done[extractor.DesktopURL(url)] {
+		return false
+	}
+	c.skipped++
+	return true
+}
+
+// record marks url as completed. Each URL is written as soon as it is done,
+// so the file is current whenever the run is interrupted.
+func (c *checkpoint) record(url string) error {
+	url = extractor.DesktopURL(url)
+	if c.done[url] {
+		return nil
+	}
+	c.done[url] = true
+	if _, err := fmt.Fprintln(c.file, url); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+	return nil
+}
+
+// close closes the checkpoint file
+func (c *checkpoint) close() error {
+	return c.file.Close()
+}
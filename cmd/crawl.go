@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	crawlDepth    int
+	crawlMaxPages int
+	crawlDelay    time.Duration
+	crawlTag      string
+)
+
+// crawlPage is a page waiting in the crawl frontier
+type crawlPage struct {
+	url   string
+	depth int
+}
+
+// crawlFrontier holds the pages a crawl has yet to fetch, breadth first, and
+// the pages it has already seen, so that each page is crawled once whichever
+// URL links to it
+type crawlFrontier struct {
+	pages      []crawlPage
+	seen       pageSet
+	crawled    pageSet
+	discovered int
+}
+
+// newCrawlFrontier starts a frontier at the seed page
+func newCrawlFrontier(seed string) *crawlFrontier {
+	f := &crawlFrontier{seen: make(pageSet), crawled: make(pageSet)}
+	f.push(seed, 0)
+	return f
+}
+
+// push queues the page at url unless it was seen before, reporting whether it was queued
+func (f *crawlFrontier) push(url string, depth int) bool {
+	if !f.seen.add(url) {
+		return false
+	}
+	f.pages = append(f.pages, crawlPage{url: url, depth: depth})
+	f.discovered++
+	return true
+}
+
+// pop removes the next page to fetch from the frontier
+func (f *crawlFrontier) pop() (crawlPage, bool) {
+	if len(f.pages) == 0 {
+		return crawlPage{}, false
+	}
+	page := f.pages[0]
+	f.pages = f.pages[1:]
+	return page, true
+}
+
+// fetched records the canonical URL of a fetched page, so that links to it
+// aren't followed again, and reports false if the page was already crawled
+// under another URL, such as a redirect queued before its target was fetched
+func (f *crawlFrontier) fetched(canonicalURL string) bool {
+	f.seen.add(canonicalURL)
+	return f.crawled.add(canonicalURL)
+}
+
+// queueLinks queues the links of a page at depth that follow accepts and
+// that weren't seen before, one link further from the seed, returning how
+// many were queued
+func (f *crawlFrontier) queueLinks(links []string, depth int, follow func(string) bool) int {
+	queued := 0
+	for _, link := range links {
+		if !follow(link) {
+			continue
+		}
+		if f.push(link, depth+1) {
+			queued++
+		}
+	}
+	return queued
+}
+
+var crawlCmd = &cobra.Command{
+	Use:   "crawl [seed URL]",
+	Short: "Extract a page and the articles its infobox links to",
+	Long: `Extract and store a seed page, then follow the article links in its
+infobox to extract and store related entities, breadth first.
+
+With --follow-see-also the articles listed in each page's "See also"
+section are followed too. Only articles on the seed's wiki are followed. --depth limits how many links
+away from the seed the crawl goes, and --max-pages how many pages it fetches.
+Each page is crawled once whichever URL links to it: mobile and desktop
+URLs, spellings of the title and redirects to the page count as one.
+Requests are spaced by --delay.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		seed := args[0]
+
+		// Create extractor
+		ext, err := newExtractor()
+		if err != nil {
+			log.Fatalf("Failed to configure extractor: %v", err)
+		}
+		if err := ext.ValidateURL(seed); err != nil {
+			exitWithExtractError(err)
+		}
+		if err := ext.SetRateLimit(crawlDelay, 1); err != nil {
+			log.Fatalf("Failed to configure extractor: %v", err)
+		}
+
+		// Initialize storage
+		dbPath := "quads.db"
+		store, err := storage.NewSQLiteStorage(dbPath)
+		if err != nil {
+			log.Fatalf("Failed to initialize storage: %v", err)
+		}
+		defer store.Close()
+
+		frontier := newCrawlFrontier(seed)
+		follow := func(link string) bool {
+			return extractor.IsArticleURL(link) && ext.ValidateURL(link) == nil
+		}
+		var fetched, skipped, failed, quads int
+
+		for fetched < crawlMaxPages {
+			page, ok := frontier.pop()
+			if !ok {
+				break
+			}
+			fetched++
+
+			result, err := ext.Extract(page.url)
+			if err != nil {
+				failed++
+				fmt.Printf("FAIL [depth %d] %s: %v\n", page.depth, page.url, err)
+				if page.url == seed {
+					exitWithExtractError(err)
+				}
+				continue
+			}
+			if !frontier.fetched(result.SubjectURL) {
+				skipped++
+				fmt.Printf("SKIP [depth %d] %s: already crawled as %s\n", page.depth, page.url, result.SubjectURL)
+				continue
+			}
+			if result.NoStructuredData {
+				warnNoStructuredData(page.url)
+			}
+			warnUnresolvedCitations(page.url, result)
+			warnValidationFailures(page.url, result)
+			archivePage(result, time.Now())
+
+			if err := saveExtraction(store, page.url, result, time.Now(), crawlTag); err != nil {
+				log.Fatalf("Failed to store data: %v", err)
+			}
+			quads += len(result.Quads)
+
+			// Queue the pages linked from this one, unless they are too far from the seed
+			queued := 0
+			if page.depth < crawlDepth {
+				links := result.Links
+				if followSeeAlso {
+					links = append(links, result.SeeAlso...)
+				}
+				queued = frontier.queueLinks(links, page.depth, follow)
+			}
+			fmt.Printf("OK   [depth %d] %s (%d quads, %d new links)\n", page.depth, page.url, len(result.Quads), queued)
+		}
+
+		fmt.Printf("\nCrawled %d pages (%d stored, %d already crawled, %d failed) and stored %d quads\n", fetched, fetched-skipped-failed, skipped, failed, quads)
+		fmt.Printf("Discovered %d pages; %d left in the frontier", frontier.discovered, len(frontier.pages))
+		if len(frontier.pages) > 0 {
+			fmt.Printf(" (--max-pages %d reached)", crawlMaxPages)
+		}
+		fmt.Println()
+
+		if failed == fetched {
+			os.Exit(exitGeneralError)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(crawlCmd)
+
+	crawlCmd.Flags().IntVar(&crawlDepth, "depth", 1, "How many links away from the seed to follow (0 extracts only the seed)")
+	crawlCmd.Flags().IntVar(&crawlMaxPages, "max-pages", 50, "Maximum number of pages to fetch")
+	crawlCmd.Flags().DurationVar(&crawlDelay, "delay", time.Second, "Pause between requests")
+	crawlCmd.Flags().StringVar(&crawlTag, "tag", "", "Label the stored quads to query them later with query --tag")
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCrawlFrontier(t *testing.T) {
+	const seed = "https://en.wikipedia.org/wiki/Paris"
+	frontier := newCrawlFrontier(seed)
+	follow := func(link string) bool {
+		return !strings.Contains(link, "Talk:")
+	}
+
+	page, ok := frontier.pop()
+	if !ok || page.url != seed || page.depth != 0 {
+		t.Fatalf("pop() = %+v, %v, want the seed at depth 0", page, ok)
+	}
+	if !frontier.fetched(seed) {
+		t.Fatal("fetched(seed) = false, want true for the first crawl of the seed")
+	}
+
+	queued := frontier.queueLinks([]string{
+		"https://en.wikipedia.org/wiki/Seine",
+		// Other spellings of the seed and of Seine
+		"https://en.m.wikipedia.org/wiki/Paris",
+		"https://en.wikipedia.org/wiki/Seine#Course",
+		"https://en.wikipedia.org/wiki/%C3%8Ele-de-France",
+		"https://en.wikipedia.org/wiki/Île-de-France",
+		"https://en.wikipedia.org/wiki/%c3%8ele-de-France",
+		"https://en.wikipedia.org/wiki/AT%26T",
+		"https://en.wikipedia.org/wiki/AT&T",
+		"https://en.wikipedia.org/wiki/Eiffel Tower",
+		"https://en.wikipedia.org/wiki/Eiffel_Tower",
+		"https://en.wikipedia.org/wiki/Talk:Paris",
+		// A redirect to the Eiffel Tower, queued before its target is fetched
+		"https://en.wikipedia.org/wiki/Tour_Eiffel",
+	}, page.depth, follow)
+	if queued != 5 {
+		t.Errorf("queueLinks queued %d links, want 5", queued)
+	}
+
+	var urls []string
+	for _, page := range frontier.pages {
+		if page.depth != 1 {
+			t.Errorf("%s queued at depth %d, want 1", page.url, page.depth)
+		}
+		urls = append(urls, page.url)
+	}
+	want := []string{
+		"https://en.wikipedia.org/wiki/Seine",
+		"https://en.wikipedia.org/wiki/%C3%8Ele-de-France",
+		"https://en.wikipedia.org/wiki/AT%26T",
+		"https://en.wikipedia.org/wiki/Eiffel Tower",
+		"https://en.wikipedia.org/wiki/Tour_Eiffel",
+	}
+	if !reflect.DeepEqual(urls, want) {
+		t.Errorf("frontier = %q, want %q", urls, want)
+	}
+
+	// The canonical URL of a fetched page stops later links to it
+	if !frontier.fetched("https://en.wikipedia.org/wiki/Eiffel_Tower") {
+		t.Error("fetched(Eiffel_Tower) = false on its first crawl")
+	}
+	if !frontier.fetched("https://en.wikipedia.org/wiki/Louvre") {
+		t.Error("fetched(Louvre) = false on its first crawl")
+	}
+	if n := frontier.queueLinks([]string{"https://en.m.wikipedia.org/wiki/Louvre"}, 1, follow); n != 0 {
+		t.Errorf("queueLinks queued %d links to a crawled page, want 0", n)
+	}
+
+	// The redirect leads to a page already crawled
+	if frontier.fetched("https://en.wikipedia.org/wiki/Eiffel_Tower") {
+		t.Error("fetched(Eiffel_Tower) = true for a page already crawled")
+	}
+
+	if frontier.discovered != 6 {
+		t.Errorf("discovered = %d, want the seed and 5 links", frontier.discovered)
+	}
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+	"github.com/chetankale/wikipedia-extraction/internal/output"
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe [subject]",
+	Short: "Summarize everything stored about a subject",
+	Long: `Summarize the stored quads about one subject, grouped by relationship.
+Each value is listed once, with the number of distinct citations supporting it.
+
+The subject must match exactly, ignoring case. Pass --format json, yaml, csv
+or tsv for machine-readable output.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		subject := args[0]
+
+		// Initialize storage
+		dbPath := "quads.db"
+		store, err := storage.NewSQLiteStorage(dbPath)
+		if err != nil {
+			log.Fatalf("Failed to initialize storage: %v", err)
+		}
+		defer store.Close()
+
+		// GetBySubject matches substrings; keep the subject itself
+		candidates, err := store.GetBySubject(subject)
+		if err != nil {
+			log.Fatalf("Failed to query data: %v", err)
+		}
+		var quads []extractor.Quad
+		others := make(map[string]bool)
+		for _, quad := range candidates {
+			if strings.EqualFold(quad.Subject, subject) {
+				subject = quad.Subject
+				quads = append(quads, quad)
+			} else {
+				others[quad.Subject] = true
+			}
+		}
+
+		if len(quads) == 0 {
+			fmt.Fprintf(os.Stderr, "No quads found for subject %q\n", subject)
+			if len(others) > 0 {
+				names := make([]string, 0, len(others))
+				for name := range others {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				fmt.Fprintf(os.Stderr, "Similar subjects: %s\n", strings.Join(names, ", "))
+			}
+			os.Exit(exitGeneralError)
+		}
+
+		summary := output.SummarizeSubject(subject, quads)
+
+		// Machine-readable output only when --format is given explicitly
+		if cmd.Flags().Changed("format") {
+			formatter, err := newReportFormatter()
+			if err != nil {
+				log.Fatalf("Invalid output options: %v", err)
+			}
+			if err := formatter.WriteSubjectSummary(summary, os.Stdout, format); err != nil {
+				log.Fatalf("Failed to write summary: %v", err)
+			}
+			return
+		}
+
+		if err := output.WriteSubjectSummaryText(summary, os.Stdout); err != nil {
+			log.Fatalf("Failed to write summary: %v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(describeCmd)
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffFrom string
+	diffTo   string
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [URL]",
+	Short: "Compare two stored extractions of a Wikipedia page",
+	Long: `Compare two stored extractions of a Wikipedia page and list the quads
+that were added, removed, or changed between them.
+
+By default the latest extraction is compared with the one before it. --from
+and --to select the latest extraction at or before the given times instead.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		url := args[0]
+
+		now := time.Now()
+		from, err := parseTimeFlag(diffFrom, now)
+		if err != nil {
+			log.Fatalf("Invalid --from: %v", err)
+		}
+		to, err := parseTimeFlag(diffTo, now)
+		if err != nil {
+			log.Fatalf("Invalid --to: %v", err)
+		}
+
+		// Initialize storage
+		dbPath := "quads.db"
+		store, err := storage.NewSQLiteStorage(dbPath)
+		if err != nil {
+			log.Fatalf("Failed to initialize storage: %v", err)
+		}
+		defer store.Close()
+
+		times, err := store.GetExtractionTimes(url)
+		if err != nil {
+			log.Fatalf("Failed to load extraction history: %v", err)
+		}
+		if len(times) == 0 {
+			log.Fatalf("No stored extractions of %s", url)
+		}
+
+		// Resolve the newer snapshot first, then default the older one to the
+		// extraction preceding it
+		if to.IsZero() {
+			to = times[0]
+		}
+		newer, newerAt, err := store.GetSnapshot(url, to)
+		if err != nil {
+			log.Fatalf("Failed to load extraction: %v", err)
+		}
+		if newerAt.IsZero() {
+			log.Fatalf("No extraction of %s at or before %s", url, to.Format(time.RFC3339))
+		}
+
+		if from.IsZero() {
+			for _, t := range times {
+				if t.Before(newerAt) {
+					from = t
+					break
+				}
+			}
+			if from.IsZero() {
+				log.Fatalf("Only one extraction of %s is stored, nothing to compare against", url)
+			}
+		}
+		older, olderAt, err := store.GetSnapshot(url, from)
+		if err != nil {
+			log.Fatalf("Failed to load extraction: %v", err)
+		}
+		if olderAt.IsZero() {
+			log.Fatalf("No extraction of %s at or before %s", url, from.Format(time.RFC3339))
+		}
+
+		diff := extractor.CompareQuads(older, newer)
+
+		// Machine-readable output only when --format is given explicitly
+		if cmd.Flags().Changed("format") {
+			formatter, err := newFormatter()
+			if err != nil {
+				log.Fatalf("Invalid output options: %v", err)
+			}
+			if err := formatter.WriteDiff(diff, os.Stdout, format); err != nil {
+				log.Fatalf("Failed to write diff: %v", err)
+			}
+			return
+		}
+
+		fmt.Printf("Comparing extraction at %s with %s\n",
+			olderAt.Format(time.RFC3339), newerAt.Format(time.RFC3339))
+		printDiff(diff)
+	},
+}
+
+// printDiff lists the added (+), removed (-) and changed (~) quads of a diff
+// for reading in a terminal
+func printDiff(diff extractor.QuadDiff) {
+	fmt.Printf("%d added, %d removed, %d changed\n",
+		len(diff.Added), len(diff.Removed), len(diff.Changed))
+	for _, quad := range diff.Added {
+		fmt.Printf("+ %s | %s | %s | %s\n", quad.Subject, quad.Relationship, quad.Value, displayCitation(quad.Citation))
+	}
+	for _, quad := range diff.Removed {
+		fmt.Printf("- %s | %s | %s | %s\n", quad.Subject, quad.Relationship, quad.Value, displayCitation(quad.Citation))
+	}
+	for _, change := range diff.Changed {
+		fmt.Printf("~ %s | %s | %s -> %s\n",
+			change.New.Subject, change.New.Relationship, change.Old.Value, change.New.Value)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&diffFrom, "from", "", "Compare from the latest extraction at or before this time (RFC3339, YYYY-MM-DD, or relative like 7d); defaults to the extraction before --to")
+	diffCmd.Flags().StringVar(&diffTo, "to", "", "Compare to the latest extraction at or before this time; defaults to the most recent extraction")
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+// Exit codes returned for the different kinds of extraction failure
+const (
+	exitGeneralError        = 1
+	exitInvalidURL          = 2
+	exitFetchFailed         = 3
+	exitNoInfobox           = 4
+	exitDisambiguation      = 5
+	exitNotFound            = 6
+	exitUnresolvedCitations = 7
+)
+
+// extractExitCode maps an extraction error to a process exit code
+func extractExitCode(err error) int {
+	switch {
+	case errors.Is(err, extractor.ErrInvalidURL):
+		return exitInvalidURL
+	case errors.Is(err, extractor.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, extractor.ErrFetchFailed):
+		return exitFetchFailed
+	case errors.Is(err, extractor.ErrNoInfobox), errors.Is(err, extractor.ErrNoStructuredData):
+		return exitNoInfobox
+	case errors.Is(err, extractor.ErrDisambiguation):
+		return exitDisambiguation
+	case errors.Is(err, extractor.ErrUnresolvedCitations):
+		return exitUnresolvedCitations
+	default:
+		return exitGeneralError
+	}
+}
+
+// exitWithExtractError logs an extraction error and exits with the matching exit code
+func exitWithExtractError(err error) {
+	log.Printf("Failed to extract data: %v", err)
+	os.Exit(extractExitCode(err))
+}
+
+// warnNoStructuredData tells the user on stderr that a page yielded nothing
+// because it has no infobox or table
+func warnNoStructuredData(url string) {
+	fmt.Fprintf(os.Stderr, "WARNING: %s has no infobox or tables, so no quads were extracted. It may be a stub or a prose-only article (use --strict to treat this as an error)\n", url)
+}
+
+// warnUnresolvedCitations tells the user on stderr that some of a page's
+// footnote markers link to no reference, when --strict-citations asks for it
+func warnUnresolvedCitations(url string, result *extractor.ExtractResult) {
+	policy, _ := extractor.ParseCitationPolicy(strictCitations)
+	if policy != extractor.CitationPolicyWarn || result.UnresolvedCitations == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "WARNING: %d footnote markers on %s link to no reference, so their quads may be missing citations\n", result.UnresolvedCitations, url)
+}
+
+// warnValidationFailures tells the user on stderr which of a page's values
+// failed the --validate-values rule of their relationship
+func warnValidationFailures(url string, result *extractor.ExtractResult) {
+	policy, _ := extractor.ParseValidationPolicy(validateValues)
+	for _, failure := range result.ValidationFailures {
+		action := "kept"
+		if policy == extractor.ValidationPolicyDrop {
+			action = "dropped"
+		}
+		fmt.Fprintf(os.Stderr, "WARNING: %s on %s is not a valid %s, %s: %s | %s | %s\n",
+			failure.Relationship, url, failure.Validator, action, failure.Subject, failure.Relationship, failure.Value)
+	}
+}
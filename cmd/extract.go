@@ -4,67 +4,327 @@ import (
 	"fmt"
 	"os"
 	"log"
-	"strings"
+	"time"
 
 	"github.com/chetankale/wikipedia-extraction/internal/extractor"
 	"github.com/chetankale/wikipedia-extraction/internal/output"
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
 	"github.com/spf13/cobra"
 )
 
+var (
+	outputDir    string
+	previewQuads int
+	// subjectOverride replaces the page title as the subject in extract and store
+	subjectOverride string
+	compareStored   bool
+	summaryOnly     bool
+	extractAsync    bool
+	parallelism     int
+)
+
+// defaultPreviewQuads is how many quads extract and store preview by default
+const defaultPreviewQuads = 5
+
 var extractCmd = &cobra.Command{
-	Use:   "extract [URL]",
-	Short: "Extract structured data from a Wikipedia page",
-	Long: `Extract structured information from a Wikipedia page URL.
+	Use:   "extract [URL...]",
+	Short: "Extract structured data from Wikipedia pages",
+	Long: `Extract structured information from one or more Wikipedia page URLs.
 The tool will parse infoboxes and extract quads in the form of:
-(subject/entity, relationship, value, citation)`,
-	Args: cobra.ExactArgs(1),
+(subject/entity, relationship, value, citation)
+
+Quads from all pages are written to --output, or to one file per page
+(named after the page title) when --output-dir is set.
+
+With no URL arguments, URLs are read from stdin one per line, e.g.
+cat urls.txt | wikipedia-extraction extract. Blank lines and lines
+starting with # are ignored. A URL from stdin that fails is reported and
+skipped; the quads of the other URLs are still written, and the command
+exits non-zero at the end.
+
+With --compare-stored nothing is written to --output. Each page's fresh
+quads are compared with its latest stored extraction instead, listing what
+a store would add, remove or change, without storing anything.
+
+With --summary-only each page's title, short description, lead paragraph
+and thumbnail are read from the REST API's page summary instead of
+scraping the page, which is faster but yields no infobox data.
+
+With --async all pages are requested concurrently, at most --parallelism
+at a time, instead of one after another. URLs from stdin are read in full
+before the first request. Results are still reported and written in the
+order the URLs were given.`,
+	Args: requireURLsOrStdin(0),
 	Run: func(cmd *cobra.Command, args []string) {
-		url := args[0]
-		
-		// Validate URL
-		if !strings.Contains(url, "wikipedia.org") {
-			log.Fatal("URL must be a Wikipedia page")
+		// Create extractor
+		ext, err := newExtractor()
+		if err != nil {
+			log.Fatalf("Failed to configure extractor: %v", err)
 		}
 
-		// Create extractor
-		ext := extractor.NewExtractor()
+		// Validate URLs
+		for _, url := range args {
+			if err := ext.ValidateURL(url); err != nil {
+				exitWithExtractError(err)
+			}
+		}
 
-		// Extract data
-		quads, err := ext.ExtractFromURL(url)
+		// Configure output before fetching so invalid options fail fast
+		formatter, err := newFormatter()
 		if err != nil {
-			log.Fatalf("Failed to extract data: %v", err)
+			log.Fatalf("Invalid output options: %v", err)
 		}
 
-		// Output results
-		fmt.Printf("Extracted %d quads from %s\n", len(quads), url)
-		
-		fileWriter, err := os.Create(outputFile)
+		if outputDir != "" {
+			if err := os.MkdirAll(outputDir, 0o755); err != nil {
+				log.Fatalf("Failed to create output directory: %v", err)
+			}
+		}
+
+		deduplicator, err := newDeduplicator()
 		if err != nil {
-			fmt.Errorf("failed to create output file: %w", err)
-			return
+			log.Fatalf("Failed to configure deduplication: %v", err)
 		}
-		defer fileWriter.Close()
 
-		// Save to file
-		formatter := output.NewFormatter()
-		if err := formatter.WriteQuads(quads, fileWriter, format); err != nil {
-			log.Fatalf("Failed to write output: %v", err)
+		var store storage.Storage
+		if compareStored {
+			store, err = storage.NewSQLiteStorage("quads.db")
+			if err != nil {
+				log.Fatalf("Failed to initialize storage: %v", err)
+			}
+			defer store.Close()
 		}
-		
-		fmt.Printf("Results saved to %s in %s format\n", outputFile, format)
-		
-		// Display first few quads as preview
-		fmt.Println("\nPreview of extracted data:")
-		for i, quad := range quads {
-			if i >= 5 { // Show only first 5
-				break
-			}
-			fmt.Printf("Quad %d: %s | %s | %s | %s\n", 
-				i+1, quad.Subject, quad.Relationship, quad.Value, quad.Citation)
+		// Machine-readable diffs of all pages are written together at the end
+		machineDiff := compareStored && cmd.Flags().Changed("format")
+		var diffs extractor.QuadDiff
+
+		if extractAsync {
+			if summaryOnly {
+				log.Fatalf("--async can't be combined with --summary-only")
+			}
+			if err := ext.SetRateLimit(0, parallelism); err != nil {
+				log.Fatalf("Failed to configure extractor: %v", err)
+			}
+		}
+
+		var quads []extractor.Quad
+		handleResult := func(url string, result *extractor.ExtractResult) {
+			// Output results
+			if result.NoStructuredData {
+				warnNoStructuredData(url)
+			}
+			warnUnresolvedCitations(url, result)
+			warnValidationFailures(url, result)
+			archivePage(result, time.Now())
+			result.Quads = deduplicator.Filter(result.Quads)
+			if compareStored {
+				diff := compareWithStored(store, url, result, !machineDiff)
+				diffs.Added = append(diffs.Added, diff.Added...)
+				diffs.Removed = append(diffs.Removed, diff.Removed...)
+				diffs.Changed = append(diffs.Changed, diff.Changed...)
+				return
+			}
+			fmt.Printf("Extracted %d quads from %s\n", len(result.Quads), url)
+			if result.Truncated {
+				fmt.Printf("Extraction stopped at the --max-quads limit of %d\n", maxQuads)
+			}
+			if n := countTruncatedFields(result.Quads); n > 0 {
+				fmt.Printf("Truncated %d quads over --max-value-length or --max-citation-length\n", n)
+			}
+			if result.SkippedLongQuads > 0 {
+				fmt.Printf("Skipped %d quads over --max-value-length or --max-citation-length\n", result.SkippedLongQuads)
+			}
+			if verbose && result.SkippedRows > 0 {
+				fmt.Printf("Skipped %d infobox rows with a label but no value or a value but no label\n", result.SkippedRows)
+			}
+			if len(result.SeeAlso) > 0 {
+				fmt.Printf("See also %d related articles:\n", len(result.SeeAlso))
+				for _, link := range result.SeeAlso {
+					fmt.Printf("  %s\n", link)
+				}
+			}
+
+			if outputDir != "" {
+				path := output.UniqueFilePath(outputDir, result.Title, format)
+				if err := writeQuadsFile(formatter, result.Quads, path); err != nil {
+					log.Fatalf("Failed to write output: %v", err)
+				}
+				fmt.Printf("Results saved to %s in %s format\n", path, format)
+			}
+
+			quads = append(quads, result.Quads...)
+		}
+		// A URL argument that fails stops the run, while a URL from stdin
+		// that fails is reported and skipped, and the run exits non-zero once
+		// the quads of the other URLs are written
+		var failures urlFailures
+		failed := func(url string, err error) {
+			if len(args) > 0 {
+				exitWithExtractError(err)
+			}
+			failures.record(url, err)
 		}
+		extractURL := func(url string) {
+			// Extract data
+			result, err := extractPage(ext, url)
+			if err != nil {
+				failed(url, err)
+				return
+			}
+			handleResult(url, result)
+		}
+
+		switch {
+		case extractAsync:
+			urls := args
+			if len(urls) == 0 {
+				err := eachURL(os.Stdin, func(url string) {
+					if err := ext.ValidateURL(url); err != nil {
+						failed(url, err)
+						return
+					}
+					urls = append(urls, url)
+				})
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
+			// Pages finish in any order, but are handled in the order given
+			for _, page := range ext.ExtractAll(urls) {
+				if page.Err != nil {
+					failed(page.URL, page.Err)
+					continue
+				}
+				handleResult(page.URL, page.Result)
+			}
+		case len(args) > 0:
+			for _, url := range args {
+				extractURL(url)
+			}
+		default:
+			// URLs piped in on stdin are validated and extracted as they arrive
+			err := eachURL(os.Stdin, func(url string) {
+				if err := ext.ValidateURL(url); err != nil {
+					failed(url, err)
+					return
+				}
+				extractURL(url)
+			})
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		reportDeduplicated(deduplicator)
+		if compareStored {
+			if machineDiff {
+				if err := formatter.WriteDiff(diffs, os.Stdout, format); err != nil {
+					log.Fatalf("Failed to write diff: %v", err)
+				}
+			}
+			failures.exit()
+			return
+		}
+
+		if outputDir == "" {
+			// Save to file
+			if err := writeQuadsFile(formatter, quads, outputFile); err != nil {
+				log.Fatalf("Failed to write output: %v", err)
+			}
+
+			fmt.Printf("Results saved to %s in %s format\n", outputFile, format)
+		}
+
+		printPreview(quads)
+		failures.exit()
 	},
 }
 
+// extractPage extracts url, or only its REST API summary with --summary-only
+func extractPage(ext *extractor.Extractor, url string) (*extractor.ExtractResult, error) {
+	if !summaryOnly {
+		return ext.Extract(url)
+	}
+	title, lang, err := extractor.SummaryTitle(url)
+	if err != nil {
+		return nil, err
+	}
+	return ext.ExtractSummary(title, lang)
+}
+
+// compareWithStored compares a fresh extraction of url with the latest stored
+// extraction of the same source, printing the comparison when print is set
+func compareWithStored(store storage.Storage, url string, result *extractor.ExtractResult, print bool) extractor.QuadDiff {
+	// Mobile and desktop URLs of a page share one source
+	url = extractor.DesktopURL(url)
+	times, err := store.GetExtractionTimes(url)
+	if err != nil {
+		log.Fatalf("Failed to load extraction history: %v", err)
+	}
+	stored, err := store.GetLatestBySourceURL(url)
+	if err != nil {
+		log.Fatalf("Failed to load stored extraction: %v", err)
+	}
+	diff := extractor.CompareQuads(stored, result.Quads)
+
+	if print {
+		if len(times) == 0 {
+			fmt.Printf("Comparing live extraction of %s with nothing stored\n", url)
+		} else {
+			fmt.Printf("Comparing live extraction of %s with stored extraction at %s\n", url, times[0].Format(time.RFC3339))
+		}
+		printDiff(diff)
+	}
+	return diff
+}
+
+// countTruncatedFields counts the quads cut short by the length limits
+func countTruncatedFields(quads []extractor.Quad) int {
+	n := 0
+	for _, quad := range quads {
+		if len(quad.TruncatedFields) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// printPreview shows the first --preview quads on stderr, keeping stdout
+// free for the command's output
+func printPreview(quads []extractor.Quad) {
+	if previewQuads <= 0 || len(quads) == 0 {
+		return
+	}
+	fmt.Fprintln(os.Stderr, "\nPreview of extracted data:")
+	for i, quad := range quads[:min(previewQuads, len(quads))] {
+		fmt.Fprintf(os.Stderr, "Quad %d: %s | %s | %s | %s\n",
+			i+1, quad.Subject, quad.Relationship, quad.Value, displayCitation(quad.Citation))
+	}
+}
+
+// writeQuadsFile writes quads to the file at path using the global format
+func writeQuadsFile(formatter *output.Formatter, quads []extractor.Quad, path string) error {
+	fileWriter, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer fileWriter.Close()
+
+	if err := formatter.WriteQuads(quads, fileWriter, format); err != nil {
+		return err
+	}
+
+	return fileWriter.Close()
+}
+
 func init() {
 	rootCmd.AddCommand(extractCmd)
-} 
\ No newline at end of file
+
+	extractCmd.Flags().StringVar(&outputDir, "output-dir", "", "write each page's quads to a separate file in this directory, named after the page title")
+	extractCmd.Flags().StringVar(&subjectOverride, "subject", "", "use this as the subject of the page's quads instead of the page title")
+	extractCmd.Flags().BoolVar(&compareStored, "compare-stored", false, "compare the fresh quads with the latest stored extraction of each page instead of writing them, without storing")
+	extractCmd.Flags().BoolVar(&summaryOnly, "summary-only", false, "read only the title, short description, lead paragraph and thumbnail from the REST API's page summary instead of parsing the page")
+	extractCmd.Flags().BoolVar(&extractAsync, "async", false, "fetch all pages concurrently instead of one after another, still writing them in the order given")
+	extractCmd.Flags().IntVar(&parallelism, "parallelism", 4, "with --async, how many pages to fetch at once")
+	extractCmd.Flags().IntVar(&previewQuads, "preview", defaultPreviewQuads, "number of extracted quads to preview on stderr (0 disables the preview)")
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+	"github.com/spf13/viper"
+)
+
+// newDeduplicator creates the cross-page deduplicator configured by --dedupe,
+// or nil when it is off
+func newDeduplicator() (*extractor.Deduplicator, error) {
+	if !dedupe {
+		return nil, nil
+	}
+	return extractor.NewDeduplicator(dedupeCapacity, dedupeFalsePositiveRate)
+}
+
+// reportDeduplicated says how many quads --dedupe suppressed
+func reportDeduplicated(deduplicator *extractor.Deduplicator) {
+	if deduplicator != nil && deduplicator.Suppressed > 0 {
+		fmt.Printf("Dropped %d quads already extracted from earlier pages (--dedupe)\n", deduplicator.Suppressed)
+	}
+}
+
+// relationshipMapping is a config file entry mapping label synonyms to a canonical relationship
+type relationshipMapping struct {
+	Canonical string   `mapstructure:"canonical"`
+	Synonyms  []string `mapstructure:"synonyms"`
+}
+
+// newExtractor creates an extractor configured from the global flags
+func newExtractor() (*extractor.Extractor, error) {
+	ext := extractor.NewExtractor()
+	ext.Options.MaxQuads = maxQuads
+	ext.Options.MaxBodyBytes = maxBodyBytes
+	ext.Options.AllowSisterProjects = allowSisterProjects
+	ext.Options.KeepHTML = keepHTML
+	ext.Options.KeepPageHTML = saveHTML != ""
+	ext.Options.CitationSeparator = citationSeparator
+	ext.Options.MaxCitations = maxCitations
+	ext.Options.MaxCitationDepth = citationDepth
+	ext.Options.OnlyWithCitations = onlyWithCitations
+	ext.Options.Strict = strict
+	ext.Options.NormalizeNumbers = normalizeNumbers
+	ext.Options.FirstOnly = firstOnly
+	ext.Options.SplitUnits = splitUnits
+	ext.Options.CountryCodes = countryCodes
+	ext.Options.TypedTables = typedTables
+	ext.Options.InferSubjectType = inferType
+	ext.Options.ShortDescription = shortDescription
+	ext.Options.FollowSeeAlso = followSeeAlso
+	ext.Options.Subject = subjectOverride
+	ext.Options.MaxValueLength = maxValueLength
+	ext.Options.MaxCitationLength = maxCitationLength
+	policy, err := extractor.ParseLengthPolicy(lengthPolicy)
+	if err != nil {
+		return nil, err
+	}
+	ext.Options.LengthPolicy = policy
+	citationPolicy, err := extractor.ParseCitationPolicy(strictCitations)
+	if err != nil {
+		return nil, err
+	}
+	ext.Options.CitationPolicy = citationPolicy
+	validationPolicy, err := extractor.ParseValidationPolicy(validateValues)
+	if err != nil {
+		return nil, err
+	}
+	ext.Options.ValidationPolicy = validationPolicy
+	if validationPolicy != extractor.ValidationPolicyOff {
+		ext.Options.ValidationRules = extractor.DefaultValidationRules()
+		for relationship, name := range viper.GetStringMapString("validation") {
+			validator, err := extractor.ParseValidator(name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid validation config for %q: %w", relationship, err)
+			}
+			ext.Options.ValidationRules.Add(relationship, validator)
+		}
+	}
+	if verbose {
+		ext.Options.Logger = slog.New(slog.NewTextHandler(os.Stderr, nil))
+	}
+
+	if noCanonicalize {
+		ext.Options.Relationships = nil
+	} else {
+		var mappings []relationshipMapping
+		if err := viper.UnmarshalKey("relationships", &mappings); err != nil {
+			return nil, fmt.Errorf("invalid relationships config: %w", err)
+		}
+		for _, mapping := range mappings {
+			ext.Options.Relationships.Add(mapping.Canonical, mapping.Synonyms...)
+		}
+	}
+	if profile != "" {
+		p, err := loadProfile(profile)
+		if err != nil {
+			return nil, err
+		}
+		p.apply(ext)
+	}
+
+	if wikiBaseURL != "" {
+		// Credentials may come from the environment to keep them out of process listings
+		cookie, token := wikiCookie, wikiToken
+		if cookie == "" {
+			cookie = viper.GetString("wiki_cookie")
+		}
+		if token == "" {
+			token = viper.GetString("wiki_token")
+		}
+		wiki, err := extractor.NewPrivateWiki(wikiBaseURL, cookie, token)
+		if err != nil {
+			return nil, err
+		}
+		ext.Options.PrivateWiki = wiki
+	}
+
+	// The transport must be in place before proxies, which are set on it
+	if insecureSkipVerify {
+		ext.SetTransport(extractor.InsecureTransport())
+	}
+	if err := ext.SetTimeout(timeout); err != nil {
+		return nil, err
+	}
+
+	proxies := proxyURLs
+	if proxyFile != "" {
+		fromFile, err := readProxyFile(proxyFile)
+		if err != nil {
+			return nil, err
+		}
+		proxies = append(proxies, fromFile...)
+	}
+	if len(proxies) > 0 {
+		if err := ext.SetProxies(proxies); err != nil {
+			return nil, err
+		}
+	}
+
+	return ext, nil
+}
+
+// readProxyFile reads proxy URLs from a file, one per line. Blank lines and
+// lines starting with # are ignored.
+func readProxyFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open proxy file: %w", err)
+	}
+	defer file.Close()
+
+	var proxies []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		proxies = append(proxies, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read proxy file: %w", err)
+	}
+
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("proxy file %s contains no proxies", path)
+	}
+
+	return proxies, nil
+}
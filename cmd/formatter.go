@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+	"github.com/chetankale/wikipedia-extraction/internal/output"
+)
+
+// newFormatter creates a formatter configured from the global flags
+func newFormatter() (*output.Formatter, error) {
+	if !output.IsFormat(format) {
+		return nil, fmt.Errorf("unsupported output format %q (valid formats: %s)", format, strings.Join(output.Formats(), ", "))
+	}
+
+	formatter, err := newReportFormatter()
+	if err != nil {
+		return nil, err
+	}
+	formatter.WithSchema = withSchema
+
+	if columns != "" {
+		cols, err := output.ParseColumns(columns)
+		if err != nil {
+			return nil, err
+		}
+		formatter.Columns = cols
+	}
+
+	formatter.MaxCellLength = maxCellLength
+
+	if baseIRI != "" {
+		iri, err := output.ParseNamespaceIRI(baseIRI)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --base-iri: %w", err)
+		}
+		formatter.BaseIRI = iri
+	}
+	if predicateNamespace != "" {
+		iri, err := output.ParseNamespaceIRI(predicateNamespace)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --predicate-namespace: %w", err)
+		}
+		formatter.PredicateNamespace = iri
+	}
+
+	if outputTemplate != "" {
+		tmpl, err := output.ParseTemplate(outputTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --template: %w", err)
+		}
+		formatter.Template = tmpl
+	}
+
+	return formatter, nil
+}
+
+// newReportFormatter creates a formatter for the describe, corroborated and
+// stats reports, whose formats are not quad output formats, configured from
+// the global flags that apply to them
+func newReportFormatter() (*output.Formatter, error) {
+	formatter := output.NewFormatter()
+	formatter.Indent = jsonIndent()
+	formatter.BOM = csvBOM
+	if csvHeaders != "" {
+		headers, err := output.ParseHeaders(csvHeaders)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --csv-headers: %w", err)
+		}
+		formatter.Headers = headers
+	}
+	return formatter, nil
+}
+
+// jsonIndent returns the JSON indentation selected by --compact and --indent
+func jsonIndent() string {
+	if compact || indentWidth <= 0 {
+		return ""
+	}
+	return strings.Repeat(" ", indentWidth)
+}
+
+// displayCitation returns a citation for human-readable output, substituting
+// the "no citation" placeholder for empty citations when --show-no-citation is set
+func displayCitation(citation string) string {
+	if citation == "" && showNoCitation {
+		return extractor.NoCitation
+	}
+	return citation
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/chetankale/wikipedia-extraction/internal/output"
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export the links between stored subjects as an adjacency list",
+	Long: `Export the links between stored subjects as a JSON adjacency list.
+
+A subject links to another when one of its quads has the other subject's
+name as its value. The output maps each subject to its outgoing edges:
+
+  {"Albert Einstein": [{"target": "Ulm", "relationship": "Born"}]}
+
+The result is written to stdout, or to --output when it is given.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Initialize storage
+		dbPath := "quads.db"
+		store, err := storage.NewSQLiteStorage(dbPath)
+		if err != nil {
+			log.Fatalf("Failed to initialize storage: %v", err)
+		}
+		defer store.Close()
+
+		links, err := store.GetSubjectLinks()
+		if err != nil {
+			log.Fatalf("Failed to load subject links: %v", err)
+		}
+
+		formatter := output.NewFormatter()
+		formatter.Indent = jsonIndent()
+
+		if !cmd.Flags().Changed("output") {
+			if err := formatter.WriteAdjacencyList(links, os.Stdout); err != nil {
+				log.Fatalf("Failed to write graph: %v", err)
+			}
+			return
+		}
+
+		file, err := os.Create(outputFile)
+		if err != nil {
+			log.Fatalf("Failed to create output file: %v", err)
+		}
+		defer file.Close()
+
+		if err := formatter.WriteAdjacencyList(links, file); err != nil {
+			log.Fatalf("Failed to write graph: %v", err)
+		}
+		if err := file.Close(); err != nil {
+			log.Fatalf("Failed to write graph: %v", err)
+		}
+		fmt.Printf("Wrote %d links to %s\n", len(links), outputFile)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+}
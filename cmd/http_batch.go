@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+	"github.com/chetankale/wikipedia-extraction/internal/output"
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+)
+
+const (
+	// batchConcurrency bounds how many pages of a batch are fetched at once
+	batchConcurrency = 4
+
+	// maxBatchBodyBytes caps the size of a /batch request body
+	maxBatchBodyBytes = 1 << 20
+)
+
+// batchRequest is the JSON body accepted by the /batch endpoint
+type batchRequest struct {
+	URLs  []string `json:"urls"`
+	Store bool     `json:"store"`
+	// Tag labels the stored quads
+	Tag string `json:"tag"`
+	// Incremental skips pages unchanged since their last stored fetch, as
+	// store --incremental does
+	Incremental bool `json:"incremental"`
+}
+
+// batchResult is the outcome of extracting one URL of a batch. Status is the
+// HTTP status the URL would have produced on its own at /extract, or at
+// /store when the batch is stored, so a failed write is a 500.
+type batchResult struct {
+	URL       string           `json:"url"`
+	Status    int              `json:"status"`
+	Error     string           `json:"error,omitempty"`
+	Quads     []extractor.Quad `json:"quads,omitempty"`
+	Truncated bool             `json:"truncated,omitempty"`
+	Stored    bool             `json:"stored,omitempty"`
+	// Unchanged is set when an incremental batch found the page unchanged
+	// since its last stored fetch, so it was neither extracted nor stored
+	Unchanged bool `json:"unchanged,omitempty"`
+	// NoStructuredData is set when the page has no infobox or table
+	NoStructuredData bool `json:"no_structured_data,omitempty"`
+}
+
+// handleBatch extracts every URL in a POSTed batchRequest with bounded
+// concurrency. Results are streamed back as a JSON array, or one JSON object
+// per line with format=jsonl, in completion order as each URL finishes, so a
+// slow page does not hold back the others.
+// Requests that ask to store results write to the shared store, and
+// incremental requests read the validators of earlier fetches from it. Batches of
+// more than maxURLs URLs are rejected with 413, and each URL counts against
+// the client's rate limit, so a batch exceeding it is rejected with 429.
+func handleBatch(live *liveConfig, store storage.Storage, maxURLs int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSONError(w, http.StatusMethodNotAllowed, "Use POST with a JSON body")
+			return
+		}
+
+		var req batchRequest
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes))
+		if err := decoder.Decode(&req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "Invalid JSON body: "+err.Error())
+			return
+		}
+		if len(req.URLs) == 0 {
+			writeJSONError(w, http.StatusBadRequest, "No URLs provided")
+			return
+		}
+		if len(req.URLs) > maxURLs {
+			writeJSONError(w, http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("Too many URLs: %d (maximum %d per batch)", len(req.URLs), maxURLs))
+			return
+		}
+
+		// The whole batch uses the config current when it started
+		config := live.get()
+		if !allowPages(w, r, config.limiter, len(req.URLs)) {
+			return
+		}
+
+		var batchStore, sources storage.Storage
+		if req.Store {
+			batchStore = store
+		}
+		if req.Incremental {
+			sources = store
+		}
+
+		stream := &batchStream{w: w, lines: r.URL.Query().Get("format") == "jsonl"}
+		if stream.lines {
+			w.Header().Set("Content-Type", output.ContentType("jsonl"))
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		setStreamingHeaders(w)
+		stream.begin()
+
+		urls := make(chan string)
+		var wg sync.WaitGroup
+		for i := 0; i < min(batchConcurrency, len(req.URLs)); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for url := range urls {
+					stream.write(extractBatchURL(r, config, batchStore, sources, url, req.Tag))
+				}
+			}()
+		}
+		for _, url := range req.URLs {
+			urls <- url
+		}
+		close(urls)
+		wg.Wait()
+
+		stream.end()
+	}
+}
+
+// extractBatchURL extracts, and optionally stores under tag, a single URL of a
+// batch. With sources set, the fetch is conditional on the validators of the
+// URL's last fetch stored there. The request timeout applies to each URL
+// separately.
+func extractBatchURL(r *http.Request, config *serviceConfig, store, sources storage.Storage, url, tag string) batchResult {
+	var etag, lastModified string
+	if sources != nil {
+		previous, err := sources.GetSource(extractor.DesktopURL(url))
+		if err != nil {
+			log.Printf("Failed to load source metadata for %s: %v", url, err)
+			return batchResult{URL: url, Status: http.StatusInternalServerError, Error: "Failed to load source metadata: " + err.Error()}
+		}
+		if previous != nil {
+			etag, lastModified = previous.ETag, previous.LastModified
+		}
+	}
+
+	result, err := extractIfModifiedForRequest(r, config, url, etag, lastModified)
+	if err != nil {
+		return batchResult{URL: url, Status: extractStatusCode(err), Error: err.Error()}
+	}
+	if result.NotModified {
+		return batchResult{URL: url, Status: http.StatusOK, Unchanged: true}
+	}
+
+	res := batchResult{
+		URL:              url,
+		Status:           http.StatusOK,
+		Quads:            result.Quads,
+		Truncated:        result.Truncated,
+		NoStructuredData: result.NoStructuredData,
+	}
+	if store != nil {
+		if err := saveExtraction(store, url, result, time.Now(), tag); err != nil {
+			log.Printf("Failed to store %s: %v", url, err)
+			res.Status = http.StatusInternalServerError
+			res.Error = "Failed to store data: " + err.Error()
+			return res
+		}
+		res.Stored = true
+	}
+
+	return res
+}
+
+// batchStream writes batch results as elements of a JSON array, or as
+// newline-delimited JSON when lines is set, flushing after each one. It is
+// safe for concurrent use.
+type batchStream struct {
+	mu    sync.Mutex
+	w     http.ResponseWriter
+	lines bool
+	count int
+}
+
+// begin opens the JSON array
+func (s *batchStream) begin() {
+	if !s.lines {
+		io.WriteString(s.w, "[\n")
+	}
+}
+
+// write appends one result to the array and flushes it to the client
+func (s *batchStream) write(result batchResult) {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		encoded, _ = json.Marshal(batchResult{URL: result.URL, Status: http.StatusInternalServerError, Error: err.Error()})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lines {
+		s.w.Write(append(encoded, '\n'))
+	} else {
+		if s.count > 0 {
+			io.WriteString(s.w, ",\n")
+		}
+		s.w.Write(encoded)
+	}
+	s.count++
+	http.NewResponseController(s.w).Flush()
+}
+
+// end closes the JSON array
+func (s *batchStream) end() {
+	if !s.lines {
+		io.WriteString(s.w, "\n]\n")
+	}
+}
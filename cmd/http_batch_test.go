@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+)
+
+// newWikiServer serves the extractor's testdata pages under /wiki/ and
+// returns a service config whose extractor may fetch from it
+func newWikiServer(t *testing.T) (*httptest.Server, *liveConfig) {
+	t.Helper()
+	server := httptest.NewServer(http.StripPrefix("/wiki/", http.FileServer(http.Dir("../internal/extractor/testdata"))))
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ext := extractor.NewExtractor()
+	ext.Options.AllowedHosts = []string{u.Host}
+	return server, &liveConfig{config: &serviceConfig{ext: ext}}
+}
+
+// stubStore is a storage.Storage recording the sources stored through it,
+// or failing every write with err. Methods it doesn't override panic.
+type stubStore struct {
+	storage.Storage
+	err error
+
+	mu      sync.Mutex
+	stored  map[string][]extractor.Quad
+	tags    map[string]string
+	sources map[string]storage.SourceRecord
+}
+
+func (s *stubStore) Store(quads []extractor.Quad, sourceURL string, extractedAt time.Time, tag string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stored == nil {
+		s.stored = make(map[string][]extractor.Quad)
+		s.tags = make(map[string]string)
+	}
+	s.stored[sourceURL] = quads
+	s.tags[sourceURL] = tag
+	return nil
+}
+
+func (s *stubStore) SaveSource(source storage.SourceRecord) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sources == nil {
+		s.sources = make(map[string]storage.SourceRecord)
+	}
+	s.sources[source.SourceURL] = source
+	return nil
+}
+
+func (s *stubStore) GetSource(sourceURL string) (*storage.SourceRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	source, ok := s.sources[sourceURL]
+	if !ok {
+		return nil, nil
+	}
+	return &source, nil
+}
+
+// postBatch sends body to handler and returns the response and its results,
+// read as a JSON array or, with format=jsonl, as lines
+func postBatch(t *testing.T, handler http.HandlerFunc, query, body string) (*httptest.ResponseRecorder, []batchResult) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/batch"+query, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+
+	var results []batchResult
+	if strings.Contains(query, "format=jsonl") {
+		scanner := bufio.NewScanner(rec.Body)
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			var result batchResult
+			if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+				t.Fatalf("decoding line %q: %v", scanner.Text(), err)
+			}
+			results = append(results, result)
+		}
+	} else if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+		t.Fatalf("decoding response: %v\n%s", err, rec.Body)
+	}
+	return rec, results
+}
+
+func TestBatch(t *testing.T) {
+	server, live := newWikiServer(t)
+	found := server.URL + "/wiki/native_name.html"
+	missing := server.URL + "/wiki/missing.html"
+	body := `{"urls": ["` + found + `", "` + missing + `", "not a url"], "store": true, "tag": "nightly"}`
+
+	for _, tt := range []struct {
+		query       string
+		contentType string
+	}{
+		{"", "application/json"},
+		{"?format=jsonl", "application/x-ndjson"},
+	} {
+		query := tt.query
+		store := &stubStore{}
+		rec, results := postBatch(t, handleBatch(live, store, 50), query, body)
+		if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+			t.Errorf("%q: Content-Type = %q, want %q", query, got, tt.contentType)
+		}
+		if len(results) != 3 {
+			t.Fatalf("%q: got %d results, want 3", query, len(results))
+		}
+
+		byURL := map[string]batchResult{}
+		for _, result := range results {
+			byURL[result.URL] = result
+		}
+		if got := byURL[found]; got.Status != http.StatusOK || !got.Stored || got.Error != "" || len(got.Quads) == 0 {
+			t.Errorf("%q: found page = %+v, want 200 with stored quads", query, got)
+		}
+		if got := byURL[missing]; got.Status != http.StatusNotFound || got.Stored || got.Error == "" {
+			t.Errorf("%q: missing page = %+v, want 404 with an error", query, got)
+		}
+		if got := byURL["not a url"]; got.Status != http.StatusBadRequest {
+			t.Errorf("%q: invalid URL status = %d, want 400", query, got.Status)
+		}
+
+		if len(store.stored) != 1 || store.tags[found] != "nightly" {
+			t.Errorf("%q: stored %v with tags %v, want only %s tagged nightly", query, store.stored, store.tags, found)
+		}
+	}
+}
+
+func TestBatchStoreFailure(t *testing.T) {
+	server, live := newWikiServer(t)
+	page := server.URL + "/wiki/native_name.html"
+	store := &stubStore{err: errors.New("disk full")}
+
+	_, results := postBatch(t, handleBatch(live, store, 50), "", `{"urls": ["`+page+`"], "store": true}`)
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	got := results[0]
+	if got.Status != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500 for a failed write", got.Status)
+	}
+	if got.Stored || !strings.Contains(got.Error, "disk full") {
+		t.Errorf("result = %+v, want not stored with the storage error", got)
+	}
+}
+
+func TestBatchWithoutStore(t *testing.T) {
+	server, live := newWikiServer(t)
+	page := server.URL + "/wiki/native_name.html"
+
+	// Without "store" the shared store is never written to
+	store := &stubStore{err: errors.New("unexpected write")}
+	_, results := postBatch(t, handleBatch(live, store, 50), "", `{"urls": ["`+page+`"]}`)
+	if len(results) != 1 || results[0].Status != http.StatusOK || results[0].Stored {
+		t.Errorf("results = %+v, want one unstored 200", results)
+	}
+}
+
+func TestBatchIncremental(t *testing.T) {
+	server, live := newWikiServer(t)
+	page := server.URL + "/wiki/native_name.html"
+	other := server.URL + "/wiki/collapsible_infobox.html"
+	store := &stubStore{}
+	handler := handleBatch(live, store, 50)
+
+	// The first fetch has nothing to compare against
+	_, results := postBatch(t, handler, "", `{"urls": ["`+page+`"], "store": true, "incremental": true}`)
+	if len(results) != 1 || !results[0].Stored || results[0].Unchanged {
+		t.Fatalf("first batch = %+v, want the page stored", results)
+	}
+	if store.sources[page].LastModified == "" {
+		t.Fatalf("source %+v has no Last-Modified to revalidate with", store.sources[page])
+	}
+
+	delete(store.stored, page)
+	_, results = postBatch(t, handler, "", `{"urls": ["`+page+`", "`+other+`"], "store": true, "incremental": true}`)
+	byURL := map[string]batchResult{}
+	for _, result := range results {
+		byURL[result.URL] = result
+	}
+	if got := byURL[page]; got.Status != http.StatusOK || !got.Unchanged || got.Stored || len(got.Quads) != 0 {
+		t.Errorf("unchanged page = %+v, want 200 marked unchanged without quads", got)
+	}
+	if got := byURL[other]; got.Unchanged || !got.Stored {
+		t.Errorf("page fetched for the first time = %+v, want it stored", got)
+	}
+	if _, ok := store.stored[page]; ok {
+		t.Error("unchanged page was stored again")
+	}
+
+	// Without "incremental" the page is extracted again
+	_, results = postBatch(t, handler, "", `{"urls": ["`+page+`"], "store": true}`)
+	if len(results) != 1 || results[0].Unchanged || !results[0].Stored {
+		t.Errorf("non-incremental batch = %+v, want the page stored", results)
+	}
+}
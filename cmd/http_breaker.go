@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+// errCircuitOpen is returned instead of extracting a page while the circuit
+// breaker is open
+var errCircuitOpen = errors.New("extraction paused after repeated upstream failures")
+
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half-open"
+	breakerDisabled = "disabled"
+)
+
+// circuitBreaker stops the service from fetching pages while the wiki keeps
+// failing, e.g. when it is blocking us. After threshold consecutive upstream
+// failures it opens and rejects extractions for cooldown, then half-opens to
+// let a single trial extraction through: success closes it again, failure
+// reopens it for another cooldown. A nil circuitBreaker is disabled.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+// breakerStatus is the circuit breaker state reported by /healthz
+type breakerStatus struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenUntil           *time.Time `json:"open_until,omitempty"`
+}
+
+// breakerTicket is handed out by allow for each extraction it lets through
+// and passed back to record with the outcome
+type breakerTicket struct {
+	// trial is set on the one extraction let through while half-open
+	trial bool
+}
+
+// newCircuitBreaker returns a circuit breaker opening after threshold
+// consecutive failures, or nil when threshold is zero
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow reports whether an extraction may go ahead. Once the cooldown is
+// over it lets one trial through; every allowed extraction must be followed
+// by a call to record with the ticket returned.
+func (b *circuitBreaker) allow() (breakerTicket, bool) {
+	if b == nil {
+		return breakerTicket{}, true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return breakerTicket{}, true
+	}
+	if b.trial || time.Now().Before(b.openUntil) {
+		return breakerTicket{}, false
+	}
+	b.trial = true
+	return breakerTicket{trial: true}, true
+}
+
+// record updates the breaker with the outcome of an allowed extraction.
+// Only upstream failures count against it: a page that is missing or has no
+// infobox shows the wiki is answering. Extractions abandoned by the client
+// don't count either way. Only the trial's own outcome ends the trial, so an
+// extraction admitted before the breaker opened can't let a second one in.
+func (b *circuitBreaker) record(ticket breakerTicket, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ticket.trial {
+		b.trial = false
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+	case isUpstreamFailure(err):
+		b.failures++
+		if b.failures >= b.threshold {
+			b.openUntil = time.Now().Add(b.cooldown)
+		}
+	default:
+		b.failures = 0
+		b.openUntil = time.Time{}
+	}
+}
+
+// status returns the breaker's current state for /healthz
+func (b *circuitBreaker) status() breakerStatus {
+	if b == nil {
+		return breakerStatus{State: breakerDisabled}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := breakerStatus{State: breakerClosed, ConsecutiveFailures: b.failures}
+	if b.failures >= b.threshold {
+		status.State = breakerOpen
+		if b.trial || !time.Now().Before(b.openUntil) {
+			status.State = breakerHalfOpen
+		}
+		openUntil := b.openUntil
+		status.OpenUntil = &openUntil
+	}
+	return status
+}
+
+// isUpstreamFailure reports whether err means the wiki failed to serve a
+// page, as opposed to the page itself being unusable
+func isUpstreamFailure(err error) bool {
+	switch {
+	case err == nil, errors.Is(err, extractor.ErrResponseTooLarge):
+		return false
+	case errors.Is(err, extractor.ErrRateLimited),
+		errors.Is(err, extractor.ErrUnavailable),
+		errors.Is(err, extractor.ErrFetchFailed),
+		errors.Is(err, context.DeadlineExceeded):
+		return true
+	default:
+		return false
+	}
+}
+
+// retryDelay returns how long to wait before retry attempt n, counting from
+// 1: backoff, doubled for each further attempt
+func retryDelay(backoff time.Duration, n int) time.Duration {
+	return backoff << (n - 1)
+}
+
+// sleepContext waits for d, returning ctx's error if it is done first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
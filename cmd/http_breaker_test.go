@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+// openBreaker returns a breaker that has just opened after failing threshold
+// times, with its cooldown already over
+func openBreaker(t *testing.T, threshold int) *circuitBreaker {
+	t.Helper()
+	b := newCircuitBreaker(threshold, time.Hour)
+	for i := 0; i < threshold; i++ {
+		ticket, ok := b.allow()
+		if !ok {
+			t.Fatalf("extraction %d rejected before the breaker opened", i)
+		}
+		b.record(ticket, extractor.ErrUnavailable)
+	}
+	if _, ok := b.allow(); ok {
+		t.Fatal("open breaker allowed an extraction during its cooldown")
+	}
+	b.openUntil = time.Now().Add(-time.Second)
+	return b
+}
+
+func TestCircuitBreakerSingleTrial(t *testing.T) {
+	b := newCircuitBreaker(2, time.Hour)
+
+	// An extraction admitted while closed is still running when the
+	// breaker opens and half-opens
+	late, ok := b.allow()
+	if !ok {
+		t.Fatal("closed breaker rejected an extraction")
+	}
+	for i := 0; i < 2; i++ {
+		ticket, _ := b.allow()
+		b.record(ticket, extractor.ErrUnavailable)
+	}
+	b.openUntil = time.Now().Add(-time.Second)
+
+	trial, ok := b.allow()
+	if !ok || !trial.trial {
+		t.Fatalf("half-open breaker gave ticket %+v, %v, want the trial", trial, ok)
+	}
+
+	// The earlier extraction finishing doesn't end the trial
+	b.record(late, extractor.ErrUnavailable)
+	if _, ok := b.allow(); ok {
+		t.Fatal("a second extraction was let through while the trial was running")
+	}
+	if got := b.status().State; got != breakerHalfOpen {
+		t.Errorf("state = %s, want %s", got, breakerHalfOpen)
+	}
+
+	// The trial succeeding closes the breaker
+	b.record(trial, nil)
+	if got := b.status(); got.State != breakerClosed || got.ConsecutiveFailures != 0 {
+		t.Errorf("status after a successful trial = %+v, want closed", got)
+	}
+	if _, ok := b.allow(); !ok {
+		t.Error("closed breaker rejected an extraction")
+	}
+}
+
+func TestCircuitBreakerFailedTrial(t *testing.T) {
+	b := openBreaker(t, 3)
+	if got := b.status().State; got != breakerHalfOpen {
+		t.Errorf("state after the cooldown = %s, want %s", got, breakerHalfOpen)
+	}
+
+	trial, ok := b.allow()
+	if !ok || !trial.trial {
+		t.Fatalf("half-open breaker gave ticket %+v, %v, want the trial", trial, ok)
+	}
+	b.record(trial, extractor.ErrRateLimited)
+	if got := b.status().State; got != breakerOpen {
+		t.Errorf("state after a failed trial = %s, want %s", got, breakerOpen)
+	}
+	if _, ok := b.allow(); ok {
+		t.Error("breaker allowed an extraction right after a failed trial")
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	var b *circuitBreaker
+	for i := 0; i < 10; i++ {
+		ticket, ok := b.allow()
+		if !ok {
+			t.Fatal("disabled breaker rejected an extraction")
+		}
+		b.record(ticket, extractor.ErrUnavailable)
+	}
+	if got := b.status().State; got != breakerDisabled {
+		t.Errorf("state = %s, want %s", got, breakerDisabled)
+	}
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+	"github.com/spf13/viper"
+)
+
+// apiKeyHeader is the request header carrying the API key, as an
+// alternative to an Authorization: Bearer header
+const apiKeyHeader = "X-API-Key"
+
+// serviceConfig holds the HTTP service settings that can change while it
+// runs. Each is read from the config file, unless set by flag.
+type serviceConfig struct {
+	ext            *extractor.Extractor
+	requestTimeout time.Duration
+	apiKey         string
+	retries        int
+	retryBackoff   time.Duration
+
+	// breaker and limiter are created at startup and carried over by
+	// reloads, so their state outlives any one config
+	breaker *circuitBreaker
+	limiter *clientLimiter
+}
+
+// liveConfig is the service's current serviceConfig, replaced as a whole on
+// SIGHUP. Requests keep the config they started with.
+type liveConfig struct {
+	mu     sync.RWMutex
+	config *serviceConfig
+}
+
+func (l *liveConfig) get() *serviceConfig {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.config
+}
+
+func (l *liveConfig) set(config *serviceConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.config = config
+}
+
+// loadServiceConfig builds the mutable service settings, including a new
+// extractor, from the flags and the config file as currently read
+func loadServiceConfig() (*serviceConfig, error) {
+	ext, err := newExtractor()
+	if err != nil {
+		return nil, err
+	}
+	if err := ext.SetTimeout(viper.GetDuration("timeout")); err != nil {
+		return nil, err
+	}
+	if delay := viper.GetDuration("rate_limit_delay"); delay > 0 {
+		if err := ext.SetRateLimit(delay, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	requestTimeout := viper.GetDuration("request_timeout")
+	if requestTimeout < 0 {
+		return nil, fmt.Errorf("invalid request_timeout: must not be negative, got %s", requestTimeout)
+	}
+
+	retries := viper.GetInt("retries")
+	if retries < 0 {
+		return nil, fmt.Errorf("invalid retries: must not be negative, got %d", retries)
+	}
+	retryBackoff := viper.GetDuration("retry_backoff")
+	if retryBackoff < 0 {
+		return nil, fmt.Errorf("invalid retry_backoff: must not be negative, got %s", retryBackoff)
+	}
+
+	return &serviceConfig{
+		ext:            ext,
+		requestTimeout: requestTimeout,
+		apiKey:         viper.GetString("api_key"),
+		retries:        retries,
+		retryBackoff:   retryBackoff,
+	}, nil
+}
+
+// reloadServiceConfig re-reads the config file and swaps in the new mutable
+// settings. On any error the current settings stay in effect. Settings that
+// only apply at startup are reported as needing a restart.
+func reloadServiceConfig(live *liveConfig, listenAddr string) {
+	if err := viper.ReadInConfig(); err != nil {
+		log.Printf("Reload: failed to read config file, keeping current settings: %v", err)
+		return
+	}
+
+	config, err := loadServiceConfig()
+	if err != nil {
+		log.Printf("Reload: invalid config, keeping current settings: %v", err)
+		return
+	}
+	previous := live.get()
+	config.breaker = previous.breaker
+	config.limiter = previous.limiter
+	live.set(config)
+	log.Printf("Reload: applied %s (request timeout %s, rate limit delay %s, retries %d, API key required: %t)",
+		viper.ConfigFileUsed(), config.requestTimeout, viper.GetDuration("rate_limit_delay"), config.retries, config.apiKey != "")
+
+	if addr := viper.GetString("listen"); addr != listenAddr {
+		log.Printf("Reload: listen address changed from %s to %s; restart the service to apply it", listenAddr, addr)
+	}
+}
+
+// requireAPIKey rejects requests without the configured API key with 401.
+// When no key is configured every request is let through, as are health
+// checks, which load balancers send without credentials.
+func requireAPIKey(live *liveConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey := live.get().apiKey
+		if apiKey == "" || healthCheckPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		presented := r.Header.Get(apiKeyHeader)
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			presented = bearer
+		}
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(apiKey)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSONError(w, http.StatusUnauthorized, "Missing or invalid API key")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+)
+
+// readyTimeout bounds the database check made by /readyz, so that a hung
+// database fails the check rather than stalling the load balancer's probe
+const readyTimeout = 2 * time.Second
+
+// healthCheckPaths are the endpoints served without an API key
+var healthCheckPaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+}
+
+// healthzResponse is the body of a /healthz response
+type healthzResponse struct {
+	Status         string        `json:"status"`
+	CircuitBreaker breakerStatus `json:"circuit_breaker"`
+}
+
+// handleHealthz reports that the process is up and serving requests, along
+// with the state of the circuit breaker. It checks nothing else, so it stays
+// cheap enough for frequent liveness probes, and an open breaker doesn't
+// fail it: restarting the process wouldn't help the wiki recover.
+func handleHealthz(live *liveConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(healthzResponse{
+			Status:         "ok",
+			CircuitBreaker: live.get().breaker.status(),
+		})
+	}
+}
+
+// handleReadyz reports whether the service can handle traffic by running a
+// query against the storage, responding 503 when it fails or times out
+func handleReadyz(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+		defer cancel()
+		if err := store.Ping(ctx); err != nil {
+			log.Printf("Readiness check failed: %v", err)
+			writeJSONError(w, http.StatusServiceUnavailable, "Storage unavailable: "+err.Error())
+			return
+		}
+		writeHealth(w, "ready")
+	}
+}
+
+// writeHealth writes a successful health check response such as {"status": "ok"}
+func writeHealth(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]string{"status": message})
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// clientLimiter limits how many pages each client may ask the service to
+// extract per minute, across /extract, /store and /batch. Clients are told
+// apart by remote address. Each has a token bucket holding up to perMinute
+// tokens, one per page, refilled at perMinute per minute, so a client may
+// spend a minute's allowance at once, e.g. on a batch. A nil clientLimiter is
+// disabled.
+type clientLimiter struct {
+	perMinute int
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// tokenBucket is one client's remaining allowance as of updated
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// newClientLimiter returns a limiter allowing perMinute pages per client per
+// minute, or nil when perMinute is zero
+func newClientLimiter(perMinute int) *clientLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &clientLimiter{perMinute: perMinute, buckets: make(map[string]*tokenBucket)}
+}
+
+// take spends n tokens of client's allowance. When the client has fewer
+// left, nothing is spent and take returns how long until it has n.
+func (l *clientLimiter) take(client string, n int, now time.Time) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+	bucket, ok := l.buckets[client]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(l.perMinute), updated: now}
+		l.buckets[client] = bucket
+	}
+	bucket.tokens = l.refill(bucket, now)
+	bucket.updated = now
+
+	if bucket.tokens >= float64(n) {
+		bucket.tokens -= float64(n)
+		return true, 0
+	}
+	missing := float64(n) - bucket.tokens
+	return false, time.Duration(missing / float64(l.perMinute) * float64(time.Minute))
+}
+
+// refill returns the tokens in a bucket at now, capped at perMinute
+func (l *clientLimiter) refill(bucket *tokenBucket, now time.Time) float64 {
+	elapsed := now.Sub(bucket.updated)
+	tokens := bucket.tokens + elapsed.Minutes()*float64(l.perMinute)
+	return math.Min(tokens, float64(l.perMinute))
+}
+
+// sweep forgets, at most once a minute, the clients whose buckets have
+// refilled, so the map doesn't grow with every address ever seen
+func (l *clientLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for client, bucket := range l.buckets {
+		if l.refill(bucket, now) >= float64(l.perMinute) {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// allowPages spends n pages of the requesting client's allowance, responding
+// 429 with a Retry-After header and returning false when it is used up
+func allowPages(w http.ResponseWriter, r *http.Request, limiter *clientLimiter, n int) bool {
+	ok, wait := limiter.take(clientAddress(r), n, time.Now())
+	if ok {
+		return true
+	}
+	seconds := int(math.Ceil(wait.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	writeJSONError(w, http.StatusTooManyRequests,
+		fmt.Sprintf("Rate limit of %d pages per minute exceeded; retry in %ds", limiter.perMinute, seconds))
+	return false
+}
+
+// clientAddress returns the IP address a request came from
+func clientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
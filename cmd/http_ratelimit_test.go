@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientLimiterTake(t *testing.T) {
+	limiter := newClientLimiter(60)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// A full minute's allowance can be spent at once
+	if ok, _ := limiter.take("198.51.100.1", 50, start); !ok {
+		t.Fatal("first 50 pages rejected")
+	}
+	ok, wait := limiter.take("198.51.100.1", 20, start)
+	if ok {
+		t.Fatal("20 more pages accepted with 10 left")
+	}
+	if wait != 10*time.Second {
+		t.Errorf("wait = %s, want 10s until 10 more pages are allowed", wait)
+	}
+	// A rejected request spends nothing
+	if ok, _ := limiter.take("198.51.100.1", 10, start); !ok {
+		t.Fatal("remaining 10 pages rejected")
+	}
+
+	// Other clients have their own allowance
+	if ok, _ := limiter.take("198.51.100.2", 60, start); !ok {
+		t.Fatal("second client rejected")
+	}
+
+	// The allowance refills at the per-minute rate, up to a minute's worth
+	if ok, _ := limiter.take("198.51.100.1", 30, start.Add(30*time.Second)); !ok {
+		t.Error("30 pages rejected after refilling for 30s")
+	}
+	if ok, _ := limiter.take("198.51.100.1", 61, start.Add(time.Hour)); ok {
+		t.Error("more than a minute's allowance accepted")
+	}
+}
+
+func TestClientLimiterSweep(t *testing.T) {
+	limiter := newClientLimiter(60)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter.take("198.51.100.1", 60, start)
+	limiter.take("198.51.100.2", 1, start.Add(50*time.Second))
+
+	limiter.take("198.51.100.3", 1, start.Add(2*time.Minute))
+	if _, ok := limiter.buckets["198.51.100.1"]; ok {
+		t.Error("refilled bucket kept after sweep")
+	}
+	if len(limiter.buckets) != 1 {
+		t.Errorf("%d buckets after sweep, want 1", len(limiter.buckets))
+	}
+}
+
+func TestDisabledClientLimiter(t *testing.T) {
+	var limiter *clientLimiter
+	if ok, _ := limiter.take("198.51.100.1", 1000000, time.Now()); !ok {
+		t.Error("disabled limiter rejected a request")
+	}
+	if newClientLimiter(0) != nil {
+		t.Error("newClientLimiter(0) is not disabled")
+	}
+}
+
+func TestBatchRateLimit(t *testing.T) {
+	live := &liveConfig{config: &serviceConfig{limiter: newClientLimiter(60)}}
+	handler := handleBatch(live, nil, 50)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(body))
+		req.RemoteAddr = "198.51.100.1:4321"
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	urls := `"https://en.wikipedia.org/wiki/A"` + strings.Repeat(`, "https://en.wikipedia.org/wiki/A"`, 50)
+	if rec := post(`{"urls": [` + urls + `]}`); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("51 URLs: status %d, want 413", rec.Code)
+	}
+
+	// Spend most of the client's allowance, as earlier requests would
+	live.get().limiter.take("198.51.100.1", 55, time.Now())
+	rec := post(`{"urls": ["https://en.wikipedia.org/wiki/A", "https://en.wikipedia.org/wiki/B", "https://en.wikipedia.org/wiki/C", "https://en.wikipedia.org/wiki/D", "https://en.wikipedia.org/wiki/E", "https://en.wikipedia.org/wiki/F"]}`)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("batch beyond the rate limit: status %d, want 429", rec.Code)
+	}
+	if retryAfter := rec.Header().Get("Retry-After"); retryAfter != "1" {
+		t.Errorf("Retry-After = %q, want %q", retryAfter, "1")
+	}
+}
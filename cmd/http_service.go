@@ -1,12 +1,69 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"log"
+	"log/slog"
+	"mime"
 	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/chetankale/wikipedia-extraction/internal/extractor"
 	"github.com/chetankale/wikipedia-extraction/internal/output"
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	dbMaxOpenConns int
+	dbMaxIdleConns int
+	requestTimeout time.Duration
+	rateLimitDelay time.Duration
+	apiKey         string
+	listenAddr     string
+	flushEvery     int
+	retries        int
+	retryBackoff   time.Duration
+
+	breakerThreshold int
+	breakerCooldown  time.Duration
+
+	batchMaxURLs    int
+	clientRateLimit int
+)
+
+// shutdownTimeout is how long in-flight requests get to finish on shutdown
+const shutdownTimeout = 30 * time.Second
+
+// defaultRequestTimeout is how long a request may spend extracting a page
+const defaultRequestTimeout = 30 * time.Second
+
+const (
+	// defaultBreakerThreshold is how many consecutive upstream failures open
+	// the circuit breaker
+	defaultBreakerThreshold = 5
+
+	// defaultBreakerCooldown is how long the open circuit breaker rejects
+	// extractions before letting a trial through
+	defaultBreakerCooldown = 30 * time.Second
+
+	// defaultBatchMaxURLs is the largest number of URLs accepted by one
+	// /batch request
+	defaultBatchMaxURLs = 50
+
+	// defaultClientRateLimit is how many pages each client may have
+	// extracted per minute
+	defaultClientRateLimit = 300
 )
 
 var httpServiceCmd = &cobra.Command{
@@ -20,37 +77,345 @@ var httpServiceCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(httpServiceCmd)
+
+	httpServiceCmd.Flags().IntVar(&dbMaxOpenConns, "db-max-open-conns", 4, "Maximum open database connections shared by all requests")
+	httpServiceCmd.Flags().IntVar(&dbMaxIdleConns, "db-max-idle-conns", 2, "Maximum idle database connections kept between requests")
+	httpServiceCmd.Flags().DurationVar(&requestTimeout, "request-timeout", defaultRequestTimeout, "Give up extracting a page after this long and respond 504 (0 for no limit)")
+	httpServiceCmd.Flags().DurationVar(&rateLimitDelay, "rate-limit-delay", 0, "Wait this long between page fetches from the same host (0 for no limit)")
+	httpServiceCmd.Flags().StringVar(&apiKey, "api-key", "", "Require this key in an X-API-Key or Authorization: Bearer header (or set API_KEY)")
+	httpServiceCmd.Flags().StringVar(&listenAddr, "listen", ":8080", "Address to listen on")
+	httpServiceCmd.Flags().IntVar(&flushEvery, "flush-every", 1, "Flush streamed (jsonl) responses to the client after this many records (0 flushes only at the end)")
+	httpServiceCmd.Flags().IntVar(&retries, "retries", 0, "Retry a page this many times when the wiki fails to serve it")
+	httpServiceCmd.Flags().DurationVar(&retryBackoff, "retry-backoff", time.Second, "Wait this long before the first retry, doubling for each further retry")
+	httpServiceCmd.Flags().IntVar(&breakerThreshold, "breaker-threshold", defaultBreakerThreshold, "Stop extracting and respond 503 after this many consecutive upstream failures (0 disables the circuit breaker)")
+	httpServiceCmd.Flags().DurationVar(&breakerCooldown, "breaker-cooldown", defaultBreakerCooldown, "How long the circuit breaker stays open before letting a trial extraction through")
+	httpServiceCmd.Flags().IntVar(&batchMaxURLs, "batch-max-urls", defaultBatchMaxURLs, "Maximum number of URLs accepted by one /batch request")
+	httpServiceCmd.Flags().IntVar(&clientRateLimit, "client-rate-limit", defaultClientRateLimit, "Maximum pages each client address may have extracted per minute across /extract, /store and /batch, responding 429 beyond it (0 for no limit)")
+
+	// These settings may also come from the config file, which is re-read on
+	// SIGHUP; flags given on the command line take precedence
+	viper.BindPFlag("request_timeout", httpServiceCmd.Flags().Lookup("request-timeout"))
+	viper.BindPFlag("rate_limit_delay", httpServiceCmd.Flags().Lookup("rate-limit-delay"))
+	viper.BindPFlag("api_key", httpServiceCmd.Flags().Lookup("api-key"))
+	viper.BindPFlag("listen", httpServiceCmd.Flags().Lookup("listen"))
+	viper.BindPFlag("retries", httpServiceCmd.Flags().Lookup("retries"))
+	viper.BindPFlag("retry_backoff", httpServiceCmd.Flags().Lookup("retry-backoff"))
+	viper.BindPFlag("timeout", rootCmd.PersistentFlags().Lookup("timeout"))
 }
 
 func StartHTTPServer() {
+	// Reject invalid output options at startup rather than on every request
+	if _, err := newFormatter(); err != nil {
+		log.Fatalf("Invalid output options: %v", err)
+	}
+	if flushEvery < 0 {
+		log.Fatalf("Invalid --flush-every: must not be negative, got %d", flushEvery)
+	}
+	if breakerThreshold < 0 {
+		log.Fatalf("Invalid --breaker-threshold: must not be negative, got %d", breakerThreshold)
+	}
+	if breakerCooldown <= 0 {
+		log.Fatalf("Invalid --breaker-cooldown: must be positive, got %s", breakerCooldown)
+	}
+	if batchMaxURLs <= 0 {
+		log.Fatalf("Invalid --batch-max-urls: must be positive, got %d", batchMaxURLs)
+	}
+	if clientRateLimit < 0 {
+		log.Fatalf("Invalid --client-rate-limit: must not be negative, got %d", clientRateLimit)
+	}
+	if clientRateLimit > 0 && clientRateLimit < batchMaxURLs {
+		// A full batch could never be accepted
+		log.Fatalf("Invalid --client-rate-limit: must be 0 or at least --batch-max-urls (%d), got %d", batchMaxURLs, clientRateLimit)
+	}
+
+	// The extractor is safe for concurrent use, so share one across requests
+	// until a reload replaces it
+	config, err := loadServiceConfig()
+	if err != nil {
+		log.Fatalf("Failed to configure extractor: %v", err)
+	}
+	config.breaker = newCircuitBreaker(breakerThreshold, breakerCooldown)
+	config.limiter = newClientLimiter(clientRateLimit)
+	live := &liveConfig{config: config}
+	addr := viper.GetString("listen")
+
+	// One storage serves every request; opening SQLite per request adds
+	// overhead and contends for the database lock under load
+	store, err := storage.NewSQLiteStorage("quads.db")
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer store.Close()
+	store.SetConnectionLimits(dbMaxOpenConns, dbMaxIdleConns)
 
-	
 	http.HandleFunc("/extract", func(w http.ResponseWriter, r *http.Request) {
 		src := r.URL.Query().Get("src")
 		if src == "" {
 			log.Println("No source URL provided")
-			http.Error(w, "No source URL provided", http.StatusBadRequest)
+			writeJSONError(w, http.StatusBadRequest, "No source URL provided")
+			return
+		}
+		config := live.get()
+		if !allowPages(w, r, config.limiter, 1) {
+			return
+		}
+		result, err := extractForRequest(r, config, src)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			writeJSONError(w, extractStatusCode(err), err.Error())
 			return
 		}
-		// Create extractor
-		ext := extractor.NewExtractor()
+		quads := result.Quads
+		if result.Truncated {
+			w.Header().Set("X-Quads-Truncated", "true")
+		}
+		if result.NoStructuredData {
+			w.Header().Set("X-No-Structured-Data", "true")
+		}
+		if result.UnresolvedCitations > 0 {
+			w.Header().Set("X-Unresolved-Citations", strconv.Itoa(result.UnresolvedCitations))
+		}
+		if len(result.ValidationFailures) > 0 {
+			w.Header().Set("X-Validation-Failures", strconv.Itoa(len(result.ValidationFailures)))
+		}
+		responseFormat := negotiateFormat(r)
+		w.Header().Set("Content-Type", output.ContentType(responseFormat))
 
-		quads, err := ext.ExtractFromURL(src)
+		formatter, err := newFormatter()
 		if err != nil {
-			log.Println("Error: %v", err)
-			http.Error(w, "Error: "+err.Error(), http.StatusInternalServerError)
+			writeJSONError(w, http.StatusInternalServerError, "Invalid output options: "+err.Error())
 			return
 		}
-		formatter := output.NewFormatter()
-		if err := formatter.WriteQuads(quads, w, format); err != nil {
-			log.Println("Failed to write output: %v", err)
-			http.Error(w, "Failed to write output: "+err.Error(), http.StatusInternalServerError)
+		var body io.Writer = w
+		if output.IsStreamingFormat(responseFormat) {
+			// Send records as they are encoded rather than when the buffer fills
+			body = newFlushingWriter(w, flushEvery)
+		}
+		if err := formatter.WriteQuads(quads, body, responseFormat); err != nil {
+			log.Printf("Failed to write output: %v", err)
+			writeJSONError(w, http.StatusInternalServerError, "Failed to write output: "+err.Error())
 			return
 		}
 	})
 
-	err := http.ListenAndServe(":8080", nil)
-	if err != nil {
+	http.HandleFunc("/batch", handleBatch(live, store, batchMaxURLs))
+	http.HandleFunc("/store", handleStore(live, store))
+	http.HandleFunc("/sources", handleSources(store))
+	http.HandleFunc("/healthz", handleHealthz(live))
+	http.HandleFunc("/readyz", handleReadyz(store))
+
+	// Access log lines are JSON on stderr, separate from the extraction output
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+
+	server := &http.Server{
+		Addr:    addr,
+		Handler: accessLog(logger, requireAPIKey(live, http.DefaultServeMux)),
+	}
+
+	// Re-read the config file on SIGHUP to change settings without a restart
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			reloadServiceConfig(live, addr)
+		}
+	}()
+
+	// Stop accepting requests on SIGINT/SIGTERM and let in-flight ones
+	// finish before the deferred storage close runs. ListenAndServe returns
+	// as soon as Shutdown starts, so wait for done, which is closed once
+	// Shutdown has returned.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Shutdown: %v", err)
+		}
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatal(err)
 	}
-}
\ No newline at end of file
+	<-done
+}
+
+// flushingWriter flushes the response after every n writes, which a
+// streaming format makes once per record. Until then the data waits in the
+// response buffer. With n zero it is only sent when the buffer fills or the
+// handler returns.
+type flushingWriter struct {
+	w       http.ResponseWriter
+	n       int
+	pending int
+}
+
+// newFlushingWriter returns a flushingWriter for w, marking the response as
+// streamed
+func newFlushingWriter(w http.ResponseWriter, n int) *flushingWriter {
+	setStreamingHeaders(w)
+	return &flushingWriter{w: w, n: n}
+}
+
+func (f *flushingWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err != nil || f.n == 0 {
+		return n, err
+	}
+	f.pending++
+	if f.pending >= f.n {
+		f.pending = 0
+		err = http.NewResponseController(f.w).Flush()
+	}
+	return n, err
+}
+
+// setStreamingHeaders marks a response whose length is unknown up front as
+// chunked, and asks reverse proxies such as nginx not to buffer it
+func setStreamingHeaders(w http.ResponseWriter) {
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Accel-Buffering", "no")
+}
+
+// negotiateFormat picks the response format from the "format" query parameter
+// or, failing that, the Accept header. It falls back to JSON when neither
+// names a supported format.
+func negotiateFormat(r *http.Request) string {
+	if requested := r.URL.Query().Get("format"); requested != "" {
+		if output.IsFormat(requested) {
+			return requested
+		}
+	}
+
+	for _, mediaType := range acceptedMediaTypes(r.Header.Get("Accept")) {
+		if f, ok := output.FormatForMediaType(mediaType); ok {
+			return f
+		}
+	}
+
+	return "json"
+}
+
+// acceptedMediaTypes parses an Accept header into media types ordered by
+// descending quality value. Types with q=0 are dropped.
+func acceptedMediaTypes(accept string) []string {
+	type weighted struct {
+		mediaType string
+		quality   float64
+	}
+
+	var accepted []weighted
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		accepted = append(accepted, weighted{mediaType: mediaType, quality: quality})
+	}
+
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].quality > accepted[j].quality
+	})
+
+	mediaTypes := make([]string, len(accepted))
+	for i, a := range accepted {
+		mediaTypes[i] = a.mediaType
+	}
+	return mediaTypes
+}
+
+// errorResponse is the JSON body returned for failed requests
+type errorResponse struct {
+	Error  string `json:"error"`
+	Status int    `json:"status"`
+}
+
+// writeJSONError writes an error response with the given status as JSON
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse{Error: message, Status: status})
+}
+
+// extractForRequest extracts url on behalf of r, giving up once the
+// configured request timeout elapses or the client goes away. Upstream
+// failures are retried with exponential backoff, and every attempt goes
+// through the circuit breaker, so retries stop as soon as it opens.
+func extractForRequest(r *http.Request, config *serviceConfig, url string) (*extractor.ExtractResult, error) {
+	return extractIfModifiedForRequest(r, config, url, "", "")
+}
+
+// extractIfModifiedForRequest is like extractForRequest but makes a
+// conditional request with the given validators, as ExtractIfModified does
+func extractIfModifiedForRequest(r *http.Request, config *serviceConfig, url, etag, lastModified string) (*extractor.ExtractResult, error) {
+	ctx := r.Context()
+	if config.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.requestTimeout)
+		defer cancel()
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if err := sleepContext(ctx, retryDelay(config.retryBackoff, attempt)); err != nil {
+				return nil, err
+			}
+		}
+		ticket, ok := config.breaker.allow()
+		if !ok {
+			return nil, errCircuitOpen
+		}
+		result, err := config.ext.ExtractIfModifiedContext(ctx, url, etag, lastModified)
+		config.breaker.record(ticket, err)
+		if err == nil || attempt >= config.retries || !isUpstreamFailure(err) || ctx.Err() != nil {
+			return result, err
+		}
+		log.Printf("Retrying %s after attempt %d failed: %v", url, attempt+1, err)
+	}
+}
+
+// extractStatusCode maps an extraction error to an HTTP status code
+func extractStatusCode(err error) int {
+	switch {
+	case errors.Is(err, errCircuitOpen):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, extractor.ErrResponseTooLarge):
+		// Checked before ErrFetchFailed, which oversized pages also match
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, extractor.ErrInvalidURL):
+		return http.StatusBadRequest
+	case errors.Is(err, extractor.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, extractor.ErrDisambiguation):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, extractor.ErrNoInfobox), errors.Is(err, extractor.ErrNoStructuredData):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, extractor.ErrUnresolvedCitations):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, extractor.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, extractor.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, extractor.ErrFetchFailed):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
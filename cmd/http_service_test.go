@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+// A wiki answering 503 makes the service answer 503 and counts against the
+// circuit breaker
+func TestExtractStatusCodeUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ext := extractor.NewExtractor()
+	ext.Options.AllowedHosts = []string{u.Host}
+
+	_, err = ext.Extract(server.URL + "/wiki/Paris")
+	if err == nil {
+		t.Fatal("Extract succeeded against a 503 response")
+	}
+	if got := extractStatusCode(err); got != http.StatusServiceUnavailable {
+		t.Errorf("extractStatusCode(%v) = %d, want %d", err, got, http.StatusServiceUnavailable)
+	}
+	if !isUpstreamFailure(err) {
+		t.Errorf("isUpstreamFailure(%v) = false, want true", err)
+	}
+}
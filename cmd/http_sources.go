@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+)
+
+// sourcesContentTypes are the MIME types of the formats served by /sources
+var sourcesContentTypes = map[string]string{
+	"json": "application/json",
+	"yaml": "application/yaml",
+	"csv":  "text/csv",
+	"tsv":  "text/tab-separated-values",
+}
+
+// handleSources lists the stored sources with their quad counts and last
+// extraction times, as JSON unless the format query parameter names yaml,
+// csv or tsv
+func handleSources(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSONError(w, http.StatusMethodNotAllowed, "Use GET to list sources")
+			return
+		}
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "json"
+		}
+		contentType, ok := sourcesContentTypes[format]
+		if !ok {
+			writeJSONError(w, http.StatusBadRequest, "Unsupported sources format "+format+" (valid formats: json, yaml, csv, tsv)")
+			return
+		}
+
+		sources, err := store.ListSources()
+		if err != nil {
+			log.Printf("Failed to list sources: %v", err)
+			writeJSONError(w, http.StatusInternalServerError, "Failed to list sources")
+			return
+		}
+
+		formatter, err := newFormatter()
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, "Invalid output options: "+err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		if err := formatter.WriteSources(sources, w, format); err != nil {
+			log.Printf("Failed to write sources: %v", err)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+)
+
+const (
+	// idempotencyKeyHeader is the request header that makes a /store request safe to retry
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	// idempotencyTTL is how long the response to an idempotent request is kept for replay
+	idempotencyTTL = 24 * time.Hour
+
+	// maxIdempotencyKeyLength bounds the length of an Idempotency-Key header
+	maxIdempotencyKeyLength = 255
+)
+
+// inFlightKeys holds the idempotency keys of /store requests currently being
+// processed, so a retry racing the original request is rejected rather than
+// storing the page twice
+var inFlightKeys sync.Map
+
+// storeResponse is the JSON body returned by the /store endpoint
+type storeResponse struct {
+	URL       string `json:"url"`
+	Title     string `json:"title"`
+	Stored    int    `json:"stored"`
+	Truncated bool   `json:"truncated,omitempty"`
+	// RevisionID is the revision of the page that was stored, when known
+	RevisionID int64 `json:"revision_id,omitempty"`
+}
+
+// handleStore extracts the page named by the src query parameter and stores
+// its quads. Requests with an Idempotency-Key header are processed once:
+// retries within idempotencyTTL replay the recorded response. Failed requests
+// are not recorded, so they can be retried with the same key.
+func handleStore(live *liveConfig, store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSONError(w, http.StatusMethodNotAllowed, "Use POST")
+			return
+		}
+
+		src := r.URL.Query().Get("src")
+		if src == "" {
+			writeJSONError(w, http.StatusBadRequest, "No source URL provided")
+			return
+		}
+
+		key := r.Header.Get(idempotencyKeyHeader)
+		if len(key) > maxIdempotencyKeyLength {
+			writeJSONError(w, http.StatusBadRequest, "Idempotency-Key is too long")
+			return
+		}
+
+		tag := r.URL.Query().Get("tag")
+		requestHash := idempotencyRequestHash(r.Method, src, tag)
+		if key != "" {
+			if _, busy := inFlightKeys.LoadOrStore(key, struct{}{}); busy {
+				writeJSONError(w, http.StatusConflict, "A request with this Idempotency-Key is already in progress")
+				return
+			}
+			defer inFlightKeys.Delete(key)
+
+			if err := store.DeleteIdempotencyRecordsBefore(time.Now().Add(-idempotencyTTL)); err != nil {
+				log.Printf("Failed to expire idempotency keys: %v", err)
+			}
+
+			record, err := store.GetIdempotencyRecord(key)
+			if err != nil {
+				log.Printf("Failed to look up idempotency key: %v", err)
+				writeJSONError(w, http.StatusInternalServerError, "Failed to look up Idempotency-Key")
+				return
+			}
+			if record != nil {
+				if record.RequestHash != requestHash {
+					writeJSONError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(record.StatusCode)
+				w.Write([]byte(record.Body))
+				return
+			}
+		}
+
+		// Replays don't fetch anything, so only new extractions count
+		config := live.get()
+		if !allowPages(w, r, config.limiter, 1) {
+			return
+		}
+		result, err := extractForRequest(r, config, src)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			writeJSONError(w, extractStatusCode(err), err.Error())
+			return
+		}
+
+		now := time.Now()
+		if err := saveExtraction(store, src, result, now, tag); err != nil {
+			log.Printf("Failed to store %s: %v", src, err)
+			writeJSONError(w, http.StatusInternalServerError, "Failed to store data: "+err.Error())
+			return
+		}
+
+		body, err := json.Marshal(storeResponse{
+			URL:        src,
+			Title:      result.Title,
+			Stored:     len(result.Quads),
+			Truncated:  result.Truncated,
+			RevisionID: result.RevisionID,
+		})
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, "Failed to encode response: "+err.Error())
+			return
+		}
+		body = append(body, '\n')
+
+		if key != "" {
+			err := store.SaveIdempotencyRecord(storage.IdempotencyRecord{
+				Key:         key,
+				RequestHash: requestHash,
+				StatusCode:  http.StatusOK,
+				Body:        string(body),
+				CreatedAt:   now,
+			})
+			if err != nil {
+				// The quads are stored; a retry would store them again, but
+				// failing this request would invite exactly that retry
+				log.Printf("Failed to record idempotency key: %v", err)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}
+}
+
+// idempotencyRequestHash fingerprints the parts of a /store request that must
+// match when an Idempotency-Key is reused
+func idempotencyRequestHash(method, src, tag string) string {
+	sum := sha256.Sum256([]byte(method + " " + src + " " + tag))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var maintenanceCmd = &cobra.Command{
+	Use:     "maintenance",
+	Aliases: []string{"vacuum"},
+	Short:   "Compact the database and refresh its statistics",
+	Long: `Compact the database with VACUUM, reclaiming the space left by replaced
+and deleted quads, refresh the query planner's statistics with ANALYZE and
+rebuild any full-text search indexes. The database size before and after is
+reported.
+
+The database is rewritten while the command runs, so it refuses to start
+while another process, such as a store, crawl or the HTTP service, is
+writing to it.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Initialize storage
+		dbPath := "quads.db"
+		store, err := storage.NewSQLiteStorage(dbPath)
+		if err != nil {
+			log.Fatalf("Failed to initialize storage: %v", err)
+		}
+		defer store.Close()
+
+		report, err := store.Maintain(context.Background())
+		if errors.Is(err, storage.ErrDatabaseBusy) {
+			store.Close()
+			fmt.Fprintln(os.Stderr, "The database is being written to by another process; try again once it finishes")
+			os.Exit(exitGeneralError)
+		}
+		if err != nil {
+			log.Fatalf("Maintenance failed: %v", err)
+		}
+
+		if len(report.RebuiltIndexes) > 0 {
+			fmt.Printf("Rebuilt full-text indexes: %s\n", strings.Join(report.RebuiltIndexes, ", "))
+		}
+		fmt.Printf("Database size: %s -> %s (%s reclaimed)\n",
+			formatBytes(report.SizeBefore), formatBytes(report.SizeAfter),
+			formatBytes(max(report.SizeBefore-report.SizeAfter, 0)))
+	},
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. 1.5 MiB
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	rootCmd.AddCommand(maintenanceCmd)
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+	"github.com/spf13/viper"
+)
+
+// extractionProfile is a named config file entry tailoring extraction to a
+// kind of article, such as people or films
+type extractionProfile struct {
+	InfoboxSelector      string                `mapstructure:"infobox_selector"`
+	Relationships        []relationshipMapping `mapstructure:"relationships"`
+	IncludeRelationships []string              `mapstructure:"include_relationships"`
+	ExcludeRelationships []string              `mapstructure:"exclude_relationships"`
+}
+
+// loadProfile reads the profile called name from the config file's profiles
+// section
+func loadProfile(name string) (*extractionProfile, error) {
+	profiles := viper.GetStringMap("profiles")
+	// Viper lowercases keys, so profile names are matched ignoring case
+	key := strings.ToLower(name)
+	if _, ok := profiles[key]; !ok {
+		if len(profiles) == 0 {
+			return nil, fmt.Errorf("unknown profile %q: the config file defines no profiles", name)
+		}
+		names := make([]string, 0, len(profiles))
+		for n := range profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown profile %q (profiles in config: %s)", name, strings.Join(names, ", "))
+	}
+
+	var profile extractionProfile
+	if err := viper.UnmarshalKey("profiles."+key, &profile); err != nil {
+		return nil, fmt.Errorf("invalid profile %q: %w", name, err)
+	}
+	return &profile, nil
+}
+
+// apply sets the profile's selectors, mappings and filters on ext. Its
+// relationship mappings are added to the global ones, taking precedence over
+// them, unless canonicalization is disabled.
+func (p *extractionProfile) apply(ext *extractor.Extractor) {
+	if p.InfoboxSelector != "" {
+		ext.Options.InfoboxSelector = p.InfoboxSelector
+	}
+	if ext.Options.Relationships != nil {
+		for _, mapping := range p.Relationships {
+			ext.Options.Relationships.Add(mapping.Canonical, mapping.Synonyms...)
+		}
+	}
+	ext.Options.IncludeRelationships = p.IncludeRelationships
+	ext.Options.ExcludeRelationships = p.ExcludeRelationships
+}
@@ -1,12 +1,16 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+	"github.com/chetankale/wikipedia-extraction/internal/output"
 	"github.com/chetankale/wikipedia-extraction/internal/storage"
 	"github.com/spf13/cobra"
 )
@@ -14,11 +18,27 @@ import (
 var (
 	querySubject     string
 	queryRelationship string
+	queryValue       string
+	queryValueExact  bool
 	querySourceURL   string
 	querySearch      string
+	queryTag         string
+	queryCitationDomain string
 	queryStats       bool
+	queryCorroborated bool
+	queryMergeSources bool
+	querySince       string
+	queryUntil       string
+	queryFuzzy       bool
+	queryFuzzyLimit  int
+	querySort        string
+	queryLang        string
 )
 
+// minCorroboratingSources is how many distinct source URLs must assert a fact
+// for query --corroborated to list it
+const minCorroboratingSources = 2
+
 var queryCmd = &cobra.Command{
 	Use:   "query",
 	Short: "Query stored quads from the database",
@@ -44,25 +64,132 @@ You can search by subject, relationship, source URL, or use full-text search.`,
 				log.Fatalf("Failed to get stats: %v", err)
 			}
 			
+			// Machine-readable output only when --format is given explicitly
+			if cmd.Flags().Changed("format") {
+				formatter, err := newReportFormatter()
+				if err != nil {
+					log.Fatalf("Invalid output options: %v", err)
+				}
+				if err := formatter.WriteStats(stats, os.Stdout, format); err != nil {
+					log.Fatalf("Failed to write stats: %v", err)
+				}
+				return
+			}
+			
 			fmt.Printf("Database Statistics:\n")
 			fmt.Printf("  Total Quads: %d\n", stats.TotalQuads)
 			fmt.Printf("  Total Subjects: %d\n", stats.TotalSubjects)
 			fmt.Printf("  Total Sources: %d\n", stats.TotalSources)
 			fmt.Printf("  Last Extraction: %s\n", stats.LastExtraction)
+			if len(stats.Tags) > 0 {
+				fmt.Printf("\nBy Tag:\n")
+				for _, tag := range stats.Tags {
+					name := tag.Tag
+					if name == "" {
+						name = "(untagged)"
+					}
+					fmt.Printf("  %s: %d quads, %d subjects, %d sources\n", name, tag.Quads, tag.Subjects, tag.Sources)
+				}
+			}
 			return
 
+		case queryCorroborated:
+			facts, err := store.GetCorroborated(minCorroboratingSources)
+			if err != nil {
+				log.Fatalf("Failed to query data: %v", err)
+			}
+
+			// Machine-readable output only when --format is given explicitly
+			if cmd.Flags().Changed("format") {
+				formatter, err := newReportFormatter()
+				if err != nil {
+					log.Fatalf("Invalid output options: %v", err)
+				}
+				if err := formatter.WriteCorroboratedFacts(facts, os.Stdout, format); err != nil {
+					log.Fatalf("Failed to write output: %v", err)
+				}
+				return
+			}
+
+			if len(facts) == 0 {
+				fmt.Println("No facts are stored from more than one source.")
+				return
+			}
+			fmt.Printf("Found %d facts stored from %d or more sources:\n\n", len(facts), minCorroboratingSources)
+			for _, fact := range facts {
+				fmt.Printf("  %s | %s | %s (%d sources)\n", fact.Subject, fact.Relationship, fact.Value, fact.Sources)
+			}
+			return
+
+		case wantsRecordColumns():
+			// Metadata columns need the stored records rather than bare quads
+			queryRecords(store)
+			return
+
+		case queryMergeSources, format == "nquads":
+			// Merging and N-Quads graphs need each quad's source URL, which
+			// only records carry
+			filter := queryFilter()
+			if filter == (storage.QueryFilter{}) {
+				fmt.Println("Please specify a query type. Use --help for options.")
+				return
+			}
+			records, err := store.QueryRecords(filter)
+			if err != nil {
+				log.Fatalf("Failed to query data: %v", err)
+			}
+			for _, record := range records {
+				quads = append(quads, record.Quad())
+			}
+
+		case queryFuzzy:
+			if querySubject == "" {
+				log.Fatalf("--fuzzy requires --subject")
+			}
+			matches, err := store.GetBySubjectFuzzy(querySubject, queryFuzzyLimit)
+			if err != nil {
+				log.Fatalf("Failed to query data: %v", err)
+			}
+			// The ranking goes to stderr so stdout holds only the quads
+			fmt.Fprintf(os.Stderr, "Closest subjects to %q:\n", querySubject)
+			for i, match := range matches {
+				fmt.Fprintf(os.Stderr, "  %d. %s (distance %d, %d quads)\n", i+1, match.Subject, match.Distance, len(match.Quads))
+				quads = append(quads, match.Quads...)
+			}
+
 		case querySubject != "":
 			quads, err2 = store.GetBySubject(querySubject)
 
 		case queryRelationship != "":
 			quads, err2 = store.GetByRelationship(queryRelationship)
 
+		case queryValue != "":
+			quads, err2 = store.GetByValue(queryValue, queryValueExact)
+
 		case querySourceURL != "":
 			quads, err2 = store.GetBySourceURL(querySourceURL)
 
+		case queryTag != "":
+			quads, err2 = store.GetByTag(queryTag)
+
+		case queryCitationDomain != "":
+			quads, err2 = store.GetByCitationDomain(queryCitationDomain)
+
 		case querySearch != "":
 			quads, err2 = store.Search(querySearch)
 
+		case querySince != "" || queryUntil != "":
+			now := time.Now()
+			since, err := parseTimeFlag(querySince, now)
+			if err != nil {
+				log.Fatalf("Invalid --since: %v", err)
+			}
+			until, err := parseTimeFlag(queryUntil, now)
+			if err != nil {
+				log.Fatalf("Invalid --until: %v", err)
+			}
+			quads, err2 = store.GetByTimeRange(since, until)
+
 		default:
 			fmt.Println("Please specify a query type. Use --help for options.")
 			return
@@ -72,48 +199,204 @@ You can search by subject, relationship, source URL, or use full-text search.`,
 			log.Fatalf("Failed to query data: %v", err2)
 		}
 
+		if onlyWithCitations {
+			quads = extractor.RequireCitation(quads)
+		}
+		if firstOnly {
+			quads = extractor.KeepFirstPerRelationship(quads)
+		}
+		if queryMergeSources {
+			quads = extractor.MergeSources(quads)
+		}
+		if querySort != "" {
+			sorter, err := newQuadSorter(querySort, queryLang)
+			if err != nil {
+				log.Fatalf("Failed to sort results: %v", err)
+			}
+			sorter.sort(quads)
+		}
+
 		// Output results
 		if len(quads) == 0 {
 			fmt.Println("No quads found matching the query.")
 			return
 		}
 
-		fmt.Printf("Found %d quads:\n\n", len(quads))
-
-		// Output in the specified format
-		switch format {
-		case "json":
-			encoder := json.NewEncoder(os.Stdout)
-			encoder.SetIndent("", "  ")
-			encoder.Encode(quads)
-		case "csv":
-			// Simple CSV output
-			fmt.Println("Subject,Relationship,Value,Citation")
-			for _, quad := range quads {
-				fmt.Printf("\"%s\",\"%s\",\"%s\",\"%s\"\n",
-					quad.Subject, quad.Relationship, quad.Value, quad.Citation)
+		// The table view is for reading in a terminal; any other format goes through the formatter
+		if format != "table" || outputTemplate != "" {
+			formatter, err := newFormatter()
+			if err != nil {
+				log.Fatalf("Invalid output options: %v", err)
 			}
-		default:
-			// Default table format
-			for i, quad := range quads {
-				fmt.Printf("Quad %d:\n", i+1)
-				fmt.Printf("  Subject: %s\n", quad.Subject)
-				fmt.Printf("  Relationship: %s\n", quad.Relationship)
-				fmt.Printf("  Value: %s\n", quad.Value)
-				fmt.Printf("  Citation: %s\n", quad.Citation)
-				fmt.Println()
+			fmt.Fprintf(os.Stderr, "Found %d quads\n", len(quads))
+			if err := formatter.WriteQuads(quads, os.Stdout, format); err != nil {
+				log.Fatalf("Failed to write output: %v", err)
+			}
+			return
+		}
+
+		fmt.Printf("Found %d quads:\n\n", len(quads))
+		for i, quad := range quads {
+			fmt.Printf("Quad %d:\n", i+1)
+			fmt.Printf("  Subject: %s\n", quad.Subject)
+			fmt.Printf("  Relationship: %s\n", quad.Relationship)
+			fmt.Printf("  Value: %s\n", quad.Value)
+			fmt.Printf("  Citation: %s\n", displayCitation(quad.Citation))
+			if queryMergeSources {
+				fmt.Printf("  Sources: %s\n", strings.Join(quad.Sources, ", "))
 			}
+			fmt.Println()
 		}
 	},
 }
 
+// wantsRecordColumns reports whether --columns asks for storage metadata
+// such as id, source_url, extracted_at or tag
+func wantsRecordColumns() bool {
+	if columns == "" {
+		return false
+	}
+	cols, err := output.ParseColumns(columns)
+	return err == nil && output.HasRecordColumns(cols)
+}
+
+// queryFilter combines all of the query's filter flags
+func queryFilter() storage.QueryFilter {
+	now := time.Now()
+	since, err := parseTimeFlag(querySince, now)
+	if err != nil {
+		log.Fatalf("Invalid --since: %v", err)
+	}
+	until, err := parseTimeFlag(queryUntil, now)
+	if err != nil {
+		log.Fatalf("Invalid --until: %v", err)
+	}
+	return storage.QueryFilter{
+		Subject:        querySubject,
+		Relationship:   queryRelationship,
+		Value:          queryValue,
+		ValueExact:     queryValueExact,
+		SourceURL:      querySourceURL,
+		Tag:            queryTag,
+		CitationDomain: queryCitationDomain,
+		Search:         querySearch,
+		Since:          since,
+		Until:          until,
+	}
+}
+
+// queryRecords writes the stored records matching all of the query's filter
+// flags, with their metadata, in --format
+func queryRecords(store storage.Storage) {
+	filter := queryFilter()
+	if filter == (storage.QueryFilter{}) {
+		fmt.Println("Please specify a query type. Use --help for options.")
+		return
+	}
+
+	if format != "json" && format != "jsonl" && format != "csv" && format != "tsv" {
+		log.Fatalf("Metadata columns (id, source_url, extracted_at, tag) require --format json, jsonl, csv or tsv")
+	}
+	formatter, err := newFormatter()
+	if err != nil {
+		log.Fatalf("Invalid output options: %v", err)
+	}
+
+	records, err := store.QueryRecords(filter)
+	if err != nil {
+		log.Fatalf("Failed to query data: %v", err)
+	}
+	if querySort != "" {
+		sorter, err := newQuadSorter(querySort, queryLang)
+		if err != nil {
+			log.Fatalf("Failed to sort results: %v", err)
+		}
+		sort.SliceStable(records, func(i, j int) bool {
+			return sorter.less(records[i].Quad(), records[j].Quad())
+		})
+	}
+
+	type key struct{ subject, relationship string }
+	seen := make(map[key]bool)
+	var kept []storage.QuadRecord
+	for _, record := range records {
+		if onlyWithCitations && !extractor.HasCitation(record.Quad()) {
+			continue
+		}
+		if firstOnly {
+			k := key{record.Subject, record.Relationship}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+		}
+		kept = append(kept, record)
+	}
+
+	if len(kept) == 0 {
+		fmt.Println("No quads found matching the query.")
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Found %d quads\n", len(kept))
+	if err := formatter.WriteRecords(kept, os.Stdout, format); err != nil {
+		log.Fatalf("Failed to write output: %v", err)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(queryCmd)
 	
 	// Query flags
 	queryCmd.Flags().StringVar(&querySubject, "subject", "", "Search by subject")
 	queryCmd.Flags().StringVar(&queryRelationship, "relationship", "", "Search by relationship")
+	queryCmd.Flags().StringVar(&queryValue, "value", "", "Search by value, e.g. to find every subject whose occupation is Physicist")
+	queryCmd.Flags().BoolVar(&queryValueExact, "value-exact", false, "Match --value exactly, including case, instead of as a substring")
 	queryCmd.Flags().StringVar(&querySourceURL, "source", "", "Search by source URL")
-	queryCmd.Flags().StringVar(&querySearch, "search", "", "Full-text search")
+	queryCmd.Flags().StringVar(&querySearch, "search", "", "Full-text search; combine terms with AND and OR, quote phrases")
+	queryCmd.Flags().StringVar(&queryTag, "tag", "", "Query quads stored with this tag")
+	queryCmd.Flags().StringVar(&queryCitationDomain, "citation-domain", "", "Query quads citing this domain or its subdomains, e.g. nytimes.com")
 	queryCmd.Flags().BoolVar(&queryStats, "stats", false, "Show database statistics")
+	queryCmd.Flags().BoolVar(&queryCorroborated, "corroborated", false, "List facts stored with the same subject, relationship and value from two or more source URLs")
+	queryCmd.Flags().BoolVar(&queryMergeSources, "merge-sources", false, "Collapse quads with the same subject, relationship and value from different sources into one, listing its sources")
+	queryCmd.Flags().BoolVar(&queryFuzzy, "fuzzy", false, "Match --subject by edit distance, returning the quads of the closest subjects first")
+	queryCmd.Flags().IntVar(&queryFuzzyLimit, "fuzzy-limit", storage.DefaultFuzzyLimit, "Number of subjects returned by --fuzzy")
+	queryCmd.Flags().StringVar(&querySort, "sort", "", "Sort results by these comma-separated fields (subject, relationship, value) using the collation of --lang")
+	queryCmd.Flags().StringVar(&queryLang, "lang", "en", "BCP 47 language tag whose collation rules --sort follows, e.g. de, sv or fr-CA")
+	queryCmd.Flags().StringVar(&querySince, "since", "", "Only quads extracted at or after this time (RFC3339, or relative like 7d, 12h)")
+	queryCmd.Flags().StringVar(&queryUntil, "until", "", "Only quads extracted at or before this time (RFC3339, or relative like 7d, 12h)")
+}
+
+// relativeUnits maps the suffixes accepted in relative time flags to durations
+var relativeUnits = map[byte]time.Duration{
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// parseTimeFlag parses an RFC3339 timestamp, a YYYY-MM-DD date, or a relative
+// duration such as "7d" meaning that long before now. An empty value yields
+// the zero time.
+func parseTimeFlag(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+
+	unit, ok := relativeUnits[value[len(value)-1]]
+	if ok {
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && n >= 0 {
+			return now.Add(-time.Duration(n) * unit), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%q is not an RFC3339 time, a date, or a relative time like 7d", value)
 } 
\ No newline at end of file
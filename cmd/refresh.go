@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	refreshConcurrency int
+	refreshDelay       time.Duration
+	refreshKeepHistory bool
+)
+
+var refreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Re-extract every source stored in the database",
+	Long: `Re-extract every source URL already stored in the database, so stored
+quads pick up improvements to the extractor.
+
+Each new extraction replaces all of the source's stored quads, including
+earlier extractions, and keeps the tag of its latest extraction. With
+--keep-history the new extraction is stored next to the earlier ones instead,
+so diff can show what changed. Sources that fail to extract are left
+unchanged.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Initialize storage
+		dbPath := "quads.db"
+		store, err := storage.NewSQLiteStorage(dbPath)
+		if err != nil {
+			log.Fatalf("Failed to initialize storage: %v", err)
+		}
+		defer store.Close()
+
+		urls, err := store.GetSourceURLs()
+		if err != nil {
+			log.Fatalf("Failed to list sources: %v", err)
+		}
+		if len(urls) == 0 {
+			fmt.Println("No stored sources to refresh")
+			return
+		}
+
+		// Create extractor
+		ext, err := newExtractor()
+		if err != nil {
+			log.Fatalf("Failed to configure extractor: %v", err)
+		}
+		if err := ext.SetRateLimit(refreshDelay, refreshConcurrency); err != nil {
+			log.Fatalf("Failed to configure extractor: %v", err)
+		}
+
+		fmt.Printf("Refreshing %d sources\n", len(urls))
+
+		var mu sync.Mutex
+		var succeeded, failed int
+		queue := make(chan string)
+		var wg sync.WaitGroup
+		for i := 0; i < min(refreshConcurrency, len(urls)); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for url := range queue {
+					count, err := refreshSource(ext, store, url)
+
+					mu.Lock()
+					if err != nil {
+						failed++
+						fmt.Printf("FAIL %s: %v\n", url, err)
+					} else {
+						succeeded++
+						fmt.Printf("OK   %s (%d quads)\n", url, count)
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+		for _, url := range urls {
+			queue <- url
+		}
+		close(queue)
+		wg.Wait()
+
+		fmt.Printf("\nRefreshed %d of %d sources (%d failed)\n", succeeded, len(urls), failed)
+		if failed > 0 {
+			os.Exit(exitGeneralError)
+		}
+	},
+}
+
+// refreshSource re-extracts one source and stores the new extraction,
+// returning the number of quads stored
+func refreshSource(ext *extractor.Extractor, store storage.Storage, url string) (int, error) {
+	result, err := ext.Extract(url)
+	if err != nil {
+		return 0, err
+	}
+	if result.NoStructuredData {
+		warnNoStructuredData(url)
+	}
+	warnUnresolvedCitations(url, result)
+	warnValidationFailures(url, result)
+	archivePage(result, time.Now())
+
+	now := time.Now()
+	if refreshKeepHistory {
+		err = store.RefreshSource(result.Quads, url, now)
+	} else {
+		err = store.ReplaceSource(result.Quads, url, now)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := store.SaveSource(sourceRecord(url, result, now)); err != nil {
+		return 0, err
+	}
+
+	return len(result.Quads), nil
+}
+
+func init() {
+	rootCmd.AddCommand(refreshCmd)
+
+	refreshCmd.Flags().IntVar(&refreshConcurrency, "concurrency", 2, "Number of sources to extract at once")
+	refreshCmd.Flags().DurationVar(&refreshDelay, "delay", time.Second, "Pause between requests to the same host")
+	refreshCmd.Flags().BoolVar(&refreshKeepHistory, "keep-history", false, "Keep each source's earlier extractions instead of replacing them")
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/chetankale/wikipedia-extraction/internal/output"
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportMinFacts         int
+	reportTopRelationships int
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarize the quality of the stored data",
+	Long: `Summarize the health of the stored data: the share of quads with a
+citation, the subjects with fewer than --min-facts distinct facts, the most
+common relationships, and the fetched source URLs that yielded no quads.
+
+Pass --format json, yaml, csv or tsv for machine-readable output.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if reportMinFacts < 1 {
+			log.Fatalf("Invalid --min-facts: must be at least 1, got %d", reportMinFacts)
+		}
+		if reportTopRelationships < 0 {
+			log.Fatalf("Invalid --top-relationships: must not be negative, got %d", reportTopRelationships)
+		}
+
+		// Initialize storage
+		dbPath := "quads.db"
+		store, err := storage.NewSQLiteStorage(dbPath)
+		if err != nil {
+			log.Fatalf("Failed to initialize storage: %v", err)
+		}
+		defer store.Close()
+
+		report, err := store.GetQualityReport(reportMinFacts, reportTopRelationships)
+		if err != nil {
+			log.Fatalf("Failed to compute report: %v", err)
+		}
+
+		// Machine-readable output only when --format is given explicitly
+		if cmd.Flags().Changed("format") {
+			formatter := output.NewFormatter()
+			formatter.Indent = jsonIndent()
+			if err := formatter.WriteQualityReport(report, os.Stdout, format); err != nil {
+				log.Fatalf("Failed to write report: %v", err)
+			}
+			return
+		}
+
+		if err := output.WriteQualityReportText(report, os.Stdout); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().IntVar(&reportMinFacts, "min-facts", 5, "List subjects with fewer than this many distinct facts")
+	reportCmd.Flags().IntVar(&reportTopRelationships, "top-relationships", 10, "Number of most common relationships to list")
+}
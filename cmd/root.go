@@ -3,7 +3,11 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+	"github.com/chetankale/wikipedia-extraction/internal/output"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -12,6 +16,53 @@ var (
 	cfgFile string
 	outputFile string
 	format  string
+	maxQuads int
+	columns  string
+	proxyURLs []string
+	proxyFile string
+	noCanonicalize bool
+	maxBodyBytes int
+	allowSisterProjects bool
+	keepHTML bool
+	citationSeparator string
+	maxCitations int
+	citationDepth int
+	maxValueLength int
+	maxCitationLength int
+	lengthPolicy string
+	strictCitations string
+	validateValues string
+	saveHTML string
+	wikiBaseURL string
+	wikiCookie string
+	wikiToken string
+	compact bool
+	indentWidth int
+	withSchema bool
+	outputTemplate string
+	onlyWithCitations bool
+	showNoCitation bool
+	strict bool
+	timeout time.Duration
+	insecureSkipVerify bool
+	verbose bool
+	normalizeNumbers bool
+	firstOnly bool
+	splitUnits bool
+	countryCodes bool
+	typedTables bool
+	inferType bool
+	shortDescription bool
+	followSeeAlso bool
+	profile string
+	dedupe bool
+	dedupeCapacity int
+	dedupeFalsePositiveRate float64
+	csvBOM bool
+	csvHeaders string
+	maxCellLength int
+	baseIRI string
+	predicateNamespace string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -34,7 +85,56 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.wikipedia-extraction.yaml)")
 	rootCmd.PersistentFlags().StringVar(&outputFile, "output", "output.json", "output file path")
-	rootCmd.PersistentFlags().StringVar(&format, "format", "json", "output format (json, csv, xml)")
+	rootCmd.PersistentFlags().StringVar(&format, "format", "json", "output format ("+strings.Join(output.Formats(), ", ")+")")
+	rootCmd.PersistentFlags().BoolVar(&compact, "compact", false, "write JSON on a single line without indentation")
+	rootCmd.PersistentFlags().BoolVar(&withSchema, "with-schema", false, "precede sql output with the CREATE TABLE statement")
+	rootCmd.PersistentFlags().IntVar(&indentWidth, "indent", len(output.DefaultIndent), "number of spaces to indent JSON output by (0 writes compact JSON)")
+	rootCmd.PersistentFlags().StringVar(&outputTemplate, "template", "", "Go text/template executed for each quad, e.g. '{{.Subject}}: {{.Value}}' (overrides --format)")
+	rootCmd.PersistentFlags().StringVar(&columns, "columns", "", "comma-separated fields to output for csv, tsv, sql, markdown and json (subject,subject_url,relationship,value,value_url,citation,source,language,section,project,raw_html,value_type,raw_value,number,metric,imperial,country_alpha2,country_alpha3,sources; query also accepts id,source_url,extracted_at,tag)")
+	rootCmd.PersistentFlags().BoolVar(&csvBOM, "csv-bom", false, "start csv and tsv output with a UTF-8 byte order mark so Excel detects the encoding")
+	rootCmd.PersistentFlags().IntVar(&maxCellLength, "max-cell-length", 0, "truncate markdown table cells longer than this many characters (0 means no limit)")
+	rootCmd.PersistentFlags().StringVar(&csvHeaders, "csv-headers", "", "override csv and tsv header titles, e.g. 'subject=Entity,value=Fact'")
+	rootCmd.PersistentFlags().StringVar(&baseIRI, "base-iri", "", "namespace IRI for subjects in turtle and nquads output, e.g. https://example.org/entity/ (default "+output.DefaultBaseIRI+")")
+	rootCmd.PersistentFlags().StringVar(&predicateNamespace, "predicate-namespace", "", "namespace IRI for predicates in turtle and nquads output, e.g. https://example.org/ontology# (default "+output.DefaultPredicateNamespace+")")
+	rootCmd.PersistentFlags().StringSliceVar(&proxyURLs, "proxy", nil, "proxy URL to fetch pages through (http, https or socks5); repeat to rotate between several")
+	rootCmd.PersistentFlags().StringVar(&proxyFile, "proxy-file", "", "file listing proxy URLs, one per line, to rotate between")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", extractor.DefaultTimeout, "maximum time to spend fetching a page (0 means no limit)")
+	rootCmd.PersistentFlags().BoolVar(&insecureSkipVerify, "insecure-skip-verify", false, "accept any TLS certificate, e.g. a private wiki's self-signed one (insecure)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "named extraction profile from the config file's profiles section, e.g. person or film")
+	rootCmd.PersistentFlags().BoolVar(&dedupe, "dedupe", false, "in extract, drop quads already extracted from an earlier page of the run, remembered approximately in a bloom filter; store and crawl keep every quad")
+	rootCmd.PersistentFlags().IntVar(&dedupeCapacity, "dedupe-capacity", extractor.DefaultDedupeCapacity, "number of distinct quads the --dedupe bloom filter is sized for; more raise its false positive rate")
+	rootCmd.PersistentFlags().Float64Var(&dedupeFalsePositiveRate, "dedupe-fp-rate", extractor.DefaultDedupeFalsePositiveRate, "share of new quads --dedupe may wrongly drop as already seen, up to --dedupe-capacity quads")
+	rootCmd.PersistentFlags().BoolVar(&noCanonicalize, "no-canonicalize", false, "keep relationship labels exactly as they appear on the page")
+	rootCmd.PersistentFlags().BoolVar(&showNoCitation, "show-no-citation", false, "show \"no citation\" for uncited quads in human-readable output")
+	rootCmd.PersistentFlags().BoolVar(&onlyWithCitations, "only-with-citations", false, "drop quads that have no citation")
+	rootCmd.PersistentFlags().BoolVar(&firstOnly, "first-only", false, "keep only the first value of each relationship per subject")
+	rootCmd.PersistentFlags().BoolVar(&strict, "strict", false, "fail when a page has no infobox or tables instead of warning and extracting nothing")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "log diagnostics to stderr, such as infobox rows skipped for having a label but no value")
+	rootCmd.PersistentFlags().IntVar(&maxQuads, "max-quads", 0, "maximum number of quads to extract per page (0 means unlimited)")
+	rootCmd.PersistentFlags().IntVar(&maxBodyBytes, "max-body-bytes", extractor.DefaultMaxBodyBytes, "abort extraction of pages whose decoded response body exceeds this many bytes")
+	rootCmd.PersistentFlags().BoolVar(&allowSisterProjects, "allow-sister-projects", false, "also accept pages from Wikimedia sister projects such as Wiktionary, Wikivoyage and Wikispecies")
+	rootCmd.PersistentFlags().BoolVar(&splitUnits, "split-units", false, "split measurements shown in both unit systems, like \"100 km (62 mi)\", into the metric and imperial fields")
+	rootCmd.PersistentFlags().BoolVar(&countryCodes, "country-codes", false, "add the ISO 3166-1 codes of countries named in infobox values, by flag or by name, in the country_alpha2 and country_alpha3 fields")
+	rootCmd.PersistentFlags().BoolVar(&typedTables, "typed-tables", false, "read tables with a rank or percent column, such as election results, as one record per row with normalized, typed values")
+	rootCmd.PersistentFlags().BoolVar(&inferType, "infer-type", false, "add a \"type\" quad giving the subject's schema.org type, such as Person or Place, inferred from its infobox")
+	rootCmd.PersistentFlags().BoolVar(&followSeeAlso, "follow-see-also", false, "collect the articles listed in a page's \"See also\" section; crawl also follows them")
+	rootCmd.PersistentFlags().BoolVar(&shortDescription, "short-description", false, "add a \"description\" quad with the page's short description, when it has one")
+	rootCmd.PersistentFlags().BoolVar(&normalizeNumbers, "normalize-numbers", false, "add the canonical form of numeric values in the number field, reading separators according to the page language")
+	rootCmd.PersistentFlags().StringVar(&saveHTML, "save-html", "", "archive the HTML of each fetched page in this directory, named by title and revision, to reprocess it offline later")
+	rootCmd.PersistentFlags().BoolVar(&keepHTML, "keep-html", false, "include the inner HTML of each value cell in the raw_html field")
+	rootCmd.PersistentFlags().StringVar(&citationSeparator, "citation-separator", extractor.DefaultCitationSeparator, "separator placed between the citations of a quad")
+	rootCmd.PersistentFlags().IntVar(&maxCitations, "max-citations", 0, "maximum number of citations listed per quad, summarizing the rest (0 means unlimited)")
+	rootCmd.PersistentFlags().IntVar(&maxValueLength, "max-value-length", extractor.DefaultMaxValueLength, "maximum length of a quad's value in characters, enforced by --length-policy")
+	rootCmd.PersistentFlags().IntVar(&maxCitationLength, "max-citation-length", extractor.DefaultMaxCitationLength, "maximum length of a quad's citation in characters, enforced by --length-policy")
+	rootCmd.PersistentFlags().StringVar(&lengthPolicy, "length-policy", string(extractor.LengthPolicyTruncate), "what to do with quads over --max-value-length or --max-citation-length: truncate (with an ellipsis), skip or keep")
+	rootCmd.PersistentFlags().StringVar(&strictCitations, "strict-citations", string(extractor.CitationPolicyOff), "what to do when footnote markers link to no reference: off, warn or error (--strict-citations alone means warn)")
+	rootCmd.PersistentFlags().Lookup("strict-citations").NoOptDefVal = string(extractor.CitationPolicyWarn)
+	rootCmd.PersistentFlags().StringVar(&validateValues, "validate-values", string(extractor.ValidationPolicyOff), "check values against their relationship's rule, e.g. Website must be a URL and Born a date, and flag or drop failing quads: off, flag or drop (--validate-values alone means flag)")
+	rootCmd.PersistentFlags().Lookup("validate-values").NoOptDefVal = string(extractor.ValidationPolicyFlag)
+	rootCmd.PersistentFlags().IntVar(&citationDepth, "citation-depth", extractor.DefaultCitationDepth, "how many footnotes deep to follow a footnote that cites other references instead of a source")
+	rootCmd.PersistentFlags().StringVar(&wikiBaseURL, "wiki-base-url", "", "base URL of a private MediaWiki instance to accept pages from, e.g. https://wiki.example.com")
+	rootCmd.PersistentFlags().StringVar(&wikiCookie, "wiki-cookie", "", "Cookie header sent to the private wiki (or set WIKI_COOKIE)")
+	rootCmd.PersistentFlags().StringVar(&wikiToken, "wiki-token", "", "OAuth bearer token sent to the private wiki (or set WIKI_TOKEN)")
 
 }
 
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// sortFields are the quad fields query --sort accepts
+var sortFields = map[string]func(extractor.Quad) string{
+	"subject":      func(q extractor.Quad) string { return q.Subject },
+	"relationship": func(q extractor.Quad) string { return q.Relationship },
+	"value":        func(q extractor.Quad) string { return q.Value },
+}
+
+// quadSorter orders quads by one or more fields, comparing them with the
+// collation rules of a language
+type quadSorter struct {
+	keys     []func(extractor.Quad) string
+	collator *collate.Collator
+}
+
+// newQuadSorter parses a comma-separated list of sort fields, such as
+// "subject,relationship", and a BCP 47 language tag for the collation
+func newQuadSorter(spec, lang string) (*quadSorter, error) {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --lang %q: %w", lang, err)
+	}
+
+	sorter := &quadSorter{collator: collate.New(tag)}
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(strings.ToLower(field))
+		key, ok := sortFields[field]
+		if !ok {
+			return nil, fmt.Errorf("unknown sort field %q (valid fields: subject, relationship, value)", field)
+		}
+		sorter.keys = append(sorter.keys, key)
+	}
+	return sorter, nil
+}
+
+// less reports whether a sorts before b. Later fields break ties of earlier ones.
+func (s *quadSorter) less(a, b extractor.Quad) bool {
+	for _, key := range s.keys {
+		if c := s.collator.CompareString(key(a), key(b)); c != 0 {
+			return c < 0
+		}
+	}
+	return false
+}
+
+// sort orders quads in place, keeping quads that compare equal in their
+// original order
+func (s *quadSorter) sort(quads []extractor.Quad) {
+	sort.SliceStable(quads, func(i, j int) bool {
+		return s.less(quads[i], quads[j])
+	})
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/chetankale/wikipedia-extraction/internal/output"
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var sourcesCmd = &cobra.Command{
+	Use:   "sources",
+	Short: "List the stored source pages",
+	Long: `List every source URL with quads in the database, with the number of
+quads stored from it and when it was last extracted.
+
+Pass --format json, yaml, csv or tsv for machine-readable output.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Initialize storage
+		dbPath := "quads.db"
+		store, err := storage.NewSQLiteStorage(dbPath)
+		if err != nil {
+			log.Fatalf("Failed to initialize storage: %v", err)
+		}
+		defer store.Close()
+
+		sources, err := store.ListSources()
+		if err != nil {
+			log.Fatalf("Failed to list sources: %v", err)
+		}
+
+		// Machine-readable output only when --format is given explicitly
+		if cmd.Flags().Changed("format") {
+			formatter := output.NewFormatter()
+			formatter.Indent = jsonIndent()
+			if err := formatter.WriteSources(sources, os.Stdout, format); err != nil {
+				log.Fatalf("Failed to write sources: %v", err)
+			}
+			return
+		}
+
+		if len(sources) == 0 {
+			fmt.Println("No sources stored.")
+			return
+		}
+		fmt.Printf("Found %d sources:\n\n", len(sources))
+		for _, source := range sources {
+			fmt.Printf("  %s (%d quads, last extracted %s)\n", source.SourceURL, source.Quads, source.LastExtracted.Local().Format(time.DateTime))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sourcesCmd)
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// stdinHasInput reports whether stdin is a pipe or file rather than a
+// terminal, so URLs can be read from it
+func stdinHasInput() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
+
+// requireURLsOrStdin accepts up to maxArgs URL arguments (0 means any
+// number), or none when URLs are piped in on stdin
+func requireURLsOrStdin(maxArgs int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if maxArgs > 0 && len(args) > maxArgs {
+			return fmt.Errorf("accepts at most %d URL argument(s), received %d", maxArgs, len(args))
+		}
+		if len(args) == 0 && !stdinHasInput() {
+			return fmt.Errorf("requires a URL argument, or URLs piped in on stdin one per line")
+		}
+		return nil
+	}
+}
+
+// eachURL calls fn with every URL listed in r, one per line, as each line
+// arrives. Blank lines and lines starting with # are ignored.
+func eachURL(r io.Reader, fn func(url string)) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fn(line)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read URLs from stdin: %w", err)
+	}
+	return nil
+}
+
+// urlFailures counts the URLs read from stdin that failed, so that a run
+// goes on to the next line and still exits non-zero at the end
+type urlFailures struct {
+	count int
+	code  int
+}
+
+// record logs the error of a failed URL
+func (f *urlFailures) record(url string, err error) {
+	log.Printf("Failed to extract data from %s: %v", url, err)
+	code := extractExitCode(err)
+	if f.count > 0 && code != f.code {
+		code = exitGeneralError
+	}
+	f.count++
+	f.code = code
+}
+
+// exit exits with the exit code of the failures, the general error code when
+// they differ, if any URL failed
+func (f *urlFailures) exit() {
+	if f.count == 0 {
+		return
+	}
+	log.Printf("%d URLs failed", f.count)
+	os.Exit(f.code)
+}
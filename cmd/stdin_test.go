@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+func TestEachURL(t *testing.T) {
+	input := "https://en.wikipedia.org/wiki/Paris\n\n  # comment\n  https://en.wikipedia.org/wiki/Rome  \n"
+	var urls []string
+	if err := eachURL(strings.NewReader(input), func(url string) { urls = append(urls, url) }); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"https://en.wikipedia.org/wiki/Paris", "https://en.wikipedia.org/wiki/Rome"}
+	if !reflect.DeepEqual(urls, want) {
+		t.Errorf("eachURL = %q, want %q", urls, want)
+	}
+}
+
+func TestURLFailuresExitCode(t *testing.T) {
+	notFound := fmt.Errorf("%w: https://en.wikipedia.org/wiki/Missing", extractor.ErrNotFound)
+	invalid := fmt.Errorf("%w: bad", extractor.ErrInvalidURL)
+
+	var failures urlFailures
+	failures.record("a", notFound)
+	failures.record("b", notFound)
+	if failures.count != 2 || failures.code != exitNotFound {
+		t.Errorf("same errors: count %d, code %d, want 2, %d", failures.count, failures.code, exitNotFound)
+	}
+
+	failures.record("c", invalid)
+	if failures.count != 3 || failures.code != exitGeneralError {
+		t.Errorf("mixed errors: count %d, code %d, want 3, %d", failures.count, failures.code, exitGeneralError)
+	}
+}
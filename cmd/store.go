@@ -3,6 +3,8 @@ package cmd
 import (
 	"fmt"
 	"log"
+	"net/url"
+	"os"
 	"strings"
 	"time"
 
@@ -11,19 +13,52 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	storeIncremental bool
+	storeDiff        bool
+	storeTag         string
+	storeResume      string
+	storeAtomic      bool
+)
+
 var storeCmd = &cobra.Command{
 	Use:   "store [URL]",
 	Short: "Extract and store structured data from a Wikipedia page",
 	Long: `Extract structured information from a Wikipedia page URL and store it in the database.
 The tool will parse infoboxes and extract quads in the form of:
-(subject/entity, relationship, value, citation) and store them persistently.`,
-	Args: cobra.ExactArgs(1),
+(subject/entity, relationship, value, citation) and store them persistently.
+
+Without a URL argument, URLs are read from stdin one per line and each is
+stored as it arrives, e.g. cat urls.txt | wikipedia-extraction store.
+Blank lines and lines starting with # are ignored. A URL from stdin that
+fails is reported and skipped, and the command exits non-zero at the end.
+
+With --resume, each URL is recorded in a checkpoint file once it is stored,
+and URLs already listed there are skipped, so an interrupted run can be
+restarted with the same command. Combine it with --incremental to also skip
+pages that haven't changed since they were stored.
+
+With --atomic, every URL of the run is stored in one transaction: nothing
+is saved unless all of them are extracted and stored, so the first URL that
+fails stops the run. A page listed more than once, e.g. under its mobile and
+desktop URLs, is stored the first time only.`,
+	Args: requireURLsOrStdin(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		url := args[0]
-		
+		if storeAtomic && storeResume != "" {
+			log.Fatalf("--atomic can't be combined with --resume")
+		}
+
+		// Create extractor
+		ext, err := newExtractor()
+		if err != nil {
+			log.Fatalf("Failed to configure extractor: %v", err)
+		}
+
 		// Validate URL
-		if !strings.Contains(url, "wikipedia.org") {
-			log.Fatal("URL must be a Wikipedia page")
+		if len(args) > 0 {
+			if err := ext.ValidateURL(args[0]); err != nil {
+				exitWithExtractError(err)
+			}
 		}
 
 		// Initialize storage
@@ -34,36 +69,245 @@ The tool will parse infoboxes and extract quads in the form of:
 		}
 		defer store.Close()
 
-		// Create extractor
-		ext := extractor.NewExtractor()
+		// Failures exit before Commit, so an atomic run stores nothing unless
+		// every URL succeeds
+		var writer extractionWriter = store
+		var tx storage.Tx
+		if storeAtomic {
+			tx, err = store.Begin()
+			if err != nil {
+				log.Fatalf("Failed to store data: %v", err)
+			}
+			defer tx.Rollback()
+			writer = tx
+		}
 
-		// Extract data
-		quads, err := ext.ExtractFromURL(url)
-		if err != nil {
-			log.Fatalf("Failed to extract data: %v", err)
+		var resume *checkpoint
+		if storeResume != "" {
+			resume, err = openCheckpoint(storeResume)
+			if err != nil {
+				log.Fatalf("Failed to resume: %v", err)
+			}
+			defer resume.close()
+		}
+		// The store can't see what the transaction has written, so an atomic
+		// run stores each page once and skips it when listed again
+		written := make(pageSet)
+		storeNext := func(url string) error {
+			if resume != nil && resume.skip(url) {
+				return nil
+			}
+			if storeAtomic && !written.add(url) {
+				fmt.Printf("Already stored in this run, skipping: %s\n", url)
+				return nil
+			}
+			if err := storeURL(ext, store, writer, url); err != nil {
+				return err
+			}
+			if resume != nil {
+				if err := resume.record(url); err != nil {
+					log.Fatalf("Failed to record progress: %v", err)
+				}
+			}
+			return nil
 		}
 
-		// Store data
-		err = store.Store(quads, url, time.Now())
-		if err != nil {
-			log.Fatalf("Failed to store data: %v", err)
+		// A URL from stdin that fails is reported and skipped, unless the run
+		// is atomic, and the run exits non-zero at the end
+		var failures urlFailures
+		if len(args) > 0 {
+			if err := storeNext(args[0]); err != nil {
+				exitWithExtractError(err)
+			}
+		} else {
+			err = eachURL(os.Stdin, func(url string) {
+				err := ext.ValidateURL(url)
+				if err == nil {
+					err = storeNext(url)
+				}
+				if err == nil {
+					return
+				}
+				if storeAtomic {
+					exitWithExtractError(err)
+				}
+				failures.record(url, err)
+			})
+			if err != nil {
+				log.Fatal(err)
+			}
 		}
 
-		// Output results
-		fmt.Printf("Extracted and stored %d quads from %s\n", len(quads), url)
-		
-		// Display first few quads as preview
-		fmt.Println("\nPreview of extracted data:")
-		for i, quad := range quads {
-			if i >= 5 { // Show only first 5
-				break
+		if tx != nil {
+			if err := tx.Commit(); err != nil {
+				log.Fatalf("Failed to store data: %v", err)
 			}
-			fmt.Printf("Quad %d: %s | %s | %s | %s\n", 
-				i+1, quad.Subject, quad.Relationship, quad.Value, quad.Citation)
 		}
+
+		if resume != nil && resume.skipped > 0 {
+			fmt.Printf("Skipped %d URLs already completed according to %s\n", resume.skipped, resume.path)
+		}
+		failures.exit()
 	},
 }
 
+// storeURL extracts one page and saves it with writer, reporting what changed
+// since its previous extraction in store. It returns the extraction error of a
+// page that can't be extracted; storage failures exit the process.
+func storeURL(ext *extractor.Extractor, store storage.Storage, writer extractionWriter, url string) error {
+	// Mobile and desktop URLs of a page share one source
+	url = extractor.DesktopURL(url)
+
+	previous, err := store.GetSource(url)
+	if err != nil {
+		log.Fatalf("Failed to load source metadata: %v", err)
+	}
+
+	// Use the validators from the previous fetch for a conditional request
+	var etag, lastModified string
+	if storeIncremental && previous != nil {
+		etag, lastModified = previous.ETag, previous.LastModified
+	}
+
+	// Extract data
+	result, err := ext.ExtractIfModified(url, etag, lastModified)
+	if err != nil {
+		return err
+	}
+
+	if result.NotModified {
+		fmt.Printf("Source unchanged since last fetch, skipping: %s\n", url)
+		return nil
+	}
+	if result.NoStructuredData {
+		warnNoStructuredData(url)
+	}
+	warnUnresolvedCitations(url, result)
+	warnValidationFailures(url, result)
+	archivePage(result, time.Now())
+	quads := result.Quads
+	contentHash := extractor.ContentHash(quads)
+
+	// Compare against the previous extraction before it is superseded
+	var added, removed []extractor.Quad
+	changed := previous != nil && previous.ContentHash != "" && previous.ContentHash != contentHash
+	if changed {
+		previousQuads, err := store.GetLatestBySourceURL(url)
+		if err != nil {
+			log.Fatalf("Failed to load previous extraction: %v", err)
+		}
+		added, removed = extractor.DiffQuads(previousQuads, quads)
+	}
+
+	// Store data
+	if err := saveExtraction(writer, url, result, time.Now(), storeTag); err != nil {
+		log.Fatalf("Failed to store data: %v", err)
+	}
+
+	// Output results
+	fmt.Printf("Extracted and stored %d quads from %s\n", len(quads), url)
+	if permalink := extractor.RevisionURL(url, result.RevisionID); permalink != "" {
+		fmt.Printf("Revision %d: %s\n", result.RevisionID, permalink)
+	}
+	if !result.PageLastModified.IsZero() {
+		fmt.Printf("Page last edited %s\n", result.PageLastModified.Format(time.RFC3339))
+	}
+	if result.Truncated {
+		fmt.Printf("Extraction stopped at the --max-quads limit of %d\n", maxQuads)
+	}
+
+	// Report changes since the previous extraction
+	switch {
+	case previous == nil || previous.ContentHash == "":
+		// First extraction with a fingerprint, nothing to compare against
+	case !changed:
+		fmt.Println("No change since the previous extraction")
+	default:
+		fmt.Printf("%d facts changed since the previous extraction (%d added, %d removed)\n",
+			len(added)+len(removed), len(added), len(removed))
+		if storeDiff {
+			for _, quad := range added {
+				fmt.Printf("+ %s | %s | %s | %s\n", quad.Subject, quad.Relationship, quad.Value, displayCitation(quad.Citation))
+			}
+			for _, quad := range removed {
+				fmt.Printf("- %s | %s | %s | %s\n", quad.Subject, quad.Relationship, quad.Value, displayCitation(quad.Citation))
+			}
+		}
+	}
+
+	printPreview(quads)
+	return nil
+}
+
+// pageSet is a set of pages keyed by pageKey, so that the mobile and desktop
+// URLs of a page, and the spellings of its title, count as one
+type pageSet map[string]bool
+
+// add adds the page at url to the set, reporting false if it was already there
+func (s pageSet) add(url string) bool {
+	key := pageKey(url)
+	if s[key] {
+		return false
+	}
+	s[key] = true
+	return true
+}
+
+// pageKey identifies the page at rawURL by its desktop URL, with the title's
+// percent-encoding normalized, spaces written as underscores and any fragment
+// dropped
+func pageKey(rawURL string) string {
+	u, err := url.Parse(extractor.DesktopURL(rawURL))
+	if err != nil {
+		return rawURL
+	}
+	u.Path = strings.ReplaceAll(u.Path, " ", "_")
+	u.RawPath = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// extractionWriter is what saveExtraction writes to: a storage.Storage, or a
+// storage.Tx grouping the extractions of a run
+type extractionWriter interface {
+	Store(quads []extractor.Quad, sourceURL string, extractedAt time.Time, tag string) error
+	SaveSource(source storage.SourceRecord) error
+}
+
+// saveExtraction stores the quads of an extraction under an optional tag and
+// records the source's cache validators and content hash for future
+// incremental runs
+func saveExtraction(store extractionWriter, url string, result *extractor.ExtractResult, fetchedAt time.Time, tag string) error {
+	url = extractor.DesktopURL(url)
+	if err := store.Store(result.Quads, url, fetchedAt, tag); err != nil {
+		return err
+	}
+
+	return store.SaveSource(sourceRecord(url, result, fetchedAt))
+}
+
+// sourceRecord builds the fetch metadata recorded for an extraction
+func sourceRecord(url string, result *extractor.ExtractResult, fetchedAt time.Time) storage.SourceRecord {
+	return storage.SourceRecord{
+		SourceURL:        url,
+		ETag:             result.ETag,
+		LastModified:     result.LastModified,
+		ContentHash:      extractor.ContentHash(result.Quads),
+		PageID:           result.PageID,
+		RevisionID:       result.RevisionID,
+		PageLastModified: result.PageLastModified,
+		FetchedAt:        fetchedAt,
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(storeCmd)
+
+	storeCmd.Flags().BoolVar(&storeIncremental, "incremental", false, "Skip extraction if the page is unchanged since the last fetch (uses ETag/Last-Modified)")
+	storeCmd.Flags().BoolVar(&storeDiff, "diff", false, "List the quads added and removed since the previous extraction")
+	storeCmd.Flags().StringVar(&storeResume, "resume", "", "Checkpoint file recording the URLs stored so far; URLs it lists are skipped, so an interrupted run can be restarted")
+	storeCmd.Flags().BoolVar(&storeAtomic, "atomic", false, "Store every URL of the run in one transaction, saving nothing unless all of them succeed")
+	storeCmd.Flags().StringVar(&storeTag, "tag", "", "Label the stored quads, e.g. with a project or run name, to query them later with query --tag")
+	storeCmd.Flags().StringVar(&subjectOverride, "subject", "", "Store the page's quads under this subject instead of the page title")
+	storeCmd.Flags().IntVar(&previewQuads, "preview", defaultPreviewQuads, "Number of stored quads to preview on stderr (0 disables the preview)")
 } 
\ No newline at end of file
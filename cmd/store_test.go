@@ -0,0 +1,24 @@
+package cmd
+
+import "testing"
+
+func TestPageSet(t *testing.T) {
+	pages := make(pageSet)
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://en.wikipedia.org/wiki/Paris", true},
+		{"https://en.wikipedia.org/wiki/Paris", false},
+		// The mobile URL of a page already in the set
+		{"https://en.m.wikipedia.org/wiki/Paris", false},
+		{"https://en.m.wikipedia.org/wiki/Rome", true},
+		{"https://en.wikipedia.org/wiki/Rome", false},
+		{"https://fr.wikipedia.org/wiki/Paris", true},
+	}
+	for _, tt := range tests {
+		if got := pages.add(tt.url); got != tt.want {
+			t.Errorf("add(%s) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package extractor
+
+import "testing"
+
+func TestNativeNameAliases(t *testing.T) {
+	result := parseFixture(t, NewExtractor(), "native_name.html")
+
+	assertQuads(t, result.Quads, []quadFields{
+		{"Taro Example", AliasRelationship, "例 太郎", ""},
+		{"Taro Example", AliasRelationship, "Rei Tarō", ""},
+		{"Taro Example", AliasRelationship, `"The Sampler"[1]`, "https://potters.example.org/taro"},
+		{"Taro Example", "Born", "3 March 1950Exampleton, Examplia", ""},
+		{"Taro Example", "Occupation", "Potter", ""},
+	})
+
+	// Native names keep the language they are written in
+	wantLanguages := []string{"ja", "ja-Latn", "", "", ""}
+	for i, q := range result.Quads {
+		if i < len(wantLanguages) && q.Language != wantLanguages[i] {
+			t.Errorf("%s %q: language = %q, want %q", q.Relationship, q.Value, q.Language, wantLanguages[i])
+		}
+	}
+}
+
+func TestIsAliasLabel(t *testing.T) {
+	tests := map[string]bool{
+		"Native name":      true,
+		"Other names":      true,
+		" also known as ":  true,
+		"Nickname(s)":      true,
+		"Alternative name": true,
+		"Name":             false,
+		"Born":             false,
+	}
+	for label, want := range tests {
+		if got := isAliasLabel(label); got != want {
+			t.Errorf("isAliasLabel(%q) = %v, want %v", label, got, want)
+		}
+	}
+}
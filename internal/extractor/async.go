@@ -0,0 +1,51 @@
+package extractor
+
+import (
+	"context"
+	"net/http"
+)
+
+// PageResult is the outcome of extracting one page with ExtractAll: its
+// result, or the error that prevented extraction
+type PageResult struct {
+	URL    string
+	Result *ExtractResult
+	Err    error
+}
+
+// ExtractAll extracts urls concurrently, issuing every request through
+// colly's async mode and waiting for all of them to finish. Results are
+// returned in the order of urls, whatever order the pages finish in, so
+// aggregating them gives the same output as extracting the pages one by one.
+// A URL that is invalid or fails to extract fails only its own result.
+//
+// How many pages are fetched at once is bounded by the extractor's rate limit
+// (see SetRateLimit); without one every page is requested at the same time.
+func (e *Extractor) ExtractAll(urls []string) []PageResult {
+	results := make([]PageResult, len(urls))
+	fetches := make([]*pageFetch, len(urls))
+	requestErrs := make([]error, len(urls))
+
+	c := e.pageCollector()
+	c.Async = true
+	for i, url := range urls {
+		results[i].URL = url
+		if err := e.ValidateURL(url); err != nil {
+			results[i].Err = err
+			continue
+		}
+		// Each request records its outcome in its own pageFetch, so the
+		// callbacks running concurrently share no state
+		fetches[i] = &pageFetch{ctx: context.Background()}
+		requestErrs[i] = c.Request(http.MethodGet, DesktopURL(url), nil, fetches[i].collyContext(), nil)
+	}
+	c.Wait()
+
+	for i, fetch := range fetches {
+		if fetch == nil {
+			continue
+		}
+		results[i].Result, results[i].Err = e.finishFetch(DesktopURL(urls[i]), fetch, requestErrs[i])
+	}
+	return results
+}
@@ -0,0 +1,89 @@
+package extractor
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestExtractAll fetches pages concurrently, so run it with -race to check
+// that the callbacks of simultaneous requests share no state
+func TestExtractAll(t *testing.T) {
+	server, e := newFixtureServer(t)
+	pages := []string{
+		"native_name.html",
+		"references.html",
+		"spanned_table.html",
+		"succession_box.html",
+		"nationalities.html",
+		"converted_measurements.html",
+		"collapsible_infobox.html",
+		"short_description.html",
+	}
+
+	// Earlier pages answer later, so the requests finish out of order and
+	// overlap
+	files := server.Config.Handler
+	var inFlight, maxInFlight atomic.Int32
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			max := maxInFlight.Load()
+			if n <= max || maxInFlight.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		for i, page := range pages {
+			if strings.HasSuffix(r.URL.Path, "/"+page) {
+				time.Sleep(time.Duration(len(pages)-i) * 10 * time.Millisecond)
+			}
+		}
+		files.ServeHTTP(w, r)
+	})
+
+	var urls []string
+	for _, page := range pages {
+		urls = append(urls, server.URL+"/wiki/"+page)
+	}
+	urls = append(urls, server.URL+"/wiki/missing.html", "not a url")
+
+	results := e.ExtractAll(urls)
+	if len(results) != len(urls) {
+		t.Fatalf("got %d results, want %d", len(results), len(urls))
+	}
+	if maxInFlight.Load() < 2 {
+		t.Errorf("at most %d requests were in flight, want them concurrent", maxInFlight.Load())
+	}
+
+	for i, page := range pages {
+		got := results[i]
+		if got.URL != urls[i] {
+			t.Errorf("result %d is for %s, want %s", i, got.URL, urls[i])
+		}
+		if got.Err != nil {
+			t.Errorf("%s: %v", page, got.Err)
+			continue
+		}
+		// Each result matches extracting the page on its own
+		want, err := e.Extract(urls[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Result.Title != want.Title || !reflect.DeepEqual(got.Result.Quads, want.Quads) {
+			t.Errorf("%s: ExtractAll gave %q with %d quads, Extract gave %q with %d",
+				page, got.Result.Title, len(got.Result.Quads), want.Title, len(want.Quads))
+		}
+	}
+
+	if err := results[len(pages)].Err; !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing page: err = %v, want ErrNotFound", err)
+	}
+	if err := results[len(pages)+1].Err; !errors.Is(err, ErrInvalidURL) {
+		t.Errorf("invalid URL: err = %v, want ErrInvalidURL", err)
+	}
+}
@@ -0,0 +1,63 @@
+package extractor
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// canonicalURL returns the absolute URL from the page's <link rel="canonical">,
+// or an empty string when the page has none
+func canonicalURL(doc *goquery.Selection) string {
+	href, ok := doc.Find(`link[rel="canonical"]`).First().Attr("href")
+	if !ok {
+		return ""
+	}
+	href = strings.TrimSpace(href)
+
+	// Protocol-relative links are served over HTTPS
+	if strings.HasPrefix(href, "//") {
+		href = "https:" + href
+	}
+	u, err := url.Parse(href)
+	if err != nil || !u.IsAbs() {
+		return ""
+	}
+	return u.String()
+}
+
+// setSubject renames the subject of every quad about the page's subject,
+// identified by the page title. SubjectURL still identifies the page.
+func (r *ExtractResult) setSubject(subject string) {
+	for i := range r.Quads {
+		if r.Quads[i].Subject == r.Title {
+			r.Quads[i].Subject = subject
+		}
+	}
+	r.subject = subject
+}
+
+// pageSubject returns the subject of the quads about the page: the page
+// title unless it was replaced by setSubject
+func (r *ExtractResult) pageSubject() string {
+	if r.subject != "" {
+		return r.subject
+	}
+	return r.Title
+}
+
+// setSubjectURL records subjectURL as the identifier of the page and of every
+// quad about the page's subject. Quads about other subjects, such as the rows
+// of a ranked table, are left without one.
+func (r *ExtractResult) setSubjectURL(subjectURL string) {
+	if subjectURL == "" {
+		return
+	}
+	r.SubjectURL = subjectURL
+	for i := range r.Quads {
+		if r.Quads[i].Subject == r.pageSubject() {
+			r.Quads[i].SubjectURL = subjectURL
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package extractor
+
+import (
+	"github.com/PuerkitoBio/goquery"
+)
+
+// collapseToggleSelector matches the "show"/"hide" controls of collapsible
+// sections: .mw-collapsible-toggle from the mw-collapsible module and
+// .collapseButton from older templates such as {{Collapsible list}}
+const collapseToggleSelector = ".mw-collapsible-toggle, .collapseButton"
+
+// stripCollapseToggles removes the toggle controls of collapsible sections so
+// their "show" and "hide" labels don't end up in labels and values. The
+// collapsed content itself stays in the DOM and is extracted like any other.
+func stripCollapseToggles(doc *goquery.Selection) {
+	doc.Find(collapseToggleSelector).Remove()
+}
@@ -0,0 +1,30 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCollapsibleInfobox(t *testing.T) {
+	e := NewExtractor()
+	e.Options.Relationships = nil
+	e.Options.KeepHTML = true
+	result := parseFixture(t, e, "collapsible_infobox.html")
+
+	// Collapsed lists are extracted, without their show/hide toggles
+	assertQuads(t, result.Quads, []quadFields{
+		{"The Examples", "Origin", "Exampleton, Exampleland", ""},
+		{"The Examples", "Genres", "Example rock", ""},
+		{"The Examples", "Discography", "First Example (1990)Second Example (1993)Live in Exampleton (1995)[1]", "https://charts.example.org/live"},
+		{"The Examples", "Past members", "Former lineupAnn ExampleBob Example", ""},
+		{"The Examples", "Website", "examples.example.org", ""},
+	})
+	for _, q := range result.Quads {
+		if strings.Contains(q.RawHTML, "show") {
+			t.Errorf("%s: RawHTML keeps a toggle: %s", q.Relationship, q.RawHTML)
+		}
+	}
+	if q, _ := findQuad(result.Quads, "Discography"); !strings.Contains(q.RawHTML, "mw-collapsible-content") {
+		t.Errorf("Discography RawHTML = %s, want the collapsed content", q.RawHTML)
+	}
+}
@@ -0,0 +1,113 @@
+package extractor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+)
+
+// quadKey returns the identity of a quad used for hashing and comparison
+func quadKey(q Quad) string {
+	return strings.Join([]string{q.Subject, q.Relationship, q.Value, q.Citation}, "\x1f")
+}
+
+// ContentHash returns a stable SHA-256 fingerprint of a set of quads. The hash
+// does not depend on the order of the quads, so it only changes when the facts
+// themselves change.
+func ContentHash(quads []Quad) string {
+	keys := make([]string, len(quads))
+	for i, q := range quads {
+		keys[i] = quadKey(q)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		h.Write([]byte(key))
+		h.Write([]byte{'\x1e'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DiffQuads compares two sets of quads and returns the quads only present in
+// newer (added) and those only present in older (removed). Duplicates are
+// matched one-for-one.
+func DiffQuads(older, newer []Quad) (added, removed []Quad) {
+	counts := make(map[string]int)
+	for _, q := range older {
+		counts[quadKey(q)]++
+	}
+
+	for _, q := range newer {
+		key := quadKey(q)
+		if counts[key] > 0 {
+			counts[key]--
+			continue
+		}
+		added = append(added, q)
+	}
+
+	// Whatever is left unmatched in older was removed
+	for _, q := range older {
+		key := quadKey(q)
+		if counts[key] > 0 {
+			counts[key]--
+			removed = append(removed, q)
+		}
+	}
+
+	return added, removed
+}
+
+// QuadChange is a fact whose value changed between two extractions
+type QuadChange struct {
+	Old Quad `json:"old"`
+	New Quad `json:"new"`
+}
+
+// QuadDiff describes how the quads of a page changed between two extractions
+type QuadDiff struct {
+	Added   []Quad       `json:"added"`
+	Removed []Quad       `json:"removed"`
+	Changed []QuadChange `json:"changed"`
+}
+
+// CompareQuads compares two sets of quads like DiffQuads, but reports an added
+// and a removed quad sharing a subject and relationship as a single change.
+// Pairing only happens when the relationship has exactly one added and one
+// removed quad, since multi-valued relationships have no unambiguous match.
+func CompareQuads(older, newer []Quad) QuadDiff {
+	added, removed := DiffQuads(older, newer)
+
+	factKey := func(q Quad) string {
+		return q.Subject + "\x1f" + q.Relationship
+	}
+	addedCounts := make(map[string]int)
+	for _, q := range added {
+		addedCounts[factKey(q)]++
+	}
+	removedByKey := make(map[string][]Quad)
+	for _, q := range removed {
+		removedByKey[factKey(q)] = append(removedByKey[factKey(q)], q)
+	}
+
+	var diff QuadDiff
+	for _, q := range added {
+		key := factKey(q)
+		if addedCounts[key] == 1 && len(removedByKey[key]) == 1 {
+			diff.Changed = append(diff.Changed, QuadChange{Old: removedByKey[key][0], New: q})
+			continue
+		}
+		diff.Added = append(diff.Added, q)
+	}
+	for _, q := range removed {
+		key := factKey(q)
+		if addedCounts[key] == 1 && len(removedByKey[key]) == 1 {
+			continue
+		}
+		diff.Removed = append(diff.Removed, q)
+	}
+
+	return diff
+}
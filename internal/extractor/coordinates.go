@@ -0,0 +1,148 @@
+package extractor
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ValueTypeCoordinates marks a location normalized to decimal "latitude, longitude"
+const ValueTypeCoordinates = "coordinates"
+
+// CoordinatesRelationship is the relationship of the subject's own location.
+// Locations with a context in the infobox, such as a river's source and
+// mouth, are prefixed with that label, e.g. "Source coordinates".
+const CoordinatesRelationship = "Coordinates"
+
+// parseCoordinates extracts one quad per distinct location marked up by the
+// {{coord}} template in the infoboxes and the title bar. Values are the
+// machine-readable decimal form from the .geo span.
+func (e *Extractor) parseCoordinates(doc *goquery.Selection, subject string, references map[string]string) []Quad {
+	var quads []Quad
+	seen := make(map[string]bool)
+
+	selector := e.infoboxSelector() + ", #coordinates"
+	doc.Find(".geo").FilterFunction(func(i int, geo *goquery.Selection) bool {
+		return geo.Closest(selector).Length() > 0
+	}).Each(func(i int, geo *goquery.Selection) {
+		value, ok := decimalCoordinates(geo.Text())
+		if !ok {
+			return
+		}
+
+		relationship := coordinatesRelationship(geo)
+		key := relationship + "\x1f" + value
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+
+		// Citations follow the coordinates in the same cell
+		cell := geo.Closest("td, .infobox-data, #coordinates")
+		if cell.Length() == 0 {
+			cell = geo
+		}
+
+		quad := Quad{
+			Subject:      subject,
+			Relationship: relationship,
+			Value:        value,
+			Citation:     e.extractCitations(cell, references),
+			ValueType:    ValueTypeCoordinates,
+			RawValue:     displayedCoordinates(geo),
+		}
+		quad.RawHTML = e.rawHTML(cell)
+		quads = append(quads, quad)
+	})
+
+	return quads
+}
+
+// displayedCoordinates returns the coordinates as shown to readers, such as
+// 48°51′N 2°17′E, falling back to the text of the .geo span
+func displayedCoordinates(geo *goquery.Selection) string {
+	shown := geo.Closest(".plainlinks, #coordinates").Find(".geo-default").First()
+	if text := strings.TrimSpace(shown.Text()); text != "" {
+		return text
+	}
+	return strings.TrimSpace(geo.Text())
+}
+
+// decimalCoordinates normalizes the text of a .geo span, "48.8584; 2.2945",
+// to "48.8584, 2.2945"
+func decimalCoordinates(text string) (string, bool) {
+	parts := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ';' || r == ','
+	})
+	if len(parts) != 2 {
+		return "", false
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return "", false
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return "", false
+	}
+
+	return strconv.FormatFloat(lat, 'f', -1, 64) + ", " + strconv.FormatFloat(lon, 'f', -1, 64), true
+}
+
+// coordinatesRelationship names a location from its infobox row. A row
+// labelled "Mouth" gives "Mouth coordinates"; a "• coordinates" sub-row takes
+// the label of the row it belongs to. Coordinates outside a labelled row, or
+// in a plain "Coordinates" row, are the subject's own.
+func coordinatesRelationship(geo *goquery.Selection) string {
+	row := geo.Closest("tr")
+	if row.Length() == 0 {
+		return CoordinatesRelationship
+	}
+
+	label, _ := infoboxRowCells(row)
+	label = cleanInfoboxLabel(label)
+	if label != "" && !isCoordinatesLabel(label) {
+		return label + " coordinates"
+	}
+	if !isSubRowLabel(row) {
+		return CoordinatesRelationship
+	}
+
+	// Walk back to the row this "• coordinates" line belongs to
+	for prev := row.Prev(); prev.Length() > 0; prev = prev.Prev() {
+		if isSubRowLabel(prev) {
+			continue
+		}
+		parent := strings.TrimSpace(prev.Find("th, .infobox-label, .infobox-header").First().Text())
+		if parent = cleanInfoboxLabel(parent); parent != "" {
+			return parent + " coordinates"
+		}
+		break
+	}
+	return CoordinatesRelationship
+}
+
+// isSubRowLabel reports whether an infobox row's label is a bulleted sub-item
+// such as "• location" that belongs to the row above it
+func isSubRowLabel(row *goquery.Selection) bool {
+	label := strings.TrimSpace(strings.ReplaceAll(row.Find("th, .infobox-label").First().Text(), " ", " "))
+	return strings.HasPrefix(label, "•") || strings.HasPrefix(label, "-")
+}
+
+// cleanInfoboxLabel strips sub-row bullets and non-breaking spaces from a label
+func cleanInfoboxLabel(label string) string {
+	label = strings.ReplaceAll(label, " ", " ")
+	label = strings.TrimLeft(strings.TrimSpace(label), "•- ")
+	return strings.TrimSpace(label)
+}
+
+// isCoordinatesLabel reports whether a label names coordinates without context
+func isCoordinatesLabel(label string) bool {
+	switch strings.ToLower(label) {
+	case "coordinates", "coords", "location", "coordinates and location":
+		return true
+	}
+	return false
+}
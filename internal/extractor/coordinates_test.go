@@ -0,0 +1,68 @@
+package extractor
+
+import "testing"
+
+func TestParseCoordinatesRiver(t *testing.T) {
+	e := NewExtractor()
+	result := parseFixture(t, e, "river_coordinates.html")
+
+	var coordinates []Quad
+	for _, q := range result.Quads {
+		if q.ValueType == ValueTypeCoordinates {
+			coordinates = append(coordinates, q)
+		}
+	}
+	// The title bar location is the river's own; the "• coordinates" sub-rows
+	// are named after the Source and Mouth rows they belong to, and the mouth
+	// is kept although it repeats the title bar location
+	assertQuads(t, coordinates, []quadFields{
+		{"Examplia River", CoordinatesRelationship, "45.5, 10.25", ""},
+		{"Examplia River", "Source coordinates", "46.1, 10.05", "https://survey.example.org/rivers/examplia"},
+		{"Examplia River", "Mouth coordinates", "45.5, 10.25", ""},
+	})
+	if len(coordinates) > 1 && coordinates[1].RawValue != "46°06′N 10°03′E" {
+		t.Errorf("raw value = %q, want the displayed coordinates", coordinates[1].RawValue)
+	}
+}
+
+func TestDecimalCoordinates(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+		ok   bool
+	}{
+		{"48.8584; 2.2945", "48.8584, 2.2945", true},
+		{" -33.8568 ; 151.2153 ", "-33.8568, 151.2153", true},
+		{"45.50; 10.250", "45.5, 10.25", true},
+		{"91; 10", "", false},
+		{"45; 181", "", false},
+		{"45.5", "", false},
+		{"north; east", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := decimalCoordinates(tt.text)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("decimalCoordinates(%q) = %q, %v, want %q, %v", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// The mobile rendering of a page yields the same quads as the desktop one
+func TestParseMobilePage(t *testing.T) {
+	e := NewExtractor()
+	desktop := parseFixture(t, e, "river_coordinates.html")
+	mobile := parseFixture(t, e, "river_coordinates_mobile.html")
+
+	if mobile.Title != desktop.Title {
+		t.Errorf("title = %q, want %q", mobile.Title, desktop.Title)
+	}
+	if mobile.SubjectURL != desktop.SubjectURL {
+		t.Errorf("subject URL = %q, want %q", mobile.SubjectURL, desktop.SubjectURL)
+	}
+	assertQuads(t, mobile.Quads, fieldsOf(desktop.Quads))
+	for i := range mobile.Quads {
+		if i < len(desktop.Quads) && mobile.Quads[i].RawValue != desktop.Quads[i].RawValue {
+			t.Errorf("%s: raw value = %q, want %q", mobile.Quads[i].Relationship, mobile.Quads[i].RawValue, desktop.Quads[i].RawValue)
+		}
+	}
+}
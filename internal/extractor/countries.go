@@ -0,0 +1,388 @@
+package extractor
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ValueTypeCountry marks a value naming one or more countries
+const ValueTypeCountry = "country"
+
+// country is an ISO 3166-1 country with the names and demonyms infoboxes
+// use for it
+type country struct {
+	alpha2 string
+	alpha3 string
+	names  []string
+}
+
+// countries lists the ISO 3166-1 countries. The constituent countries of the
+// United Kingdom resolve to GB, as ISO 3166-1 gives them no codes of their own.
+var countries = []country{
+	{"AF", "AFG", []string{"Afghanistan", "Afghan"}},
+	{"AX", "ALA", []string{"Åland Islands", "Åland"}},
+	{"AL", "ALB", []string{"Albania", "Albanian"}},
+	{"DZ", "DZA", []string{"Algeria", "Algerian"}},
+	{"AS", "ASM", []string{"American Samoa"}},
+	{"AD", "AND", []string{"Andorra", "Andorran"}},
+	{"AO", "AGO", []string{"Angola", "Angolan"}},
+	{"AI", "AIA", []string{"Anguilla"}},
+	{"AQ", "ATA", []string{"Antarctica"}},
+	{"AG", "ATG", []string{"Antigua and Barbuda"}},
+	{"AR", "ARG", []string{"Argentina", "Argentine", "Argentinian"}},
+	{"AM", "ARM", []string{"Armenia", "Armenian"}},
+	{"AW", "ABW", []string{"Aruba"}},
+	{"AU", "AUS", []string{"Australia", "Australian"}},
+	{"AT", "AUT", []string{"Austria", "Austrian"}},
+	{"AZ", "AZE", []string{"Azerbaijan", "Azerbaijani"}},
+	{"BS", "BHS", []string{"Bahamas", "The Bahamas", "Bahamian"}},
+	{"BH", "BHR", []string{"Bahrain", "Bahraini"}},
+	{"BD", "BGD", []string{"Bangladesh", "Bangladeshi"}},
+	{"BB", "BRB", []string{"Barbados", "Barbadian"}},
+	{"BY", "BLR", []string{"Belarus", "Belarusian"}},
+	{"BE", "BEL", []string{"Belgium", "Belgian"}},
+	{"BZ", "BLZ", []string{"Belize", "Belizean"}},
+	{"BJ", "BEN", []string{"Benin", "Beninese"}},
+	{"BM", "BMU", []string{"Bermuda"}},
+	{"BT", "BTN", []string{"Bhutan", "Bhutanese"}},
+	{"BO", "BOL", []string{"Bolivia", "Bolivian"}},
+	{"BQ", "BES", []string{"Caribbean Netherlands", "Bonaire, Sint Eustatius and Saba"}},
+	{"BA", "BIH", []string{"Bosnia and Herzegovina", "Bosnian"}},
+	{"BW", "BWA", []string{"Botswana"}},
+	{"BV", "BVT", []string{"Bouvet Island"}},
+	{"BR", "BRA", []string{"Brazil", "Brazilian"}},
+	{"IO", "IOT", []string{"British Indian Ocean Territory"}},
+	{"BN", "BRN", []string{"Brunei", "Brunei Darussalam"}},
+	{"BG", "BGR", []string{"Bulgaria", "Bulgarian"}},
+	{"BF", "BFA", []string{"Burkina Faso", "Burkinabé"}},
+	{"BI", "BDI", []string{"Burundi", "Burundian"}},
+	{"CV", "CPV", []string{"Cape Verde", "Cabo Verde", "Cape Verdean"}},
+	{"KH", "KHM", []string{"Cambodia", "Cambodian"}},
+	{"CM", "CMR", []string{"Cameroon", "Cameroonian"}},
+	{"CA", "CAN", []string{"Canada", "Canadian"}},
+	{"KY", "CYM", []string{"Cayman Islands"}},
+	{"CF", "CAF", []string{"Central African Republic"}},
+	{"TD", "TCD", []string{"Chad", "Chadian"}},
+	{"CL", "CHL", []string{"Chile", "Chilean"}},
+	{"CN", "CHN", []string{"China", "People's Republic of China", "PRC", "Chinese"}},
+	{"CX", "CXR", []string{"Christmas Island"}},
+	{"CC", "CCK", []string{"Cocos (Keeling) Islands", "Cocos Islands"}},
+	{"CO", "COL", []string{"Colombia", "Colombian"}},
+	{"KM", "COM", []string{"Comoros", "Comorian"}},
+	{"CG", "COG", []string{"Republic of the Congo", "Congo"}},
+	{"CD", "COD", []string{"Democratic Republic of the Congo", "DR Congo", "DRC"}},
+	{"CK", "COK", []string{"Cook Islands"}},
+	{"CR", "CRI", []string{"Costa Rica", "Costa Rican"}},
+	{"CI", "CIV", []string{"Ivory Coast", "Côte d'Ivoire", "Ivorian"}},
+	{"HR", "HRV", []string{"Croatia", "Croatian"}},
+	{"CU", "CUB", []string{"Cuba", "Cuban"}},
+	{"CW", "CUW", []string{"Curaçao"}},
+	{"CY", "CYP", []string{"Cyprus", "Cypriot"}},
+	{"CZ", "CZE", []string{"Czech Republic", "Czechia", "Czech"}},
+	{"DK", "DNK", []string{"Denmark", "Danish"}},
+	{"DJ", "DJI", []string{"Djibouti", "Djiboutian"}},
+	{"DM", "DMA", []string{"Dominica"}},
+	{"DO", "DOM", []string{"Dominican Republic", "Dominican"}},
+	{"EC", "ECU", []string{"Ecuador", "Ecuadorian"}},
+	{"EG", "EGY", []string{"Egypt", "Egyptian"}},
+	{"SV", "SLV", []string{"El Salvador", "Salvadoran"}},
+	{"GQ", "GNQ", []string{"Equatorial Guinea"}},
+	{"ER", "ERI", []string{"Eritrea", "Eritrean"}},
+	{"EE", "EST", []string{"Estonia", "Estonian"}},
+	{"SZ", "SWZ", []string{"Eswatini", "Swaziland"}},
+	{"ET", "ETH", []string{"Ethiopia", "Ethiopian"}},
+	{"FK", "FLK", []string{"Falkland Islands"}},
+	{"FO", "FRO", []string{"Faroe Islands", "Faroese"}},
+	{"FJ", "FJI", []string{"Fiji", "Fijian"}},
+	{"FI", "FIN", []string{"Finland", "Finnish"}},
+	{"FR", "FRA", []string{"France", "French"}},
+	{"GF", "GUF", []string{"French Guiana"}},
+	{"PF", "PYF", []string{"French Polynesia"}},
+	{"TF", "ATF", []string{"French Southern and Antarctic Lands", "French Southern Territories"}},
+	{"GA", "GAB", []string{"Gabon", "Gabonese"}},
+	{"GM", "GMB", []string{"The Gambia", "Gambia", "Gambian"}},
+	{"GE", "GEO", []string{"Georgia (country)", "Georgia", "Georgian"}},
+	{"DE", "DEU", []string{"Germany", "German"}},
+	{"GH", "GHA", []string{"Ghana", "Ghanaian"}},
+	{"GI", "GIB", []string{"Gibraltar"}},
+	{"GR", "GRC", []string{"Greece", "Greek"}},
+	{"GL", "GRL", []string{"Greenland"}},
+	{"GD", "GRD", []string{"Grenada"}},
+	{"GP", "GLP", []string{"Guadeloupe"}},
+	{"GU", "GUM", []string{"Guam"}},
+	{"GT", "GTM", []string{"Guatemala", "Guatemalan"}},
+	{"GG", "GGY", []string{"Guernsey"}},
+	{"GN", "GIN", []string{"Guinea", "Guinean"}},
+	{"GW", "GNB", []string{"Guinea-Bissau"}},
+	{"GY", "GUY", []string{"Guyana", "Guyanese"}},
+	{"HT", "HTI", []string{"Haiti", "Haitian"}},
+	{"HM", "HMD", []string{"Heard Island and McDonald Islands"}},
+	{"VA", "VAT", []string{"Vatican City", "Holy See"}},
+	{"HN", "HND", []string{"Honduras", "Honduran"}},
+	{"HK", "HKG", []string{"Hong Kong"}},
+	{"HU", "HUN", []string{"Hungary", "Hungarian"}},
+	{"IS", "ISL", []string{"Iceland", "Icelandic"}},
+	{"IN", "IND", []string{"India", "Indian"}},
+	{"ID", "IDN", []string{"Indonesia", "Indonesian"}},
+	{"IR", "IRN", []string{"Iran", "Iranian"}},
+	{"IQ", "IRQ", []string{"Iraq", "Iraqi"}},
+	{"IE", "IRL", []string{"Ireland", "Republic of Ireland", "Irish"}},
+	{"IM", "IMN", []string{"Isle of Man"}},
+	{"IL", "ISR", []string{"Israel", "Israeli"}},
+	{"IT", "ITA", []string{"Italy", "Italian"}},
+	{"JM", "JAM", []string{"Jamaica", "Jamaican"}},
+	{"JP", "JPN", []string{"Japan", "Japanese"}},
+	{"JE", "JEY", []string{"Jersey"}},
+	{"JO", "JOR", []string{"Jordan", "Jordanian"}},
+	{"KZ", "KAZ", []string{"Kazakhstan", "Kazakh", "Kazakhstani"}},
+	{"KE", "KEN", []string{"Kenya", "Kenyan"}},
+	{"KI", "KIR", []string{"Kiribati"}},
+	{"KP", "PRK", []string{"North Korea", "North Korean"}},
+	{"KR", "KOR", []string{"South Korea", "Republic of Korea", "South Korean"}},
+	{"KW", "KWT", []string{"Kuwait", "Kuwaiti"}},
+	{"KG", "KGZ", []string{"Kyrgyzstan", "Kyrgyz"}},
+	{"LA", "LAO", []string{"Laos", "Lao", "Laotian"}},
+	{"LV", "LVA", []string{"Latvia", "Latvian"}},
+	{"LB", "LBN", []string{"Lebanon", "Lebanese"}},
+	{"LS", "LSO", []string{"Lesotho"}},
+	{"LR", "LBR", []string{"Liberia", "Liberian"}},
+	{"LY", "LBY", []string{"Libya", "Libyan"}},
+	{"LI", "LIE", []string{"Liechtenstein"}},
+	{"LT", "LTU", []string{"Lithuania", "Lithuanian"}},
+	{"LU", "LUX", []string{"Luxembourg", "Luxembourgish"}},
+	{"MO", "MAC", []string{"Macau", "Macao"}},
+	{"MG", "MDG", []string{"Madagascar", "Malagasy"}},
+	{"MW", "MWI", []string{"Malawi", "Malawian"}},
+	{"MY", "MYS", []string{"Malaysia", "Malaysian"}},
+	{"MV", "MDV", []string{"Maldives", "Maldivian"}},
+	{"ML", "MLI", []string{"Mali", "Malian"}},
+	{"MT", "MLT", []string{"Malta", "Maltese"}},
+	{"MH", "MHL", []string{"Marshall Islands"}},
+	{"MQ", "MTQ", []string{"Martinique"}},
+	{"MR", "MRT", []string{"Mauritania", "Mauritanian"}},
+	{"MU", "MUS", []string{"Mauritius", "Mauritian"}},
+	{"YT", "MYT", []string{"Mayotte"}},
+	{"MX", "MEX", []string{"Mexico", "Mexican"}},
+	{"FM", "FSM", []string{"Federated States of Micronesia", "Micronesia"}},
+	{"MD", "MDA", []string{"Moldova", "Moldovan"}},
+	{"MC", "MCO", []string{"Monaco", "Monégasque"}},
+	{"MN", "MNG", []string{"Mongolia", "Mongolian"}},
+	{"ME", "MNE", []string{"Montenegro", "Montenegrin"}},
+	{"MS", "MSR", []string{"Montserrat"}},
+	{"MA", "MAR", []string{"Morocco", "Moroccan"}},
+	{"MZ", "MOZ", []string{"Mozambique", "Mozambican"}},
+	{"MM", "MMR", []string{"Myanmar", "Burma", "Burmese"}},
+	{"NA", "NAM", []string{"Namibia", "Namibian"}},
+	{"NR", "NRU", []string{"Nauru"}},
+	{"NP", "NPL", []string{"Nepal", "Nepali", "Nepalese"}},
+	{"NL", "NLD", []string{"Netherlands", "The Netherlands", "Dutch"}},
+	{"NC", "NCL", []string{"New Caledonia"}},
+	{"NZ", "NZL", []string{"New Zealand", "New Zealander"}},
+	{"NI", "NIC", []string{"Nicaragua", "Nicaraguan"}},
+	{"NE", "NER", []string{"Niger"}},
+	{"NG", "NGA", []string{"Nigeria", "Nigerian"}},
+	{"NU", "NIU", []string{"Niue"}},
+	{"NF", "NFK", []string{"Norfolk Island"}},
+	{"MK", "MKD", []string{"North Macedonia", "Macedonia", "Macedonian"}},
+	{"MP", "MNP", []string{"Northern Mariana Islands"}},
+	{"NO", "NOR", []string{"Norway", "Norwegian"}},
+	{"OM", "OMN", []string{"Oman", "Omani"}},
+	{"PK", "PAK", []string{"Pakistan", "Pakistani"}},
+	{"PW", "PLW", []string{"Palau"}},
+	{"PS", "PSE", []string{"Palestine", "State of Palestine", "Palestinian"}},
+	{"PA", "PAN", []string{"Panama", "Panamanian"}},
+	{"PG", "PNG", []string{"Papua New Guinea"}},
+	{"PY", "PRY", []string{"Paraguay", "Paraguayan"}},
+	{"PE", "PER", []string{"Peru", "Peruvian"}},
+	{"PH", "PHL", []string{"Philippines", "The Philippines", "Filipino"}},
+	{"PN", "PCN", []string{"Pitcairn Islands"}},
+	{"PL", "POL", []string{"Poland", "Polish"}},
+	{"PT", "PRT", []string{"Portugal", "Portuguese"}},
+	{"PR", "PRI", []string{"Puerto Rico", "Puerto Rican"}},
+	{"QA", "QAT", []string{"Qatar", "Qatari"}},
+	{"RE", "REU", []string{"Réunion"}},
+	{"RO", "ROU", []string{"Romania", "Romanian"}},
+	{"RU", "RUS", []string{"Russia", "Russian Federation", "Russian"}},
+	{"RW", "RWA", []string{"Rwanda", "Rwandan"}},
+	{"BL", "BLM", []string{"Saint Barthélemy"}},
+	{"SH", "SHN", []string{"Saint Helena, Ascension and Tristan da Cunha", "Saint Helena"}},
+	{"KN", "KNA", []string{"Saint Kitts and Nevis"}},
+	{"LC", "LCA", []string{"Saint Lucia"}},
+	{"MF", "MAF", []string{"Saint Martin", "Collectivity of Saint Martin"}},
+	{"PM", "SPM", []string{"Saint Pierre and Miquelon"}},
+	{"VC", "VCT", []string{"Saint Vincent and the Grenadines"}},
+	{"WS", "WSM", []string{"Samoa", "Samoan"}},
+	{"SM", "SMR", []string{"San Marino"}},
+	{"ST", "STP", []string{"São Tomé and Príncipe"}},
+	{"SA", "SAU", []string{"Saudi Arabia", "Saudi", "Saudi Arabian"}},
+	{"SN", "SEN", []string{"Senegal", "Senegalese"}},
+	{"RS", "SRB", []string{"Serbia", "Serbian"}},
+	{"SC", "SYC", []string{"Seychelles"}},
+	{"SL", "SLE", []string{"Sierra Leone"}},
+	{"SG", "SGP", []string{"Singapore", "Singaporean"}},
+	{"SX", "SXM", []string{"Sint Maarten"}},
+	{"SK", "SVK", []string{"Slovakia", "Slovak"}},
+	{"SI", "SVN", []string{"Slovenia", "Slovenian", "Slovene"}},
+	{"SB", "SLB", []string{"Solomon Islands"}},
+	{"SO", "SOM", []string{"Somalia", "Somali"}},
+	{"ZA", "ZAF", []string{"South Africa", "South African"}},
+	{"GS", "SGS", []string{"South Georgia and the South Sandwich Islands"}},
+	{"SS", "SSD", []string{"South Sudan", "South Sudanese"}},
+	{"ES", "ESP", []string{"Spain", "Spanish"}},
+	{"LK", "LKA", []string{"Sri Lanka", "Sri Lankan"}},
+	{"SD", "SDN", []string{"Sudan", "Sudanese"}},
+	{"SR", "SUR", []string{"Suriname", "Surinamese"}},
+	{"SJ", "SJM", []string{"Svalbard and Jan Mayen"}},
+	{"SE", "SWE", []string{"Sweden", "Swedish"}},
+	{"CH", "CHE", []string{"Switzerland", "Swiss"}},
+	{"SY", "SYR", []string{"Syria", "Syrian"}},
+	{"TW", "TWN", []string{"Taiwan", "Republic of China", "Taiwanese"}},
+	{"TJ", "TJK", []string{"Tajikistan", "Tajik"}},
+	{"TZ", "TZA", []string{"Tanzania", "Tanzanian"}},
+	{"TH", "THA", []string{"Thailand", "Thai"}},
+	{"TL", "TLS", []string{"East Timor", "Timor-Leste"}},
+	{"TG", "TGO", []string{"Togo", "Togolese"}},
+	{"TK", "TKL", []string{"Tokelau"}},
+	{"TO", "TON", []string{"Tonga", "Tongan"}},
+	{"TT", "TTO", []string{"Trinidad and Tobago"}},
+	{"TN", "TUN", []string{"Tunisia", "Tunisian"}},
+	{"TR", "TUR", []string{"Turkey", "Türkiye", "Turkish"}},
+	{"TM", "TKM", []string{"Turkmenistan", "Turkmen"}},
+	{"TC", "TCA", []string{"Turks and Caicos Islands"}},
+	{"TV", "TUV", []string{"Tuvalu"}},
+	{"UG", "UGA", []string{"Uganda", "Ugandan"}},
+	{"UA", "UKR", []string{"Ukraine", "Ukrainian"}},
+	{"AE", "ARE", []string{"United Arab Emirates", "UAE", "Emirati"}},
+	{"GB", "GBR", []string{"United Kingdom", "UK", "Great Britain", "British", "England", "English", "Scotland", "Scottish", "Wales", "Welsh", "Northern Ireland"}},
+	{"US", "USA", []string{"United States", "United States of America", "USA", "U.S.", "US", "American"}},
+	{"UM", "UMI", []string{"United States Minor Outlying Islands"}},
+	{"UY", "URY", []string{"Uruguay", "Uruguayan"}},
+	{"UZ", "UZB", []string{"Uzbekistan", "Uzbek"}},
+	{"VU", "VUT", []string{"Vanuatu"}},
+	{"VE", "VEN", []string{"Venezuela", "Venezuelan"}},
+	{"VN", "VNM", []string{"Vietnam", "Viet Nam", "Vietnamese"}},
+	{"VG", "VGB", []string{"British Virgin Islands"}},
+	{"VI", "VIR", []string{"United States Virgin Islands", "U.S. Virgin Islands"}},
+	{"WF", "WLF", []string{"Wallis and Futuna"}},
+	{"EH", "ESH", []string{"Western Sahara"}},
+	{"YE", "YEM", []string{"Yemen", "Yemeni"}},
+	{"ZM", "ZMB", []string{"Zambia", "Zambian"}},
+	{"ZW", "ZWE", []string{"Zimbabwe", "Zimbabwean"}},
+}
+
+// countryIndex maps the normalized names and demonyms in countries to their
+// country
+var countryIndex = func() map[string]*country {
+	index := make(map[string]*country)
+	for i := range countries {
+		for _, name := range countries[i].names {
+			index[normalizeLabel(name)] = &countries[i]
+		}
+	}
+	return index
+}()
+
+// flagFile matches the country named by a flag image, e.g. "the_United_States"
+// in ".../23px-Flag_of_the_United_States.svg.png"
+var flagFile = regexp.MustCompile(`Flag_of_([^/]+?)(?:_\([^/]*\))?\.svg`)
+
+// countryListSeparator splits a value listing several countries. Parts that
+// aren't a country name are split further on "and", which is left alone in
+// names like "Trinidad and Tobago".
+var (
+	countryListSeparator = regexp.MustCompile(`\s*[,;/\n]\s*`)
+	countryAndSeparator  = regexp.MustCompile(`\s+and\s+`)
+)
+
+// lookupCountry returns the country with the given name or demonym
+func lookupCountry(name string) (*country, bool) {
+	name = strings.TrimPrefix(normalizeLabel(name), "the ")
+	c, ok := countryIndex[name]
+	if !ok {
+		c, ok = countryIndex["the "+name]
+	}
+	return c, ok
+}
+
+// cellCountries returns the countries named in an infobox value cell: those
+// shown with a flag icon, or those making up the whole value, such as
+// "American" or "France, Belgium". whole is set in the latter case.
+func cellCountries(cell *goquery.Selection, value string) (found []*country, whole bool) {
+	seen := make(map[*country]bool)
+	add := func(c *country) {
+		if !seen[c] {
+			seen[c] = true
+			found = append(found, c)
+		}
+	}
+
+	cell.Find(".flagicon").Each(func(i int, flag *goquery.Selection) {
+		if c, ok := flagCountry(flag); ok {
+			add(c)
+		}
+	})
+
+	var listed []*country
+	for _, part := range countryListSeparator.Split(footnoteMarker.ReplaceAllString(value, ""), -1) {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		if c, ok := lookupCountry(part); ok {
+			listed = append(listed, c)
+			continue
+		}
+		for _, name := range countryAndSeparator.Split(part, -1) {
+			c, ok := lookupCountry(name)
+			if !ok {
+				return found, false
+			}
+			listed = append(listed, c)
+		}
+	}
+	for _, c := range listed {
+		add(c)
+	}
+	return found, len(listed) > 0
+}
+
+// flagCountry returns the country of a {{flagicon}}, read from the flag
+// image's file name or, failing that, the link following the icon
+func flagCountry(flag *goquery.Selection) (*country, bool) {
+	if src, ok := flag.Find("img").Attr("src"); ok {
+		if match := flagFile.FindStringSubmatch(src); match != nil {
+			name, err := url.PathUnescape(match[1])
+			if err == nil {
+				if c, ok := lookupCountry(strings.ReplaceAll(name, "_", " ")); ok {
+					return c, true
+				}
+			}
+		}
+	}
+	if title, ok := flag.NextAllFiltered("a").First().Attr("title"); ok {
+		return lookupCountry(title)
+	}
+	return nil, false
+}
+
+// setCountryCodes sets CountryAlpha2 and CountryAlpha3 on quad from the
+// countries named in its value cell, listing several separated by spaces
+func setCountryCodes(quad *Quad, cell *goquery.Selection) {
+	found, whole := cellCountries(cell, quad.Value)
+	if len(found) == 0 {
+		return
+	}
+	alpha2 := make([]string, len(found))
+	alpha3 := make([]string, len(found))
+	for i, c := range found {
+		alpha2[i] = c.alpha2
+		alpha3[i] = c.alpha3
+	}
+	quad.CountryAlpha2 = strings.Join(alpha2, " ")
+	quad.CountryAlpha3 = strings.Join(alpha3, " ")
+	if whole && quad.ValueType == "" {
+		quad.ValueType = ValueTypeCountry
+	}
+}
@@ -0,0 +1,71 @@
+package extractor
+
+import "testing"
+
+func TestCountryCodesNationalities(t *testing.T) {
+	type codes struct {
+		Relationship string
+		Alpha2       string
+		Alpha3       string
+		ValueType    string
+	}
+	want := []codes{
+		// Flag icons name the country without making it the whole value
+		{"Born", "FR", "FRA", ""},
+		{"Nationality", "US", "USA", ValueTypeCountry},
+		{"Citizenship", "CA GB", "CAN GBR", ValueTypeCountry},
+		{"Represented", "TT US", "TTO USA", ""},
+		{"Residence", "TT", "TTO", ValueTypeCountry},
+		{"Spouse", "", "", ""},
+		{"Alma mater", "", "", ""},
+	}
+
+	e := NewExtractor()
+	e.Options.CountryCodes = true
+	result := parseFixture(t, e, "nationalities.html")
+	if len(result.Quads) != len(want) {
+		t.Fatalf("got %d quads, want %d", len(result.Quads), len(want))
+	}
+	for i, q := range result.Quads {
+		got := codes{q.Relationship, q.CountryAlpha2, q.CountryAlpha3, q.ValueType}
+		if got != want[i] {
+			t.Errorf("quad %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+	if q, _ := findQuad(result.Quads, "Nationality"); q.Citation != "https://example.org/profile" {
+		t.Errorf("Nationality citation = %q, want the profile link", q.Citation)
+	}
+
+	e.Options.CountryCodes = false
+	for _, q := range parseFixture(t, e, "nationalities.html").Quads {
+		if q.CountryAlpha2 != "" || q.CountryAlpha3 != "" || q.ValueType == ValueTypeCountry {
+			t.Errorf("%s without CountryCodes = %+v", q.Relationship, q)
+		}
+	}
+}
+
+func TestLookupCountry(t *testing.T) {
+	tests := []struct {
+		name   string
+		alpha2 string
+	}{
+		{"France", "FR"},
+		{"French", "FR"},
+		{"the Netherlands", "NL"},
+		{"Netherlands", "NL"},
+		{"Trinidad and Tobago", "TT"},
+		{"Scottish", "GB"},
+		{"Åland", "AX"},
+		{"Exampleland", ""},
+		{"Paris", ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if c, ok := lookupCountry(tt.name); ok {
+			got = c.alpha2
+		}
+		if got != tt.alpha2 {
+			t.Errorf("lookupCountry(%q) = %q, want %q", tt.name, got, tt.alpha2)
+		}
+	}
+}
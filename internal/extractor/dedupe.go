@@ -0,0 +1,118 @@
+package extractor
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+const (
+	// DefaultDedupeCapacity is how many distinct quads a Deduplicator is
+	// sized for when no capacity is given
+	DefaultDedupeCapacity = 1000000
+
+	// DefaultDedupeFalsePositiveRate is the share of new quads a Deduplicator
+	// wrongly suppresses, once it holds its capacity, when no rate is given
+	DefaultDedupeFalsePositiveRate = 0.001
+)
+
+// bloomFilter is a fixed-size set of strings that answers membership with no
+// false negatives and a tunable rate of false positives
+type bloomFilter struct {
+	bits   []uint64
+	size   uint64
+	hashes int
+}
+
+// newBloomFilter sizes a bloom filter for capacity strings at the given false
+// positive rate, using the optimal number of bits and hash functions
+func newBloomFilter(capacity int, falsePositiveRate float64) *bloomFilter {
+	n := float64(capacity)
+	size := uint64(math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	size = max(size, 64)
+	hashes := int(math.Round(float64(size) / n * math.Ln2))
+	return &bloomFilter{
+		bits:   make([]uint64, (size+63)/64),
+		size:   size,
+		hashes: max(hashes, 1),
+	}
+}
+
+// locations returns the bit positions of key, derived from two hashes by
+// double hashing
+func (f *bloomFilter) locations(key string) func(i int) uint64 {
+	h1, h2 := fnv.New64a(), fnv.New64()
+	h1.Write([]byte(key))
+	h2.Write([]byte(key))
+	a, b := h1.Sum64(), h2.Sum64()|1
+	return func(i int) uint64 {
+		return (a + uint64(i)*b) % f.size
+	}
+}
+
+// addIfAbsent adds key to the set, reporting whether it may already have been
+// there
+func (f *bloomFilter) addIfAbsent(key string) bool {
+	location := f.locations(key)
+	present := true
+	for i := 0; i < f.hashes; i++ {
+		bit := location(i)
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			present = false
+			f.bits[bit/64] |= 1 << (bit % 64)
+		}
+	}
+	return present
+}
+
+// Deduplicator suppresses quads already seen in earlier pages of a batch or
+// crawl. Within a page duplicates are found exactly, but across pages the
+// quads seen so far are remembered in a bloom filter, whose memory is fixed
+// by its capacity rather than growing with every quad. The price is that a
+// small share of quads never seen before, set by the false positive rate, is
+// suppressed as well, and the rate rises once more quads than the capacity
+// have been seen. A nil Deduplicator keeps every quad. It is not safe for
+// concurrent use.
+type Deduplicator struct {
+	seen *bloomFilter
+	// Suppressed counts the quads dropped as seen in an earlier page
+	Suppressed int
+}
+
+// NewDeduplicator returns a Deduplicator sized for capacity distinct quads at
+// the given false positive rate. Zero values select DefaultDedupeCapacity
+// and DefaultDedupeFalsePositiveRate.
+func NewDeduplicator(capacity int, falsePositiveRate float64) (*Deduplicator, error) {
+	if capacity == 0 {
+		capacity = DefaultDedupeCapacity
+	}
+	if falsePositiveRate == 0 {
+		falsePositiveRate = DefaultDedupeFalsePositiveRate
+	}
+	if capacity < 0 {
+		return nil, fmt.Errorf("invalid dedupe capacity: must be positive, got %d", capacity)
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return nil, fmt.Errorf("invalid dedupe false positive rate: must be between 0 and 1, got %g", falsePositiveRate)
+	}
+	return &Deduplicator{seen: newBloomFilter(capacity, falsePositiveRate)}, nil
+}
+
+// Filter returns the quads of one page without exact duplicates and without
+// the quads (probably) seen in the pages filtered before it, then remembers
+// them. Quads are compared by subject, relationship and value. Order is
+// preserved and the input slice is not modified.
+func (d *Deduplicator) Filter(quads []Quad) []Quad {
+	if d == nil {
+		return quads
+	}
+	var kept []Quad
+	for _, q := range DedupeQuads(quads) {
+		if d.seen.addIfAbsent(q.Subject + "\x00" + q.Relationship + "\x00" + q.Value) {
+			d.Suppressed++
+			continue
+		}
+		kept = append(kept, q)
+	}
+	return kept
+}
@@ -0,0 +1,30 @@
+package extractor
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DescriptionRelationship is the relationship of the quad giving the page's
+// short description when ExtractorOptions.ShortDescription is set
+const DescriptionRelationship = "description"
+
+// parseShortDescription returns the page's short description, the one-line
+// gloss set with {{Short description}} that Wikidata and search results
+// show. MediaWiki renders it as a .shortdescription element that is hidden
+// from readers (display:none, noexcerpt), so it is read from the markup
+// rather than skipped like other hidden text. "none", which editors use to
+// suppress a description, counts as no description.
+func parseShortDescription(doc *goquery.Selection) string {
+	var description string
+	doc.Find(".shortdescription").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		text := strings.Join(strings.Fields(s.Text()), " ")
+		if text == "" || strings.EqualFold(text, "none") {
+			return true
+		}
+		description = text
+		return false
+	})
+	return description
+}
@@ -0,0 +1,56 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestShortDescription(t *testing.T) {
+	const description = "Fictional mathematician (1815–1852)"
+	e := NewExtractor()
+	result := parseFixture(t, e, "short_description.html")
+	if result.ShortDescription != description {
+		t.Errorf("ShortDescription = %q, want %q", result.ShortDescription, description)
+	}
+	assertQuads(t, result.Quads, []quadFields{
+		{"Ada Example", "Born", "10 December 1815", ""},
+		{"Ada Example", "Known\u00a0for", "Example engines", ""},
+	})
+
+	e.Options.ShortDescription = true
+	e.Options.InferSubjectType = true
+	result = parseFixture(t, e, "short_description.html")
+	assertQuads(t, result.Quads, []quadFields{
+		{"Ada Example", TypeRelationship, "Person", ""},
+		{"Ada Example", DescriptionRelationship, description, ""},
+		{"Ada Example", "Born", "10 December 1815", ""},
+		{"Ada Example", "Known\u00a0for", "Example engines", ""},
+	})
+	if q, _ := findQuad(result.Quads, DescriptionRelationship); q.SubjectURL != "https://en.wikipedia.org/wiki/Ada_Example" {
+		t.Errorf("description subject URL = %q, want the canonical URL", q.SubjectURL)
+	}
+}
+
+func TestParseShortDescription(t *testing.T) {
+	tests := []struct {
+		html string
+		want string
+	}{
+		{`<div class="shortdescription">  Capital of   France </div>`, "Capital of France"},
+		{`<div class="shortdescription">none</div>`, ""},
+		{`<div class="shortdescription">None</div><div class="shortdescription">City in Texas</div>`, "City in Texas"},
+		{`<div class="shortdescription"></div>`, ""},
+		{`<p>No description</p>`, ""},
+	}
+	for _, tt := range tests {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><body>" + tt.html + "</body></html>"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := parseShortDescription(doc.Selection); got != tt.want {
+			t.Errorf("parseShortDescription(%s) = %q, want %q", tt.html, got, tt.want)
+		}
+	}
+}
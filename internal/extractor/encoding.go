@@ -0,0 +1,49 @@
+package extractor
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// decodeBody decodes a response body according to its Content-Encoding
+// header, reading at most one byte past limit so oversized output can be
+// detected without inflating all of it.
+//
+// The HTTP transport asks for gzip and decompresses it transparently, and
+// colly decompresses gzip itself when the header is set explicitly, so gzip
+// bodies arrive here already decoded. Deflate and Brotli are only sent by
+// servers that ignore Accept-Encoding or by caches and proxies that recompress
+// pages, but are cheap to support.
+func decodeBody(encoding string, body []byte, limit int) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity", "gzip", "x-gzip":
+		return body, nil
+	case "deflate":
+		// Deflate is specified as zlib-wrapped, but some servers send a raw stream
+		reader, err := zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			reader = flate.NewReader(bytes.NewReader(body))
+		}
+		defer reader.Close()
+
+		decoded, err := io.ReadAll(io.LimitReader(reader, int64(limit)+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode deflate body: %w", err)
+		}
+		return decoded, nil
+	case "br":
+		decoded, err := io.ReadAll(io.LimitReader(brotli.NewReader(bytes.NewReader(body)), int64(limit)+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode brotli body: %w", err)
+		}
+		return decoded, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedEncoding, encoding)
+	}
+}
@@ -0,0 +1,131 @@
+package extractor
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+// compress encodes data with a writer from one of the compression packages
+func compress(t *testing.T, data []byte, newWriter func(io.Writer) io.WriteCloser) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := newWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeBody(t *testing.T) {
+	page := []byte(strings.Repeat("<p>Examplia is a fictional country.</p>\n", 50))
+
+	zlibBody := compress(t, page, func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) })
+	rawDeflateBody := compress(t, page, func(w io.Writer) io.WriteCloser {
+		fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+		return fw
+	})
+	brotliBody := compress(t, page, func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) })
+
+	tests := []struct {
+		encoding string
+		body     []byte
+	}{
+		{"", page},
+		{"identity", page},
+		// gzip is decoded by the transport or colly before decodeBody sees it
+		{"gzip", page},
+		{"x-gzip", page},
+		{"deflate", zlibBody},
+		{"deflate", rawDeflateBody},
+		{"Deflate", zlibBody},
+		{"br", brotliBody},
+		{" BR ", brotliBody},
+	}
+	for _, tt := range tests {
+		decoded, err := decodeBody(tt.encoding, tt.body, len(page))
+		if err != nil {
+			t.Errorf("decodeBody(%q): %v", tt.encoding, err)
+			continue
+		}
+		if !bytes.Equal(decoded, page) {
+			t.Errorf("decodeBody(%q) = %d bytes, want the %d byte page", tt.encoding, len(decoded), len(page))
+		}
+	}
+}
+
+func TestDecodeBodyLimit(t *testing.T) {
+	page := bytes.Repeat([]byte("a"), 1000)
+	bodies := map[string][]byte{
+		"deflate": compress(t, page, func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }),
+		"br":      compress(t, page, func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) }),
+	}
+	for encoding, body := range bodies {
+		// One byte past the limit is read so oversized pages can be detected
+		decoded, err := decodeBody(encoding, body, 100)
+		if err != nil {
+			t.Fatalf("decodeBody(%q): %v", encoding, err)
+		}
+		if len(decoded) != 101 {
+			t.Errorf("decodeBody(%q) with limit 100 read %d bytes, want 101", encoding, len(decoded))
+		}
+	}
+}
+
+func TestDecodeBodyErrors(t *testing.T) {
+	if _, err := decodeBody("compress", []byte("data"), 100); !errors.Is(err, ErrUnsupportedEncoding) {
+		t.Errorf("decodeBody(compress) error = %v, want ErrUnsupportedEncoding", err)
+	}
+	if _, err := decodeBody("br", []byte("not brotli at all"), 100); err == nil {
+		t.Error("decodeBody(br) of a corrupt body succeeded")
+	}
+}
+
+func TestExtractEncodedPage(t *testing.T) {
+	page, err := os.ReadFile(filepath.Join("testdata", "native_name.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bodies := map[string][]byte{
+		"deflate": compress(t, page, func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }),
+		"br":      compress(t, page, func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) }),
+		"zstd":    page,
+	}
+
+	server, e := newFixtureServer(t)
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := strings.TrimPrefix(r.URL.Path, "/wiki/")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Encoding", encoding)
+		w.Write(bodies[encoding])
+	})
+
+	for encoding := range bodies {
+		result, err := e.Extract(server.URL + "/wiki/" + encoding)
+		if encoding == "zstd" {
+			if !errors.Is(err, ErrUnsupportedEncoding) || !errors.Is(err, ErrFetchFailed) {
+				t.Errorf("%s: Extract error = %v, want ErrUnsupportedEncoding", encoding, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", encoding, err)
+			continue
+		}
+		if q, ok := findQuad(result.Quads, "Occupation"); !ok || q.Value != "Potter" {
+			t.Errorf("%s: Occupation quad = %+v, want value Potter", encoding, q)
+		}
+	}
+}
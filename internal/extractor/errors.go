@@ -0,0 +1,101 @@
+package extractor
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrInvalidURL is returned when the URL is not a valid Wikipedia page URL
+	ErrInvalidURL = errors.New("invalid Wikipedia URL")
+
+	// ErrFetchFailed is returned when the page could not be fetched
+	ErrFetchFailed = errors.New("failed to fetch page")
+
+	// ErrNotFound is returned when the page does not exist
+	ErrNotFound = errors.New("page not found")
+
+	// ErrNoInfobox is returned when the page has no infobox and no other structured data
+	ErrNoInfobox = errors.New("page has no infobox")
+
+	// ErrNoStructuredData is returned in strict mode when the page has neither
+	// an infobox nor a wikitable, as with stubs and prose-only articles
+	ErrNoStructuredData = errors.New("page has no infobox or tables")
+
+	// ErrUnavailable is returned when the wiki answers with 502, 503 or 504,
+	// usually a temporary outage or maintenance
+	ErrUnavailable = errors.New("wiki temporarily unavailable")
+
+	// ErrRateLimited is returned when the wiki answers with 429 Too Many Requests
+	ErrRateLimited = errors.New("rate limited by wiki")
+
+	// ErrDisambiguation is returned when the URL points at a disambiguation page
+	ErrDisambiguation = errors.New("page is a disambiguation page")
+
+	// ErrResponseTooLarge is returned when a page exceeds ExtractorOptions.MaxBodyBytes
+	ErrResponseTooLarge = errors.New("response body exceeds size limit")
+
+	// ErrUnresolvedCitations is returned under CitationPolicyError when
+	// footnote markers link to no entry of the page's reference list
+	ErrUnresolvedCitations = errors.New("page has unresolved citations")
+
+	// ErrUnsupportedEncoding is returned when a page uses a content encoding that cannot be decoded
+	ErrUnsupportedEncoding = errors.New("unsupported content encoding")
+)
+
+// FetchError describes a failure to fetch a page. It matches ErrFetchFailed
+// with errors.Is and exposes the underlying cause via errors.Unwrap.
+type FetchError struct {
+	URL string
+	Err error
+}
+
+func (e *FetchError) Error() string {
+	return fmt.Sprintf("%v %s: %v", ErrFetchFailed, e.URL, e.Err)
+}
+
+// Unwrap returns the underlying fetch error
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrFetchFailed
+func (e *FetchError) Is(target error) bool {
+	return target == ErrFetchFailed
+}
+
+// StatusError describes a fetch the wiki answered with an error status. It
+// matches ErrNotFound for 404 and 410 and ErrFetchFailed otherwise, as well as
+// ErrRateLimited or ErrUnavailable where they apply.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	if e.notFound() {
+		return fmt.Sprintf("%v: %s", ErrNotFound, e.URL)
+	}
+	return fmt.Sprintf("%v %s: HTTP %d %s", ErrFetchFailed, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Is reports whether target is one of the errors matching the status
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.notFound()
+	case ErrFetchFailed:
+		return !e.notFound()
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrUnavailable:
+		return e.StatusCode == http.StatusBadGateway || e.StatusCode == http.StatusServiceUnavailable ||
+			e.StatusCode == http.StatusGatewayTimeout
+	}
+	return false
+}
+
+func (e *StatusError) notFound() bool {
+	return e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone
+}
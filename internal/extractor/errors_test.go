@@ -0,0 +1,125 @@
+package extractor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestStatusErrorIs(t *testing.T) {
+	tests := []struct {
+		status      int
+		notFound    bool
+		rateLimited bool
+		unavailable bool
+	}{
+		{http.StatusNotFound, true, false, false},
+		{http.StatusGone, true, false, false},
+		{http.StatusForbidden, false, false, false},
+		{http.StatusTooManyRequests, false, true, false},
+		{http.StatusInternalServerError, false, false, false},
+		{http.StatusBadGateway, false, false, true},
+		{http.StatusServiceUnavailable, false, false, true},
+		{http.StatusGatewayTimeout, false, false, true},
+	}
+	for _, tt := range tests {
+		err := fmt.Errorf("extracting: %w", &StatusError{URL: "https://en.wikipedia.org/wiki/Go", StatusCode: tt.status})
+
+		if got := errors.Is(err, ErrNotFound); got != tt.notFound {
+			t.Errorf("%d: errors.Is(ErrNotFound) = %v, want %v", tt.status, got, tt.notFound)
+		}
+		if got := errors.Is(err, ErrFetchFailed); got == tt.notFound {
+			t.Errorf("%d: errors.Is(ErrFetchFailed) = %v, want %v", tt.status, got, !tt.notFound)
+		}
+		if got := errors.Is(err, ErrRateLimited); got != tt.rateLimited {
+			t.Errorf("%d: errors.Is(ErrRateLimited) = %v, want %v", tt.status, got, tt.rateLimited)
+		}
+		if got := errors.Is(err, ErrUnavailable); got != tt.unavailable {
+			t.Errorf("%d: errors.Is(ErrUnavailable) = %v, want %v", tt.status, got, tt.unavailable)
+		}
+
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.status {
+			t.Errorf("%d: errors.As did not find the StatusError", tt.status)
+		}
+	}
+}
+
+func TestFetchError(t *testing.T) {
+	err := fmt.Errorf("extracting: %w", &FetchError{URL: "https://en.wikipedia.org/wiki/Go", Err: io.ErrUnexpectedEOF})
+
+	if !errors.Is(err, ErrFetchFailed) {
+		t.Error("FetchError does not match ErrFetchFailed")
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Error("FetchError does not unwrap to its cause")
+	}
+	if errors.Is(err, ErrNotFound) {
+		t.Error("FetchError matches ErrNotFound")
+	}
+}
+
+func TestValidateURLErrors(t *testing.T) {
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{"https://en.wikipedia.org/wiki/Go", true},
+		{"http://de.wikipedia.org/wiki/Go", true},
+		{"ftp://en.wikipedia.org/wiki/Go", false},
+		{"https://example.com/wiki/Go", false},
+		{"https://en.wiktionary.org/wiki/go", false},
+		{"://missing-scheme", false},
+		{"en.wikipedia.org/wiki/Go", false},
+	}
+	for _, tt := range tests {
+		err := ValidateWikipediaURL(tt.url)
+		if tt.valid && err != nil {
+			t.Errorf("ValidateWikipediaURL(%q) = %v, want nil", tt.url, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidURL) {
+			t.Errorf("ValidateWikipediaURL(%q) = %v, want ErrInvalidURL", tt.url, err)
+		}
+	}
+
+	// Invalid URLs are rejected before anything is fetched
+	if _, err := NewExtractor().ExtractFromURL("https://example.com/wiki/Go"); !errors.Is(err, ErrInvalidURL) {
+		t.Errorf("ExtractFromURL = %v, want ErrInvalidURL", err)
+	}
+}
+
+func TestParseDocumentErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{
+			name: "disambiguation",
+			body: `<h1 id="firstHeading">Mercury</h1>
+<div class="mw-parser-output"><p><b>Mercury</b> may refer to:</p>
+<table id="disambigbox" class="metadata plainlinks dmbox dmbox-disambig"><tr><td>This disambiguation page lists articles.</td></tr></table></div>`,
+			want: ErrDisambiguation,
+		},
+		{
+			name: "tables without pairs",
+			body: `<h1 id="firstHeading">Examplia</h1>
+<div class="mw-parser-output"><table class="wikitable"><tr><td>Only one column</td></tr></table></div>`,
+			want: ErrNoInfobox,
+		},
+	}
+	for _, tt := range tests {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><body>" + tt.body + "</body></html>"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewExtractor().ParseDocument(doc.Selection); !errors.Is(err, tt.want) {
+			t.Errorf("%s: ParseDocument error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
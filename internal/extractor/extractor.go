@@ -1,8 +1,14 @@
 package extractor
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/gocolly/colly/v2"
@@ -11,80 +17,777 @@ import (
 // Quad represents a structured data point extracted from Wikipedia
 type Quad struct {
 	Subject     string `json:"subject"`
+	// SubjectURL is the canonical article URL identifying the subject, set
+	// for quads about the page's own subject
+	SubjectURL string `json:"subject_url,omitempty"`
 	Relationship string `json:"relationship"`
 	Value       string `json:"value"`
+	// ValueURL is the article URL of the value, set when the value is a
+	// linked entity such as a predecessor in a succession box
+	ValueURL string `json:"value_url,omitempty"`
 	Citation    string `json:"citation"`
+	// RawRelationship is the label as it appeared on the page, set when the
+	// relationship was rewritten to its canonical name
+	RawRelationship string `json:"raw_relationship,omitempty"`
+	// Language is the BCP 47 language tag of the value, when the page marks it
+	Language string `json:"language,omitempty"`
+	// Source is the URL of the page the quad was extracted from
+	Source string `json:"source,omitempty"`
+	// Section is the heading of the article section containing a table
+	// quad, or the office of a succession box quad
+	Section string `json:"section,omitempty"`
+	// Project is the Wikimedia project the page belongs to, e.g. "wikipedia" or "wiktionary"
+	Project string `json:"project,omitempty"`
+	// RawHTML is the inner HTML of the value cell, set when Options.KeepHTML is enabled
+	RawHTML string `json:"raw_html,omitempty"`
+	// ValueType names the kind of value when it was recognized and
+	// normalized, e.g. ValueTypePercent or ValueTypeRank
+	ValueType string `json:"value_type,omitempty"`
+	// RawValue is the value as it appeared on the page, set when Value was normalized
+	RawValue string `json:"raw_value,omitempty"`
+	// Number is the value's number in canonical form, e.g. "1234567.5" for
+	// "1.234.567,5" on a German page, set when Options.NormalizeNumbers is
+	// enabled and the value is a number
+	Number string `json:"number,omitempty"`
+	// Metric and Imperial are the two halves of a value given in both
+	// systems of units, such as "100 km" and "62 mi" for "100 km (62 mi)",
+	// set when Options.SplitUnits is enabled
+	Metric   string `json:"metric,omitempty"`
+	Imperial string `json:"imperial,omitempty"`
+	// CountryAlpha2 and CountryAlpha3 are the ISO 3166-1 codes of the
+	// countries named by the value, separated by spaces when there are
+	// several, set when Options.CountryCodes is enabled
+	CountryAlpha2 string `json:"country_alpha2,omitempty"`
+	CountryAlpha3 string `json:"country_alpha3,omitempty"`
+	// Sources lists every source URL asserting the quad, set when quads from
+	// several pages were combined by MergeSources
+	Sources []string `json:"sources,omitempty"`
+	// TruncatedFields names the fields, "value" or "citation", cut short
+	// because they exceeded Options.MaxValueLength or MaxCitationLength
+	TruncatedFields []string `json:"truncated_fields,omitempty"`
 }
 
-// Extractor handles Wikipedia page extraction
+// AliasRelationship is the relationship used for alternate names of the subject
+const AliasRelationship = "alias"
+
+// NoCitation is the placeholder shown for uncited quads in human-readable
+// output. Extraction leaves Citation empty instead; databases written by
+// older versions may still contain it.
+const NoCitation = "no citation"
+
+// ExtractorOptions configures optional extraction behaviour
+type ExtractorOptions struct {
+	// MaxQuads caps the number of quads collected per page. Zero means unlimited.
+	MaxQuads int
+
+	// Relationships maps relationship label synonyms to canonical names. When
+	// nil, relationships are kept exactly as they appear on the page.
+	Relationships RelationshipMap
+
+	// AllowedHosts lists hosts accepted in addition to wikipedia.org, such as
+	// a mirror or a local server serving saved fixture pages. Entries may
+	// include a port.
+	AllowedHosts []string
+
+	// AllowSisterProjects accepts pages from Wikimedia sister projects such
+	// as Wiktionary and Wikivoyage, which share Wikipedia's markup
+	AllowSisterProjects bool
+
+	// PrivateWiki, when set, is a login-protected MediaWiki instance whose
+	// pages are accepted and fetched with its credentials. Create it with
+	// NewPrivateWiki.
+	PrivateWiki *PrivateWiki
+
+	// KeepHTML records the inner HTML of each value cell in Quad.RawHTML, for
+	// consumers that need the links and formatting lost by plain text
+	KeepHTML bool
+
+	// MaxBodyBytes caps the decoded size of a fetched page. Larger responses
+	// are aborted with ErrResponseTooLarge. Zero uses DefaultMaxBodyBytes.
+	MaxBodyBytes int
+
+	// CitationSeparator joins the citations of a quad. Empty uses
+	// DefaultCitationSeparator.
+	CitationSeparator string
+
+	// MaxCitations caps the citations listed per quad; the rest are summarized
+	// as "… and N more". Zero means unlimited.
+	MaxCitations int
+
+	// OnlyWithCitations drops quads that have no citation
+	OnlyWithCitations bool
+
+	// Strict fails extraction with ErrNoStructuredData when a page has no
+	// infobox or table and yields no quads. Otherwise such pages yield an
+	// empty result with NoStructuredData set.
+	Strict bool
+
+	// Logger, when set, receives a warning with the raw HTML of every
+	// infobox row skipped because it has a label but no value or a value but
+	// no label, to help find rows the parser misses
+	Logger *slog.Logger
+
+	// NormalizeNumbers sets Quad.Number on quads whose value is a number,
+	// reading digit grouping and the decimal separator according to the
+	// page's language. Value keeps the text as displayed.
+	NormalizeNumbers bool
+
+	// SplitUnits sets Quad.Metric and Quad.Imperial on quads whose value
+	// shows a measurement with its conversion, as the {{convert}} template
+	// does, e.g. "100 km (62 mi)"
+	SplitUnits bool
+
+	// CountryCodes sets Quad.CountryAlpha2 and Quad.CountryAlpha3 on infobox
+	// quads whose value shows a country's flag or consists of country names
+	// or nationalities, such as "France" or "American"
+	CountryCodes bool
+
+	// TypedTables reads tables with a header row and a rank or percent
+	// column as one record per row, with ranks and percentages normalized
+	// and typed (see parseTypedTable). Otherwise every table is read as
+	// label/value pairs.
+	TypedTables bool
+
+	// FirstOnly keeps only the first quad of each relationship per subject
+	FirstOnly bool
+
+	// InferSubjectType adds a quad with relationship TypeRelationship giving
+	// the subject's schema.org type, when ExtractResult.SubjectType is known
+	InferSubjectType bool
+
+	// ShortDescription adds a quad with relationship DescriptionRelationship
+	// giving the page's short description, when it has one
+	ShortDescription bool
+
+	// Subject, when set, replaces the page title as the subject of the quads
+	// about the page, e.g. to attribute a page's data to a sub-entity. Quads
+	// about other subjects, such as ranked table rows, are left alone.
+	Subject string
+
+	// MaxCitationDepth limits how many notes deep a citation is followed when
+	// a footnote cites other references rather than linking a source itself.
+	// Zero uses DefaultCitationDepth.
+	MaxCitationDepth int
+
+	// MaxValueLength and MaxCitationLength limit the length, in characters,
+	// of a quad's value and citation. Zero uses DefaultMaxValueLength and
+	// DefaultMaxCitationLength.
+	MaxValueLength    int
+	MaxCitationLength int
+
+	// LengthPolicy says what happens to quads over MaxValueLength or
+	// MaxCitationLength; empty means LengthPolicyTruncate
+	LengthPolicy LengthPolicy
+
+	// CitationPolicy says what happens when footnote markers link to no
+	// reference; empty means CitationPolicyOff
+	CitationPolicy CitationPolicy
+
+	// FollowSeeAlso collects the articles listed in the page's "See also"
+	// section into ExtractResult.SeeAlso
+	FollowSeeAlso bool
+
+	// InfoboxSelector is the CSS selector matching a page's infoboxes, for
+	// wikis whose infobox templates use other classes. Empty uses
+	// DefaultInfoboxSelector.
+	InfoboxSelector string
+
+	// IncludeRelationships, when not empty, keeps only the quads with one of
+	// these relationships. ExcludeRelationships drops the quads with one of
+	// its relationships. Both are matched against canonical names, ignoring
+	// case.
+	IncludeRelationships []string
+	ExcludeRelationships []string
+
+	// ValidationPolicy says what happens to quads whose value fails the rule
+	// of their relationship in ValidationRules. The zero value skips
+	// validation.
+	ValidationPolicy ValidationPolicy
+
+	// ValidationRules maps relationships to the validator their values must
+	// pass. Nil uses DefaultValidationRules.
+	ValidationRules ValidationRules
+
+	// KeepPageHTML keeps the fetched page's HTML in ExtractResult.PageHTML,
+	// e.g. to archive it for reprocessing offline
+	KeepPageHTML bool
+}
+
+const (
+	// DefaultMaxBodyBytes is the page size limit used when MaxBodyBytes is zero
+	DefaultMaxBodyBytes = 10 * 1024 * 1024
+
+	// DefaultCitationSeparator joins citations when CitationSeparator is empty
+	DefaultCitationSeparator = "; "
+
+	// DefaultCitationDepth is the note depth followed when MaxCitationDepth is zero
+	DefaultCitationDepth = 3
+
+	// DefaultInfoboxSelector matches infoboxes when InfoboxSelector is empty
+	DefaultInfoboxSelector = ".infobox"
+)
+
+// Extractor handles Wikipedia page extraction. Extraction methods are safe
+// for concurrent use as long as Options is not modified at the same time.
 type Extractor struct {
 	colly *colly.Collector
+
+	// Options controls optional extraction behaviour and may be changed
+	// between extractions
+	Options ExtractorOptions
 }
 
-// NewExtractor creates a new Wikipedia extractor
+// NewExtractor creates a new Wikipedia extractor with a default collector
 func NewExtractor() *Extractor {
 	c := colly.NewCollector(
 		colly.UserAgent("Wikipedia-Extraction/1.0"),
+		colly.AllowURLRevisit(),
 	)
 
+	return NewExtractorWithCollector(c)
+}
+
+// NewExtractorWithCollector creates a Wikipedia extractor that fetches pages
+// using a pre-configured collector, e.g. one with a proxy or custom transport.
+//
+// The caller keeps ownership of the collector. The extractor never registers
+// callbacks on it; each extraction runs on a clone, which copies the
+// collector's settings and shares its HTTP backend, cookie jar and visited-URL
+// store. Configuration changes made to the collector later apply to subsequent
+// extractions. Unless the collector allows URL revisits, each URL can only be
+// extracted once across all extractors sharing it.
+func NewExtractorWithCollector(c *colly.Collector) *Extractor {
 	return &Extractor{
 		colly: c,
+		Options: ExtractorOptions{
+			Relationships: DefaultRelationshipMap(),
+		},
 	}
 }
 
+// ExtractResult holds the quads extracted from a page along with fetch metadata
+type ExtractResult struct {
+	Title        string `json:"title"`
+	Quads        []Quad `json:"quads"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	// NotModified is set when a conditional fetch found the page unchanged
+	NotModified bool `json:"not_modified,omitempty"`
+	// Truncated is set when extraction stopped early because of Options.MaxQuads
+	Truncated bool `json:"truncated,omitempty"`
+	// PageID and RevisionID identify the page and the exact revision that was
+	// extracted. They are zero when the page does not expose them.
+	PageID     int64 `json:"page_id,omitempty"`
+	RevisionID int64 `json:"revision_id,omitempty"`
+	// PageLastModified is when the page was last edited, as shown in its
+	// footer. It is zero when the date could not be read.
+	PageLastModified time.Time `json:"page_last_modified"`
+	// SubjectURL is the page's canonical article URL, or the fetched URL when
+	// the page does not declare one
+	SubjectURL string `json:"subject_url,omitempty"`
+	// SubjectType is the subject's schema.org type, such as Person, Place or
+	// Movie, inferred from the infobox. It is empty when unknown.
+	SubjectType string `json:"subject_type,omitempty"`
+	// ShortDescription is the page's one-line short description, such as
+	// "Theoretical physicist (1879–1955)". It is empty when the page has none.
+	ShortDescription string `json:"short_description,omitempty"`
+	// Summary and Thumbnail are the page's lead paragraph as plain text and
+	// the URL of its lead image, set by ExtractSummary
+	Summary   string `json:"summary,omitempty"`
+	Thumbnail string `json:"thumbnail,omitempty"`
+	// NoStructuredData is set when the page has no infobox or table to
+	// extract from and yielded no quads, which usually means a stub or
+	// prose-only article
+	NoStructuredData bool `json:"no_structured_data,omitempty"`
+	// SkippedRows counts the infobox rows skipped because they have a label
+	// but no value or a value but no label
+	SkippedRows int `json:"skipped_rows,omitempty"`
+	// SkippedLongQuads counts the quads dropped for an overlong value or
+	// citation under LengthPolicySkip
+	SkippedLongQuads int `json:"skipped_long_quads,omitempty"`
+	// UnresolvedCitations counts the footnote markers in the infoboxes and
+	// tables that link to no entry of the reference list
+	UnresolvedCitations int `json:"unresolved_citations,omitempty"`
+	// ValidationFailures lists the quads whose value failed the validation
+	// rule of their relationship, kept or dropped by Options.ValidationPolicy
+	ValidationFailures []ValidationFailure `json:"validation_failures,omitempty"`
+	// PageHTML is the page's HTML as fetched, after decompression, when
+	// Options.KeepPageHTML is set
+	PageHTML []byte `json:"-"`
+	// Links are the absolute URLs of the articles linked from the page's
+	// infoboxes on the same wiki, in page order without duplicates
+	Links []string `json:"links,omitempty"`
+	// SeeAlso are the absolute URLs of the articles listed in the page's "See
+	// also" section on the same wiki, when Options.FollowSeeAlso is set
+	SeeAlso []string `json:"see_also,omitempty"`
+
+	// infoboxHrefs are the raw link targets found by ParseDocument, resolved
+	// into Links once the page URL is known
+	infoboxHrefs []string
+	// seeAlsoHrefs are the raw "See also" link targets, resolved into SeeAlso
+	seeAlsoHrefs []string
+
+	// subject replaces Title as the subject of the page's quads, set from
+	// Options.Subject
+	subject string
+}
+
 // ExtractFromURL extracts structured data from a Wikipedia URL
 func (e *Extractor) ExtractFromURL(url string) ([]Quad, error) {
-	var quads []Quad
-	var references map[string]string
+	result, err := e.Extract(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Quads, nil
+}
+
+// Extract extracts structured data from a Wikipedia URL along with the
+// response's cache validators
+func (e *Extractor) Extract(url string) (*ExtractResult, error) {
+	return e.ExtractIfModified(url, "", "")
+}
+
+// ExtractIfModified performs a conditional fetch using the ETag and/or
+// Last-Modified values from a previous fetch. If the server responds with
+// 304 Not Modified, extraction is skipped and the result has NotModified set.
+// Pages on a mobile domain are fetched from the desktop domain instead, which
+// is also recorded as the quads' Source (see DesktopURL).
+func (e *Extractor) ExtractIfModified(url, etag, lastModified string) (*ExtractResult, error) {
+	return e.extractIfModified(context.Background(), url, etag, lastModified)
+}
+
+// ExtractContext is like Extract but gives up when ctx is done, returning an
+// error that wraps ctx.Err(), e.g. context.DeadlineExceeded
+func (e *Extractor) ExtractContext(ctx context.Context, url string) (*ExtractResult, error) {
+	return e.ExtractIfModifiedContext(ctx, url, "", "")
+}
+
+// ExtractIfModifiedContext is like ExtractIfModified but gives up when ctx is
+// done, returning an error that wraps ctx.Err(). A request not yet sent is
+// skipped and a response whose headers arrive after ctx is done is not
+// downloaded. A download already in progress can't be interrupted; it runs
+// on in the background, bounded by the extractor's timeout (see SetTimeout).
+func (e *Extractor) ExtractIfModifiedContext(ctx context.Context, url, etag, lastModified string) (*ExtractResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("extraction of %s abandoned: %w", url, err)
+	}
+	if ctx.Done() == nil {
+		// The context can never be cancelled
+		return e.extractIfModified(ctx, url, etag, lastModified)
+	}
+
+	type outcome struct {
+		result *ExtractResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := e.extractIfModified(ctx, url, etag, lastModified)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.result, o.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("extraction of %s abandoned: %w", url, ctx.Err())
+	}
+}
 
-	e.colly.OnHTML("body", func(h *colly.HTMLElement) {
-		doc := h.DOM
+// extractIfModified implements ExtractIfModified, checking ctx before the
+// request is sent and before the response body is downloaded
+func (e *Extractor) extractIfModified(ctx context.Context, url, etag, lastModified string) (*ExtractResult, error) {
+	if err := e.ValidateURL(url); err != nil {
+		return nil, err
+	}
+
+	// Mobile pages collapse sections and restyle infoboxes, so fetch the desktop page
+	url = DesktopURL(url)
+
+	fetch := &pageFetch{ctx: ctx, etag: etag, lastModified: lastModified}
+	err := e.pageCollector().Request(http.MethodGet, url, nil, fetch.collyContext(), nil)
+	return e.finishFetch(url, fetch, err)
+}
+
+// pageFetch is the state of one page fetch, filled in by the callbacks of
+// pageCollector. Each request carries its own in its colly context, so
+// concurrent fetches on one collector don't share state.
+type pageFetch struct {
+	ctx                context.Context
+	etag, lastModified string
 
-		// Extract page title
-		title := doc.Find("h1#firstHeading").Text()
-		if title == "" {
-			title = doc.Find("title").Text()
+	result                             *ExtractResult
+	parseErr                           error
+	notModified                        bool
+	statusCode                         int
+	responseETag, responseLastModified string
+	tooLarge                           bool
+	decodeErr                          error
+	pageHTML                           []byte
+	// err is the error the fetch failed with. Async requests report it only
+	// here, as their Request call returns before the fetch is made.
+	err error
+}
+
+// pageFetchKey is the colly context key of a request's pageFetch
+const pageFetchKey = "pageFetch"
+
+// collyContext returns a colly context carrying the fetch to the callbacks
+func (f *pageFetch) collyContext() *colly.Context {
+	ctx := colly.NewContext()
+	ctx.Put(pageFetchKey, f)
+	return ctx
+}
+
+// fetchOf returns the pageFetch of the request a callback runs for
+func fetchOf(ctx *colly.Context) *pageFetch {
+	return ctx.GetAny(pageFetchKey).(*pageFetch)
+}
+
+// maxBodyBytes returns Options.MaxBodyBytes, or DefaultMaxBodyBytes when it is zero
+func (e *Extractor) maxBodyBytes() int {
+	if e.Options.MaxBodyBytes <= 0 {
+		return DefaultMaxBodyBytes
+	}
+	return e.Options.MaxBodyBytes
+}
+
+// pageCollector returns a clone of the extractor's collector with callbacks
+// that fetch and parse pages, recording the outcome of each request in the
+// pageFetch of its colly context
+func (e *Extractor) pageCollector() *colly.Collector {
+	maxBodyBytes := e.maxBodyBytes()
+
+	// Use a clone so callbacks don't accumulate on the shared collector
+	c := e.colly.Clone()
+
+	// Read one byte past the limit so an oversized body can be told apart from
+	// one that is exactly at the limit; colly would otherwise truncate silently
+	c.MaxBodySize = maxBodyBytes + 1
+
+	c.OnRequest(func(r *colly.Request) {
+		fetch := fetchOf(r.Ctx)
+		if fetch.ctx.Err() != nil {
+			r.Abort()
+			return
+		}
+		if e.Options.PrivateWiki != nil {
+			e.Options.PrivateWiki.authorize(r)
+		}
+		if fetch.etag != "" {
+			r.Headers.Set("If-None-Match", fetch.etag)
 		}
+		if fetch.lastModified != "" {
+			r.Headers.Set("If-Modified-Since", fetch.lastModified)
+		}
+	})
+
+	// Abort before downloading when the server announces an oversized body
+	c.OnResponseHeaders(func(r *colly.Response) {
+		fetch := fetchOf(r.Ctx)
+		if fetch.ctx.Err() != nil {
+			r.Request.Abort()
+			return
+		}
+		length, err := strconv.Atoi(r.Headers.Get("Content-Length"))
+		if err == nil && length > maxBodyBytes {
+			fetch.tooLarge = true
+			r.Request.Abort()
+		}
+	})
+
+	c.OnResponse(func(r *colly.Response) {
+		fetch := fetchOf(r.Ctx)
+		fetch.responseETag = r.Headers.Get("ETag")
+		fetch.responseLastModified = r.Headers.Get("Last-Modified")
+
+		r.Body, fetch.decodeErr = decodeBody(r.Headers.Get("Content-Encoding"), r.Body, maxBodyBytes)
+		if len(r.Body) > maxBodyBytes {
+			fetch.tooLarge = true
+		}
+		if fetch.tooLarge || fetch.decodeErr != nil {
+			// Don't parse a truncated or undecodable page
+			r.Body = nil
+		} else if e.Options.KeepPageHTML {
+			fetch.pageHTML = r.Body
+		}
+	})
+
+	c.OnError(func(r *colly.Response, err error) {
+		fetch := fetchOf(r.Ctx)
+		fetch.err = err
+		fetch.statusCode = r.StatusCode
+		if r.StatusCode == http.StatusNotModified {
+			fetch.notModified = true
+		}
+	})
+
+	c.OnHTML("html", func(h *colly.HTMLElement) {
+		fetch := fetchOf(h.Response.Ctx)
+		if fetch.tooLarge || fetch.decodeErr != nil {
+			return
+		}
+		fetch.result, fetch.parseErr = e.ParseDocument(h.DOM)
+	})
+
+	return c
+}
+
+// finishFetch turns the outcome of a page fetch into the extraction result,
+// or the error describing why there is none. visitErr is the error returned
+// when the request was made.
+func (e *Extractor) finishFetch(url string, fetch *pageFetch, visitErr error) (*ExtractResult, error) {
+	if visitErr == nil {
+		visitErr = fetch.err
+	}
+	if ctxErr := fetch.ctx.Err(); ctxErr != nil {
+		return nil, fmt.Errorf("extraction of %s abandoned: %w", url, ctxErr)
+	}
+	if fetch.tooLarge {
+		return nil, &FetchError{URL: url, Err: fmt.Errorf("%w of %d bytes", ErrResponseTooLarge, e.maxBodyBytes())}
+	}
+	if fetch.decodeErr != nil {
+		return nil, &FetchError{URL: url, Err: fetch.decodeErr}
+	}
+	if fetch.notModified {
+		// The page is unchanged, so keep the validators that were sent
+		return &ExtractResult{ETag: fetch.etag, LastModified: fetch.lastModified, NotModified: true}, nil
+	}
+	if fetch.statusCode >= http.StatusBadRequest {
+		return nil, &StatusError{URL: url, StatusCode: fetch.statusCode}
+	}
+	if visitErr != nil {
+		return nil, &FetchError{URL: url, Err: visitErr}
+	}
+
+	if fetch.parseErr != nil {
+		return nil, fmt.Errorf("%w: %s", fetch.parseErr, url)
+	}
+	result := fetch.result
+	if result == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoInfobox, url)
+	}
+
+	result.ETag = fetch.responseETag
+	result.LastModified = fetch.responseLastModified
+	result.PageHTML = fetch.pageHTML
+	result.Links = resolveArticleLinks(url, result.infoboxHrefs)
+	resolveValueURLs(url, result.Quads)
+	result.SeeAlso = resolveArticleLinks(url, result.seeAlsoHrefs)
+	if result.SubjectURL == "" {
+		// Without a canonical link the fetched URL is the best identifier
+		result.setSubjectURL(url)
+	}
+	project := ProjectForURL(url)
+	for i := range result.Quads {
+		result.Quads[i].Source = url
+		result.Quads[i].Project = project
+	}
+
+	return result, nil
+}
+
+// ParseDocument extracts quads from an already fetched Wikipedia page. It
+// returns ErrDisambiguation for disambiguation pages and ErrNoInfobox when the
+// page's tables yield no quads. Pages with no infobox or table at all that
+// yield no quads are reported through NoStructuredData, or ErrNoStructuredData
+// when Options.Strict is set.
+func (e *Extractor) ParseDocument(doc *goquery.Selection) (*ExtractResult, error) {
+	result := &ExtractResult{}
+
+	// Extract page title
+	title := doc.Find("h1#firstHeading").Text()
+	if title == "" {
+		title = doc.Find("title").Text()
+	}
+	result.Title = title
+	result.SubjectURL = canonicalURL(doc)
+	result.infoboxHrefs = infoboxLinks(doc, e.infoboxSelector())
+	if e.Options.FollowSeeAlso {
+		result.seeAlsoHrefs = seeAlsoLinks(doc)
+	}
+	result.PageID, result.RevisionID = parsePageIDs(doc)
+	result.PageLastModified = parsePageLastModified(doc)
+	result.ShortDescription = parseShortDescription(doc)
+
+	// Disambiguation pages list other articles rather than describing an entity
+	if doc.Find("#disambigbox, .dmbox-disambig").Length() > 0 {
+		return nil, ErrDisambiguation
+	}
+
+	// Collapsed sections are extracted too, without their show/hide toggles
+	stripCollapseToggles(doc)
+
+	// First, extract all references from the references section
+	references := e.extractReferences(doc)
 
-		// First, extract all references from the references section
-		references = e.extractReferences(h.DOM)
+	// Find and parse infoboxes
+	infoboxes := doc.Find(e.infoboxSelector())
+	result.SubjectType = inferSubjectType(infoboxes)
+	if e.Options.InferSubjectType && result.SubjectType != "" {
+		e.appendQuads(result, []Quad{{
+			Subject:      title,
+			Relationship: TypeRelationship,
+			Value:        result.SubjectType,
+			ValueType:    ValueTypeSchemaType,
+		}})
+	}
+	if e.Options.ShortDescription && result.ShortDescription != "" {
+		e.appendQuads(result, []Quad{{
+			Subject:      title,
+			Relationship: DescriptionRelationship,
+			Value:        result.ShortDescription,
+		}})
+	}
+	infoboxes.EachWithBreak(func(i int, s *goquery.Selection) bool {
+		infoboxQuads, skipped := e.parseInfobox(s, title, references)
+		result.SkippedRows += skipped
+		return e.appendQuads(result, infoboxQuads)
+	})
 
-		// Find and parse infoboxes
-		doc.Find(".infobox").Each(func(i int, s *goquery.Selection) {
-			infoboxQuads := e.parseInfobox(s, title, references)
-			quads = append(quads, infoboxQuads...)
+	// Coordinates from the infoboxes and title bar, one quad per location
+	if !result.Truncated {
+		e.appendQuads(result, e.parseCoordinates(doc, title, references))
+	}
+
+	// Find and parse other structured data tables. Succession boxes are
+	// styled as wikitables but read separately below.
+	tables := doc.Find("table.wikitable").Not(".succession-box")
+	if !result.Truncated {
+		tables.EachWithBreak(func(i int, s *goquery.Selection) bool {
+			var tableQuads []Quad
+			ok := false
+			if e.Options.TypedTables {
+				tableQuads, ok = e.parseTypedTable(s, references)
+			}
+			if !ok {
+				tableQuads = e.parseTable(s, title, references)
+			}
+			return e.appendQuads(result, tableQuads)
 		})
+	}
 
-		// Find and parse other structured data tables
-		doc.Find("table.wikitable").Each(func(i int, s *goquery.Selection) {
-			tableQuads := e.parseTable(s, title, references)
-			quads = append(quads, tableQuads...)
+	successionBoxes := doc.Find("table.succession-box")
+	if !result.Truncated {
+		successionBoxes.EachWithBreak(func(i int, s *goquery.Selection) bool {
+			return e.appendQuads(result, e.parseSuccessionBox(s, title))
 		})
-	})
+	}
 
-	err := e.colly.Visit(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to visit URL: %w", err)
+	noStructure := infoboxes.Length() == 0 && tables.Length() == 0 && successionBoxes.Length() == 0
+	if !noStructure && infoboxes.Length() == 0 && len(result.Quads) == 0 {
+		return nil, ErrNoInfobox
+	}
+	result.UnresolvedCitations = countUnresolvedCitations(infoboxes.AddSelection(tables), referenceIDs(doc))
+	if err := e.checkCitations(result); err != nil {
+		return nil, err
+	}
+
+	e.canonicalizeRelationships(result.Quads)
+	result.setSubjectURL(result.SubjectURL)
+	if result.SubjectURL != "" {
+		resolveValueURLs(result.SubjectURL, result.Quads)
+	}
+	if e.Options.Subject != "" {
+		result.setSubject(e.Options.Subject)
+	}
+	if e.Options.NormalizeNumbers {
+		normalizeNumbers(result.Quads, pageLanguage(doc))
+	}
+	if e.Options.SplitUnits {
+		splitMeasurements(result.Quads)
+	}
+	e.limitLengths(result)
+
+	if len(e.Options.IncludeRelationships) > 0 || len(e.Options.ExcludeRelationships) > 0 {
+		result.Quads = FilterRelationships(result.Quads, e.Options.IncludeRelationships, e.Options.ExcludeRelationships)
+	}
+	if e.Options.OnlyWithCitations {
+		result.Quads = RequireCitation(result.Quads)
+	}
+	e.validateValues(result, pageLanguage(doc))
+	if e.Options.FirstOnly {
+		result.Quads = KeepFirstPerRelationship(result.Quads)
+	}
+
+	// Checked last, so that quads from the title bar coordinates or the
+	// short description still go through the steps above
+	if noStructure && len(result.Quads) == 0 {
+		if e.Options.Strict {
+			return nil, ErrNoStructuredData
+		}
+		result.NoStructuredData = true
 	}
 
-	return quads, nil
+	return result, nil
 }
 
-// parseInfobox extracts quads from a Wikipedia infobox
-func (e *Extractor) parseInfobox(infobox *goquery.Selection, subject string, references map[string]string) []Quad {
-	var quads []Quad
+// infoboxSelector returns Options.InfoboxSelector, or DefaultInfoboxSelector
+// when it is empty
+func (e *Extractor) infoboxSelector() string {
+	if e.Options.InfoboxSelector == "" {
+		return DefaultInfoboxSelector
+	}
+	return e.Options.InfoboxSelector
+}
+
+// appendQuads adds quads to the result while respecting Options.MaxQuads. It
+// returns false once quads had to be dropped so callers can stop parsing.
+func (e *Extractor) appendQuads(result *ExtractResult, quads []Quad) bool {
+	limit := e.Options.MaxQuads
+	if limit <= 0 {
+		result.Quads = append(result.Quads, quads...)
+		return true
+	}
 
-	infobox.Find("tr").Each(func(i int, s *goquery.Selection) {
+	remaining := limit - len(result.Quads)
+	if len(quads) > remaining {
+		result.Quads = append(result.Quads, quads[:remaining]...)
+		result.Truncated = true
+		return false
+	}
+
+	result.Quads = append(result.Quads, quads...)
+	return true
+}
+
+// parseInfobox extracts quads from a Wikipedia infobox. It also returns the
+// number of rows skipped for having only a label or only a value.
+func (e *Extractor) parseInfobox(infobox *goquery.Selection, subject string, references map[string]string) ([]Quad, int) {
+	// Alternate names shown under the title come first
+	quads := e.parseHeaderAliases(infobox, subject, references)
+
+	// Table rows, plus div-based rows whose label sits outside any table row
+	rows := infobox.Find("tr").AddSelection(
+		infobox.Find(".infobox-label").FilterFunction(func(i int, s *goquery.Selection) bool {
+			return s.Closest("tr").Length() == 0
+		}).Parent(),
+	)
+
+	skipped := 0
+	rows.Each(func(i int, s *goquery.Selection) {
 		// Skip header rows
 		if s.HasClass("infobox-header") || s.HasClass("infobox-subheader") {
 			return
 		}
 
 		// Extract label and value
-		label := strings.TrimSpace(s.Find("th").Text())
-		valueCell := s.Find("td")
+		label, valueCell := infoboxRowCells(s)
 		value := strings.TrimSpace(valueCell.Text())
 
+		// Coordinates are extracted in normalized form by parseCoordinates
+		if valueCell.Find(".geo").Length() > 0 {
+			return
+		}
+
+		// Rows with neither are spacers or images; only half-filled rows hint at a parser gap
+		if (label == "") != (value == "") {
+			skipped++
+			e.logSkippedRow(s, label, value)
+			return
+		}
+
 		if label != "" && value != "" {
 			// Extract citations from the value cell
 			citations := e.extractCitations(valueCell, references)
@@ -95,74 +798,339 @@ func (e *Extractor) parseInfobox(infobox *goquery.Selection, subject string, ref
 				Value:       value,
 				Citation:    citations,
 			}
+
+			// Rows such as "Other names" or "Native name" describe the subject itself
+			if isAliasLabel(label) {
+				quad.Relationship = AliasRelationship
+				quad.Language = valueLanguage(valueCell)
+			}
+			quad.RawHTML = e.rawHTML(valueCell)
+			if e.Options.CountryCodes {
+				setCountryCodes(&quad, valueCell)
+			}
+
 			quads = append(quads, quad)
 		}
 	})
 
+	return quads, skipped
+}
+
+// maxSkippedRowHTML bounds the HTML snippet logged for a skipped row
+const maxSkippedRowHTML = 300
+
+// logSkippedRow reports an infobox row skipped by parseInfobox to Options.Logger
+func (e *Extractor) logSkippedRow(row *goquery.Selection, label, value string) {
+	if e.Options.Logger == nil {
+		return
+	}
+
+	html, err := goquery.OuterHtml(row)
+	if err != nil {
+		html = ""
+	}
+	html = strings.Join(strings.Fields(html), " ")
+	if len(html) > maxSkippedRowHTML {
+		html = strings.ToValidUTF8(html[:maxSkippedRowHTML], "") + "…"
+	}
+
+	reason := "label without value"
+	if label == "" {
+		reason = "value without label"
+	}
+	e.Options.Logger.Warn("skipped infobox row",
+		slog.String("reason", reason),
+		slog.String("label", label),
+		slog.String("value", value),
+		slog.String("html", html),
+	)
+}
+
+// infoboxRowCells returns the label and value cell of an infobox row. Most
+// rows use a th label and a td value, but some templates put both in one cell
+// or use .infobox-label/.infobox-data elements instead, so those layouts are
+// tried when the th/td pair doesn't yield both a label and a value.
+func infoboxRowCells(row *goquery.Selection) (string, *goquery.Selection) {
+	label := strings.TrimSpace(row.Find("th").Text())
+	valueCell := row.Find("td")
+	if label != "" && strings.TrimSpace(valueCell.Text()) != "" {
+		return label, valueCell
+	}
+
+	classLabel := strings.TrimSpace(row.Find(".infobox-label").First().Text())
+	classValue := row.Find(".infobox-data").First()
+	if classLabel != "" && strings.TrimSpace(classValue.Text()) != "" {
+		return classLabel, classValue
+	}
+
+	if cells := row.ChildrenFiltered("th, td"); cells.Length() == 1 && cells.Is("td") {
+		if boldLabel, boldValue, ok := splitLeadingLabel(cells); ok {
+			return boldLabel, boldValue
+		}
+	}
+
+	return label, valueCell
+}
+
+// splitLeadingLabel splits a cell holding both the label and the value of a
+// row, such as <td><b>Founded:</b> 1900</td>, where the label is the bold
+// element the cell starts with. It returns the label without its colon and a
+// detached copy of the cell holding the rest.
+func splitLeadingLabel(cell *goquery.Selection) (string, *goquery.Selection, bool) {
+	value := cell.Clone()
+	contents := value.Contents()
+	first := -1
+	contents.EachWithBreak(func(i int, s *goquery.Selection) bool {
+		if goquery.NodeName(s) == "#text" && strings.TrimSpace(s.Text()) == "" {
+			return true
+		}
+		first = i
+		return false
+	})
+	if first < 0 || !contents.Eq(first).Is("b, strong") {
+		return "", nil, false
+	}
+
+	bold := contents.Eq(first)
+	label := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(bold.Text()), ":"))
+	if label == "" {
+		return "", nil, false
+	}
+	bold.Remove()
+
+	// A colon may follow the label outside the bold element
+	if next := contents.Eq(first + 1); next.Length() > 0 && goquery.NodeName(next) == "#text" {
+		node := next.Get(0)
+		if rest, found := strings.CutPrefix(strings.TrimLeft(node.Data, " \t\n"), ":"); found {
+			node.Data = rest
+		}
+	}
+
+	return label, value, true
+}
+
+// aliasLabels are infobox labels whose values are alternate names of the subject
+var aliasLabels = map[string]bool{
+	"other names":       true,
+	"other name":        true,
+	"also known as":     true,
+	"native name":       true,
+	"alternative names": true,
+	"alternative name":  true,
+	"alternate names":   true,
+	"nickname":          true,
+	"nicknames":         true,
+}
+
+// isAliasLabel reports whether an infobox label introduces alternate names
+func isAliasLabel(label string) bool {
+	label = strings.ToLower(strings.TrimSpace(label))
+	label = strings.TrimSuffix(label, "(s)")
+	return aliasLabels[label]
+}
+
+// parseHeaderAliases extracts alternate names from the title and sub-header
+// rows of an infobox, such as native-language spellings and nicknames
+func (e *Extractor) parseHeaderAliases(infobox *goquery.Selection, subject string, references map[string]string) []Quad {
+	var quads []Quad
+	seen := map[string]bool{strings.TrimSpace(subject): true}
+
+	infobox.Find(".infobox-above, .infobox-subheader").Find(".nickname, [lang]").Each(func(i int, s *goquery.Selection) {
+		alias := strings.TrimSpace(s.Text())
+		if alias == "" || seen[alias] {
+			return
+		}
+		seen[alias] = true
+
+		quads = append(quads, Quad{
+			Subject:      subject,
+			Relationship: AliasRelationship,
+			Value:        alias,
+			Citation:     e.extractCitations(s, references),
+			Language:     valueLanguage(s),
+			RawHTML:      e.rawHTML(s),
+		})
+	})
+
 	return quads
 }
 
+// rawHTML returns the inner HTML of a value cell when Options.KeepHTML is
+// enabled, and an empty string otherwise
+func (e *Extractor) rawHTML(cell *goquery.Selection) string {
+	if !e.Options.KeepHTML {
+		return ""
+	}
+	html, err := cell.Html()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(html)
+}
+
+// valueLanguage returns the language tag of a value from its own or a descendant's lang attribute
+func valueLanguage(s *goquery.Selection) string {
+	if lang, exists := s.Attr("lang"); exists {
+		return lang
+	}
+	if lang, exists := s.Find("[lang]").First().Attr("lang"); exists {
+		return lang
+	}
+	return ""
+}
+
 // parseTable extracts quads from a Wikipedia table
 func (e *Extractor) parseTable(table *goquery.Selection, subject string, references map[string]string) []Quad {
 	var quads []Quad
+	section := sectionHeading(table)
 
-	table.Find("tr").Each(func(i int, s *goquery.Selection) {
-		cells := s.Find("td, th")
-		if cells.Length() >= 2 {
-			label := strings.TrimSpace(cells.Eq(0).Text())
-			valueCell := cells.Eq(1)
-			value := strings.TrimSpace(valueCell.Text())
-
-			if label != "" && value != "" {
-				// Extract citations from the value cell
-				citations := e.extractCitations(valueCell, references)
-				
-				quad := Quad{
-					Subject:     subject,
-					Relationship: label,
-					Value:       value,
-					Citation:    citations,
-				}
-				quads = append(quads, quad)
+	for _, row := range buildTableGrid(table) {
+		if len(row) < 2 || row[0] == nil || row[1] == nil {
+			continue
+		}
+
+		// A single cell spanning both columns (e.g. a section header) is not a label/value pair
+		if row[0].IsSelection(row[1]) {
+			continue
+		}
+
+		label := strings.TrimSpace(row[0].Text())
+		valueCell := row[1]
+		value := strings.TrimSpace(valueCell.Text())
+
+		if label != "" && value != "" {
+			// Extract citations from the value cell
+			citations := e.extractCitations(valueCell, references)
+
+			quad := Quad{
+				Subject:     subject,
+				Relationship: label,
+				Value:       value,
+				Citation:    citations,
+				Section:     section,
 			}
+			quad.RawHTML = e.rawHTML(valueCell)
+			quads = append(quads, quad)
 		}
-	})
+	}
 
 	return quads
 }
 
-// extractCitations extracts citation links by following named anchors to the references section
-func (e *Extractor) extractCitations(cell *goquery.Selection, references map[string]string) string {
-	var citations []string
-	citationMap := make(map[string]bool)
-	
-	// Find all citation links in the cell
-	cell.Find("a[href*='#cite_note']").Each(func(i int, s *goquery.Selection) {
-		if href, exists := s.Attr("href"); exists {
-			// Extract the citation ID from the href
-			if strings.Contains(href, "#cite_note-") {
-				citationID := strings.TrimPrefix(href, "#cite_note-")
-				// Look up the actual citation from the references map
-				referenceKey := "cite_note-" + citationID
-				if actualCitation, exists := references[referenceKey]; exists {
-					if !citationMap[actualCitation] {
-						citationMap[actualCitation] = true
-						citations = append(citations, actualCitation)
-					}
+// sectionHeading returns the text of the nearest heading preceding an element
+// in the article body, or an empty string if it is in the lead section
+func sectionHeading(s *goquery.Selection) string {
+	// Walk up to the element's top-level block within the article body
+	top := s
+	if parents := s.ParentsUntil(".mw-parser-output"); parents.Length() > 0 && parents.Last().Parent().Is(".mw-parser-output") {
+		top = parents.Last()
+	}
+
+	heading := top.PrevAllFiltered("h2, h3, h4, div.mw-heading").First()
+	if heading.Length() == 0 {
+		return ""
+	}
+
+	// Older markup wraps the title in .mw-headline next to [edit] links
+	if headline := heading.Find(".mw-headline"); headline.Length() > 0 {
+		return strings.TrimSpace(headline.Text())
+	}
+	if inner := heading.Find("h2, h3, h4"); inner.Length() > 0 {
+		return strings.TrimSpace(inner.First().Text())
+	}
+	return strings.TrimSpace(heading.Text())
+}
+
+// buildTableGrid expands a table into a normalized grid of cells. Cells with a
+// rowspan or colspan are repeated in every grid position they cover, so that
+// column N of each row always refers to the same logical column.
+func buildTableGrid(table *goquery.Selection) [][]*goquery.Selection {
+	// Only consider rows that belong to this table, not to nested tables
+	rows := table.Find("tr").FilterFunction(func(i int, tr *goquery.Selection) bool {
+		return tr.Closest("table").IsSelection(table)
+	})
+
+	grid := make([][]*goquery.Selection, rows.Length())
+
+	rows.Each(func(r int, tr *goquery.Selection) {
+		col := 0
+		tr.ChildrenFiltered("th, td").Each(func(i int, cell *goquery.Selection) {
+			// Skip positions already filled by a rowspan from a previous row
+			for col < len(grid[r]) && grid[r][col] != nil {
+				col++
+			}
+
+			rowspan := spanAttr(cell, "rowspan")
+			colspan := spanAttr(cell, "colspan")
+
+			// rowspan="0" extends the cell to the end of the table
+			if rowspan == 0 || r+rowspan > len(grid) {
+				rowspan = len(grid) - r
+			}
+
+			for dr := 0; dr < rowspan; dr++ {
+				for dc := 0; dc < colspan; dc++ {
+					setGridCell(&grid[r+dr], col+dc, cell)
 				}
 			}
-		}
+			col += colspan
+		})
 	})
+
+	return grid
+}
+
+// setGridCell stores a cell at the given column, growing the row as needed
+func setGridCell(row *[]*goquery.Selection, col int, cell *goquery.Selection) {
+	for len(*row) <= col {
+		*row = append(*row, nil)
+	}
+	if (*row)[col] == nil {
+		(*row)[col] = cell
+	}
+}
+
+// maxSpan bounds rowspan/colspan values to guard against malformed markup
+const maxSpan = 1000
+
+// spanAttr returns the numeric value of a rowspan/colspan attribute, defaulting to 1.
+// A rowspan of 0 is returned as-is since it means "span the remaining rows".
+func spanAttr(cell *goquery.Selection, name string) int {
+	raw, exists := cell.Attr(name)
+	if !exists {
+		return 1
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || n < 0 {
+		return 1
+	}
+	if n == 0 && name != "rowspan" {
+		return 1
+	}
+	if n > maxSpan {
+		return maxSpan
+	}
+
+	return n
+}
+
+// extractCitations extracts citation links by following named anchors to the
+// references section. Citations are de-duplicated and sorted so the result is
+// stable across runs, then formatted by formatCitations.
+func (e *Extractor) extractCitations(cell *goquery.Selection, references map[string]string) string {
+	var citations []string
+	citationMap := make(map[string]bool)
 	
-	// Also look for superscript citation links
-	cell.Find("sup a").Each(func(i int, s *goquery.Selection) {
+	// Find all citation links in the cell, including superscript footnote links
+	cell.Find("a[href*='#cite_note'], sup a").Each(func(i int, s *goquery.Selection) {
 		if href, exists := s.Attr("href"); exists {
 			// Extract the citation ID from the href
-			if strings.Contains(href, "#cite_note-") {
-				citationID := strings.TrimPrefix(href, "#cite_note-")
+			if referenceKey := citationKey(href); referenceKey != "" {
 				// Look up the actual citation from the references map
-				referenceKey := "cite_note-" + citationID
-				if actualCitation, exists := references[referenceKey]; exists {
+				actualCitation, exists := lookupReference(references, referenceKey)
+				if exists {
+					actualCitation = strings.TrimSpace(actualCitation)
 					if !citationMap[actualCitation] {
 						citationMap[actualCitation] = true
 						citations = append(citations, actualCitation)
@@ -172,45 +1140,164 @@ func (e *Extractor) extractCitations(cell *goquery.Selection, references map[str
 		}
 	})
 	
-	// If no citations found, return "no citation"
+	// Leave the citation empty when none was found
 	if len(citations) == 0 {
-		return "no citation"
+		return ""
 	}
 	
-	return strings.Join(citations, "; ")
+	return e.formatCitations(citations)
 }
 
-// extractReferences extracts all references from the references section
+// formatCitations sorts citations and joins them with Options.CitationSeparator,
+// keeping at most Options.MaxCitations followed by an "… and N more" suffix
+func (e *Extractor) formatCitations(citations []string) string {
+	sort.Strings(citations)
+	
+	separator := e.Options.CitationSeparator
+	if separator == "" {
+		separator = DefaultCitationSeparator
+	}
+	
+	limit := e.Options.MaxCitations
+	if limit <= 0 || len(citations) <= limit {
+		return strings.Join(citations, separator)
+	}
+	
+	return fmt.Sprintf("%s… and %d more", strings.Join(citations[:limit], separator)+separator, len(citations)-limit)
+}
+
+// extractReferences maps the IDs of the page's reference list items, such
+// as "cite_note-NYT-3", to the external link they cite. A note without a link
+// of its own, such as an explanatory footnote [a] that cites other references,
+// takes the link of the first reference it cites, following notes that cite
+// notes at most Options.MaxCitationDepth levels deep. Named references are
+// also recorded under their base name, e.g. "cite_note-NYT", for citations
+// whose numbering doesn't match the list.
 func (e *Extractor) extractReferences(doc *goquery.Selection) map[string]string {
 	references := make(map[string]string)
+	// notes maps the IDs of list items without an external link to the
+	// references they cite
+	notes := make(map[string][]string)
 	
 	// Find the references section - Wikipedia uses various selectors
-	doc.Find("#References, #references, .reflist, .references").Each(func(i int, s *goquery.Selection) {
-		// Find all reference list items
-		s.Find("li").Each(func(j int, li *goquery.Selection) {
-			// Extract the reference ID
-			if id, exists := li.Attr("id"); exists {
-				// Look for external links in the reference
-				li.Find("a[href^='http']").Each(func(k int, a *goquery.Selection) {
-					if href, exists := a.Attr("href"); exists {
-						references[id] = href
-					}
-				})
+	doc.Find(referenceListSelector).Find("li[id]").Each(func(i int, li *goquery.Selection) {
+		id := li.AttrOr("id", "")
+		// Look for external links in the reference
+		li.Find("a[href^='http']").Each(func(k int, a *goquery.Selection) {
+			references[id] = a.AttrOr("href", "")
+		})
+		if _, linked := references[id]; linked {
+			return
+		}
+		li.Find("a[href*='#cite_note-']").Each(func(k int, a *goquery.Selection) {
+			if target := citationKey(a.AttrOr("href", "")); target != "" && target != id {
+				notes[id] = append(notes[id], target)
 			}
 		})
 	})
 	
-	// Also look for cite_note references
-	doc.Find("ol.references li").Each(func(i int, li *goquery.Selection) {
-		if id, exists := li.Attr("id"); exists {
-			// Look for external links in the reference
-			li.Find("a[href^='http']").Each(func(k int, a *goquery.Selection) {
-				if href, exists := a.Attr("href"); exists {
-					references[id] = href
-				}
-			})
+	depth := e.Options.MaxCitationDepth
+	if depth <= 0 {
+		depth = DefaultCitationDepth
+	}
+	// Resolve every note against the linked references alone, so the result
+	// doesn't depend on the order notes are resolved in
+	resolved := make(map[string]string)
+	for id := range notes {
+		if href := resolveNote(id, references, notes, depth, make(map[string]bool)); href != "" {
+			resolved[id] = href
 		}
+	}
+	for id, href := range resolved {
+		references[id] = href
+	}
+	
+	// Base names are added in order so the lowest numbered reference wins
+	ids := make([]string, 0, len(references))
+	for id := range references {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return referenceLess(ids[i], ids[j])
 	})
+	for _, id := range ids {
+		base := citationBaseName(id)
+		if _, exists := references[base]; !exists {
+			references[base] = references[id]
+		}
+	}
 	
 	return references
+}
+
+// resolveNote returns the link of the first reference cited by a note,
+// following notes that cite other notes up to depth levels deep. Notes
+// already being followed are skipped, so citation cycles end.
+func resolveNote(id string, references map[string]string, notes map[string][]string, depth int, following map[string]bool) string {
+	if depth <= 0 || following[id] {
+		return ""
+	}
+	following[id] = true
+	defer delete(following, id)
+	
+	for _, target := range notes[id] {
+		if href, ok := references[target]; ok {
+			return href
+		}
+		if href := resolveNote(target, references, notes, depth-1, following); href != "" {
+			return href
+		}
+	}
+	return ""
+}
+
+// citationKey returns the reference list item ID a citation link points to,
+// e.g. "cite_note-NYT-3" for "#cite_note-NYT-3", or "" for other links
+func citationKey(href string) string {
+	_, fragment, found := strings.Cut(href, "#")
+	if !found || !strings.HasPrefix(fragment, "cite_note-") {
+		return ""
+	}
+	return fragment
+}
+
+// lookupReference returns the citation recorded for a reference ID, falling
+// back to the base name of a named reference
+func lookupReference(references map[string]string, key string) (string, bool) {
+	if citation, ok := references[key]; ok {
+		return citation, true
+	}
+	citation, ok := references[citationBaseName(key)]
+	return citation, ok
+}
+
+// referenceLess orders reference IDs by base name, then by the number
+// appended to it, so that "cite_note-NYT-3" comes before "cite_note-NYT-10"
+func referenceLess(a, b string) bool {
+	baseA, baseB := citationBaseName(a), citationBaseName(b)
+	if baseA != baseB {
+		return baseA < baseB
+	}
+	numberA, _ := strconv.Atoi(strings.TrimPrefix(a, baseA+"-"))
+	numberB, _ := strconv.Atoi(strings.TrimPrefix(b, baseB+"-"))
+	if numberA != numberB {
+		return numberA < numberB
+	}
+	return a < b
+}
+
+// citationBaseName strips the number Wikipedia appends to the IDs of named
+// references, turning "cite_note-NYT-3" into "cite_note-NYT". Unnamed
+// references such as "cite_note-5" are returned as they are.
+func citationBaseName(id string) string {
+	i := strings.LastIndex(id, "-")
+	if i <= len("cite_note") || i == len(id)-1 {
+		return id
+	}
+	for _, r := range id[i+1:] {
+		if r < '0' || r > '9' {
+			return id
+		}
+	}
+	return id[:i]
 } 
\ No newline at end of file
@@ -0,0 +1,192 @@
+package extractor
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strconv"
+	"testing"
+)
+
+// newFixtureServer serves the pages in testdata under /wiki/, e.g.
+// testdata/references.html as /wiki/references.html, and returns an
+// extractor allowed to fetch from it
+func newFixtureServer(t *testing.T) (*httptest.Server, *Extractor) {
+	t.Helper()
+	server := httptest.NewServer(http.StripPrefix("/wiki/", http.FileServer(http.Dir("testdata"))))
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := NewExtractor()
+	e.Options.AllowedHosts = []string{u.Host}
+	return server, e
+}
+
+func TestExtractInfobox(t *testing.T) {
+	server, e := newFixtureServer(t)
+	pageURL := server.URL + "/wiki/native_name.html"
+
+	result, err := e.Extract(pageURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Title != "Taro Example" {
+		t.Errorf("Title = %q, want %q", result.Title, "Taro Example")
+	}
+	if result.SubjectURL != "https://en.wikipedia.org/wiki/Taro_Example" {
+		t.Errorf("SubjectURL = %q, want the canonical URL", result.SubjectURL)
+	}
+	occupation, ok := findQuad(result.Quads, "Occupation")
+	if !ok || occupation.Value != "Potter" {
+		t.Fatalf("Occupation quad = %+v, want value Potter", occupation)
+	}
+	for _, q := range result.Quads {
+		if q.Source != pageURL {
+			t.Errorf("%s: Source = %q, want %q", q.Relationship, q.Source, pageURL)
+		}
+		if q.SubjectURL != result.SubjectURL {
+			t.Errorf("%s: SubjectURL = %q, want %q", q.Relationship, q.SubjectURL, result.SubjectURL)
+		}
+	}
+}
+
+func TestExtractTable(t *testing.T) {
+	server, e := newFixtureServer(t)
+	e.Options.Relationships = nil
+
+	quads, err := e.ExtractFromURL(server.URL + "/wiki/spanned_table.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var districts []string
+	for _, q := range quads {
+		if q.Relationship == "Districts" {
+			districts = append(districts, q.Value)
+		}
+	}
+	want := []string{"Old Town[1]", "Harbour", "Hillside"}
+	if len(districts) != len(want) {
+		t.Fatalf("Districts = %q, want %q", districts, want)
+	}
+	for i := range want {
+		if districts[i] != want[i] {
+			t.Errorf("Districts = %q, want %q", districts, want)
+		}
+	}
+}
+
+func TestExtractReferences(t *testing.T) {
+	server, e := newFixtureServer(t)
+	e.Options.Relationships = nil
+
+	result, err := e.Extract(server.URL + "/wiki/references.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const (
+		rivers = "https://rivers.example.org/bridges"
+		survey = "https://survey.example.org/1911"
+		firm   = "https://firms.example.org/example-co"
+	)
+	assertQuads(t, result.Quads, []quadFields{
+		{"Sample Bridge", "Crosses", "Examplia River[1]", rivers},
+		// Several citations are sorted and joined
+		{"Sample Bridge", "Total length", "412 m[2][1]", rivers + "; " + survey},
+		// A named reference whose number doesn't match the list
+		{"Sample Bridge", "Opened", "1 May 1911[2]", survey},
+		// An explanatory note takes the link of the reference it cites
+		{"Sample Bridge", "Designer", "Ann Example[a]", firm},
+		// A marker linking to no reference leaves the quad uncited
+		{"Sample Bridge", "Toll", "None[9]", ""},
+	})
+	if result.UnresolvedCitations != 1 {
+		t.Errorf("UnresolvedCitations = %d, want 1", result.UnresolvedCitations)
+	}
+}
+
+func TestExtractErrors(t *testing.T) {
+	server, e := newFixtureServer(t)
+
+	tests := []struct {
+		name string
+		url  string
+		want error
+	}{
+		{"missing page", server.URL + "/wiki/missing.html", ErrNotFound},
+		{"disambiguation page", server.URL + "/wiki/disambiguation.html", ErrDisambiguation},
+		{"host not allowed", "https://example.com/wiki/native_name.html", ErrInvalidURL},
+		{"unsupported scheme", "ftp://" + server.Listener.Addr().String() + "/wiki/native_name.html", ErrInvalidURL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := e.Extract(tt.url)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Extract error = %v, want %v", err, tt.want)
+			}
+			if result != nil {
+				t.Errorf("Extract returned a result along with error %v", err)
+			}
+		})
+	}
+
+	// A server that can't be reached is a fetch failure, not a missing page
+	server.Close()
+	_, err := e.Extract(server.URL + "/wiki/native_name.html")
+	if !errors.Is(err, ErrFetchFailed) || errors.Is(err, ErrNotFound) {
+		t.Errorf("Extract from a closed server: error = %v, want ErrFetchFailed", err)
+	}
+}
+
+func TestExtractStatusErrors(t *testing.T) {
+	server, e := newFixtureServer(t)
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, err := strconv.Atoi(path.Base(r.URL.Path))
+		if err != nil {
+			t.Errorf("unexpected request for %s", r.URL.Path)
+			status = http.StatusTeapot
+		}
+		http.Error(w, http.StatusText(status), status)
+	})
+
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusServiceUnavailable, ErrUnavailable},
+		{http.StatusBadGateway, ErrUnavailable},
+		{http.StatusGatewayTimeout, ErrUnavailable},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusGone, ErrNotFound},
+		{http.StatusInternalServerError, ErrFetchFailed},
+		{http.StatusForbidden, ErrFetchFailed},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.status), func(t *testing.T) {
+			pageURL := server.URL + "/wiki/" + strconv.Itoa(tt.status)
+			result, err := e.Extract(pageURL)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Extract error = %v, want %v", err, tt.want)
+			}
+			if result != nil {
+				t.Error("Extract returned a result along with the error")
+			}
+
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) {
+				t.Fatalf("error %v is not a StatusError", err)
+			}
+			if statusErr.StatusCode != tt.status || statusErr.URL != pageURL {
+				t.Errorf("StatusError = %d for %s, want %d for %s", statusErr.StatusCode, statusErr.URL, tt.status, pageURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package extractor
+
+import (
+	"slices"
+	"strings"
+)
+
+// HasCitation reports whether a quad cites a reference
+func HasCitation(q Quad) bool {
+	citation := strings.TrimSpace(q.Citation)
+	return citation != "" && citation != NoCitation
+}
+
+// RequireCitation returns the quads that cite a reference, dropping those
+// with an empty citation or the legacy NoCitation placeholder. The input slice is not modified.
+func RequireCitation(quads []Quad) []Quad {
+	var cited []Quad
+	for _, q := range quads {
+		if HasCitation(q) {
+			cited = append(cited, q)
+		}
+	}
+	return cited
+}
+
+// FilterRelationships returns the quads whose relationship is listed in
+// include, or all of them when include is empty, less those listed in
+// exclude. Relationships are compared ignoring case and extra whitespace. The
+// input slice is not modified.
+func FilterRelationships(quads []Quad, include, exclude []string) []Quad {
+	included := make(map[string]bool, len(include))
+	for _, relationship := range include {
+		included[normalizeLabel(relationship)] = true
+	}
+	excluded := make(map[string]bool, len(exclude))
+	for _, relationship := range exclude {
+		excluded[normalizeLabel(relationship)] = true
+	}
+
+	var kept []Quad
+	for _, q := range quads {
+		relationship := normalizeLabel(q.Relationship)
+		if (len(included) > 0 && !included[relationship]) || excluded[relationship] {
+			continue
+		}
+		kept = append(kept, q)
+	}
+	return kept
+}
+
+// KeepFirstPerRelationship returns, for each subject, only the first quad of
+// every relationship, flattening multi-valued rows into one value each. Order
+// is preserved and the input slice is not modified.
+func KeepFirstPerRelationship(quads []Quad) []Quad {
+	type key struct{ subject, relationship string }
+	seen := make(map[key]bool)
+	var first []Quad
+	for _, q := range quads {
+		k := key{q.Subject, q.Relationship}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		first = append(first, q)
+	}
+	return first
+}
+
+// DedupeQuads returns quads without exact duplicates, keeping the first quad
+// of each subject, relationship and value. Order is preserved and the input
+// slice is not modified.
+func DedupeQuads(quads []Quad) []Quad {
+	type key struct{ subject, relationship, value string }
+	seen := make(map[key]bool)
+	var kept []Quad
+	for _, q := range quads {
+		k := key{q.Subject, q.Relationship, q.Value}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		kept = append(kept, q)
+	}
+	return kept
+}
+
+// MergeSources collapses quads with the same subject, relationship and value
+// into the first of them, listing the distinct sources of all of them in
+// Sources. The merged quad keeps the first citation found among them. Order
+// is preserved and the input slice is not modified.
+func MergeSources(quads []Quad) []Quad {
+	type key struct{ subject, relationship, value string }
+	index := make(map[key]int)
+	var merged []Quad
+	for _, q := range quads {
+		k := key{q.Subject, q.Relationship, q.Value}
+		i, seen := index[k]
+		if !seen {
+			index[k] = len(merged)
+			q.Sources = nil
+			if q.Source != "" {
+				q.Sources = []string{q.Source}
+			}
+			merged = append(merged, q)
+			continue
+		}
+		m := &merged[i]
+		if q.Source != "" && !slices.Contains(m.Sources, q.Source) {
+			m.Sources = append(m.Sources, q.Source)
+		}
+		if !HasCitation(*m) && HasCitation(q) {
+			m.Citation = q.Citation
+		}
+	}
+	return merged
+}
@@ -0,0 +1,83 @@
+package extractor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// loadFixture parses a saved page from testdata
+func loadFixture(t testing.TB, name string) *goquery.Document {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		t.Fatalf("parsing %s: %v", name, err)
+	}
+	return doc
+}
+
+// parseFixture runs ParseDocument over a saved page from testdata
+func parseFixture(t testing.TB, e *Extractor, name string) *ExtractResult {
+	t.Helper()
+	result, err := e.ParseDocument(loadFixture(t, name).Selection)
+	if err != nil {
+		t.Fatalf("ParseDocument(%s): %v", name, err)
+	}
+	return result
+}
+
+// quadFields is the part of a quad most tests check
+type quadFields struct {
+	Subject      string
+	Relationship string
+	Value        string
+	Citation     string
+}
+
+// fieldsOf returns the quadFields of each quad
+func fieldsOf(quads []Quad) []quadFields {
+	fields := make([]quadFields, len(quads))
+	for i, q := range quads {
+		fields[i] = quadFields{q.Subject, q.Relationship, q.Value, q.Citation}
+	}
+	return fields
+}
+
+// assertQuads fails the test unless quads match want in order
+func assertQuads(t testing.TB, quads []Quad, want []quadFields) {
+	t.Helper()
+	got := fieldsOf(quads)
+	if reflect.DeepEqual(got, want) {
+		return
+	}
+	t.Errorf("got %d quads, want %d", len(got), len(want))
+	for i := 0; i < len(got) || i < len(want); i++ {
+		switch {
+		case i >= len(got):
+			t.Errorf("  missing %+v", want[i])
+		case i >= len(want):
+			t.Errorf("  extra   %+v", got[i])
+		case got[i] != want[i]:
+			t.Errorf("  got     %+v\n  want    %+v", got[i], want[i])
+		}
+	}
+}
+
+// findQuad returns the first quad with the given relationship
+func findQuad(quads []Quad, relationship string) (Quad, bool) {
+	for _, q := range quads {
+		if q.Relationship == relationship {
+			return q, true
+		}
+	}
+	return Quad{}, false
+}
@@ -0,0 +1,64 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestInfoboxSingleCellRows(t *testing.T) {
+	e := NewExtractor()
+	e.Options.Relationships = nil
+	result := parseFixture(t, e, "single_cell_rows.html")
+
+	const subject = "Example Pottery Company"
+	assertQuads(t, result.Quads, []quadFields{
+		{subject, "Industry", "Pottery", ""},
+		{subject, "Founded", "12 May 1901", ""},
+		{subject, "Headquarters", "Exampleburg[1]", "https://companies.example.org/pottery"},
+		{subject, "Key people", "Ann Example (CEO)", ""},
+		{subject, "Products", "Vases, bowls", ""},
+	})
+	// The title row and the cell that doesn't start with its label are skipped
+	if result.SkippedRows != 2 {
+		t.Errorf("SkippedRows = %d, want 2", result.SkippedRows)
+	}
+}
+
+func TestSplitLeadingLabel(t *testing.T) {
+	tests := []struct {
+		html  string
+		label string
+		value string
+		ok    bool
+	}{
+		{`<td><b>Founded</b><br>1901</td>`, "Founded", "1901", true},
+		{`<td> <b>Founded:</b> 1901</td>`, "Founded", "1901", true},
+		{`<td><strong>Founded</strong> : 1901</td>`, "Founded", "1901", true},
+		{`<td><b>Awards</b></td>`, "Awards", "", true},
+		{`<td>Made in <b>Examplia</b></td>`, "", "", false},
+		{`<td><i>Founded</i> 1901</td>`, "", "", false},
+		{`<td><b> </b>1901</td>`, "", "", false},
+	}
+	for _, tt := range tests {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader("<table><tr>" + tt.html + "</tr></table>"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cell := doc.Find("td")
+		before, _ := goquery.OuterHtml(cell)
+		label, value, ok := splitLeadingLabel(cell)
+		if ok != tt.ok || label != tt.label {
+			t.Errorf("splitLeadingLabel(%s) = %q, %v, want %q, %v", tt.html, label, ok, tt.label, tt.ok)
+			continue
+		}
+		if ok && strings.TrimSpace(value.Text()) != tt.value {
+			t.Errorf("splitLeadingLabel(%s) value = %q, want %q", tt.html, value.Text(), tt.value)
+		}
+		// The page itself is left as it was
+		if html, _ := goquery.OuterHtml(cell); html != before {
+			t.Errorf("splitLeadingLabel modified the cell: %s", html)
+		}
+	}
+}
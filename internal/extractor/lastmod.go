@@ -0,0 +1,146 @@
+package extractor
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var (
+	// dateModifiedPattern matches the modification time in the page's JSON-LD metadata
+	dateModifiedPattern = regexp.MustCompile(`"dateModified"\s*:\s*"([^"]+)"`)
+
+	// footerTimePattern matches the time of day in a footer, e.g. "12:34" or "12 h 34"
+	footerTimePattern = regexp.MustCompile(`(\d{1,2})\s*(?::|h|\.)\s*(\d{2})`)
+
+	// cjkDatePattern matches dates written as 2024年1月9日, used by the Chinese and Japanese editions
+	cjkDatePattern = regexp.MustCompile(`(\d{4})\s*年\s*(\d{1,2})\s*月\s*(\d{1,2})\s*日`)
+
+	// footerNumberPattern matches the numbers left in a footer once the time is removed
+	footerNumberPattern = regexp.MustCompile(`\d+`)
+)
+
+// monthNames maps month names and abbreviations used in the footers of the
+// larger Wikipedia editions to month numbers
+var monthNames = map[string]time.Month{
+	// English
+	"january": time.January, "february": time.February, "march": time.March, "april": time.April,
+	"may": time.May, "june": time.June, "july": time.July, "august": time.August,
+	"september": time.September, "october": time.October, "november": time.November, "december": time.December,
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"jun": time.June, "jul": time.July, "aug": time.August, "sep": time.September, "sept": time.September,
+	"oct": time.October, "nov": time.November, "dec": time.December,
+	// German
+	"januar": time.January, "februar": time.February, "märz": time.March, "mai": time.May,
+	"juni": time.June, "juli": time.July, "oktober": time.October, "dezember": time.December,
+	// French
+	"janvier": time.January, "février": time.February, "mars": time.March, "avril": time.April,
+	"juin": time.June, "juillet": time.July, "août": time.August, "septembre": time.September,
+	"octobre": time.October, "novembre": time.November, "décembre": time.December,
+	// Spanish
+	"enero": time.January, "febrero": time.February, "marzo": time.March, "abril": time.April,
+	"mayo": time.May, "junio": time.June, "julio": time.July, "agosto": time.August,
+	"septiembre": time.September, "setiembre": time.September, "octubre": time.October,
+	"noviembre": time.November, "diciembre": time.December,
+	"ene": time.January, "abr": time.April, "ago": time.August, "dic": time.December,
+	// Italian
+	"gennaio": time.January, "febbraio": time.February, "aprile": time.April, "maggio": time.May,
+	"giugno": time.June, "luglio": time.July, "settembre": time.September, "ottobre": time.October,
+	"dicembre": time.December,
+	// Portuguese
+	"janeiro": time.January, "fevereiro": time.February, "março": time.March, "maio": time.May,
+	"junho": time.June, "julho": time.July, "setembro": time.September, "outubro": time.October,
+	"novembro": time.November, "dezembro": time.December,
+	// Dutch
+	"januari": time.January, "februari": time.February, "maart": time.March, "mei": time.May,
+	"augustus": time.August,
+}
+
+// parsePageLastModified returns when the page was last edited. The JSON-LD
+// metadata gives an exact UTC timestamp; when it is missing, the footer's
+// "This page was last edited on ..." line is parsed best-effort, treating its
+// time as UTC. A zero time is returned when neither can be read.
+func parsePageLastModified(doc *goquery.Selection) time.Time {
+	var modified time.Time
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		if match := dateModifiedPattern.FindStringSubmatch(s.Text()); match != nil {
+			if t, err := time.Parse(time.RFC3339, match[1]); err == nil {
+				modified = t.UTC()
+			}
+		}
+		return modified.IsZero()
+	})
+	if !modified.IsZero() {
+		return modified
+	}
+
+	return parseFooterDate(doc.Find("#footer-info-lastmod").Text())
+}
+
+// parseFooterDate parses the date and time from a localized "last edited"
+// footer line such as "This page was last edited on 9 January 2024, at 12:34
+// (UTC)." or "Diese Seite wurde zuletzt am 9. Januar 2024 um 12:34 Uhr
+// bearbeitet."
+func parseFooterDate(text string) time.Time {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return time.Time{}
+	}
+
+	var hour, minute int
+	if match := footerTimePattern.FindStringSubmatchIndex(text); match != nil {
+		hour, _ = strconv.Atoi(text[match[2]:match[3]])
+		minute, _ = strconv.Atoi(text[match[4]:match[5]])
+		text = text[:match[0]] + " " + text[match[1]:]
+	}
+	if hour > 23 || minute > 59 {
+		hour, minute = 0, 0
+	}
+
+	if match := cjkDatePattern.FindStringSubmatch(text); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+		day, _ := strconv.Atoi(match[3])
+		return footerTime(year, time.Month(month), day, hour, minute)
+	}
+
+	var month time.Month
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if m, ok := monthNames[word]; ok {
+			month = m
+			break
+		}
+	}
+
+	var year, day int
+	for _, number := range footerNumberPattern.FindAllString(text, -1) {
+		n, _ := strconv.Atoi(number)
+		switch {
+		case len(number) == 4 && year == 0:
+			year = n
+		case len(number) <= 2 && day == 0:
+			day = n
+		}
+	}
+
+	return footerTime(year, month, day, hour, minute)
+}
+
+// footerTime builds a UTC time from footer date parts, returning the zero
+// time if any part is missing or out of range
+func footerTime(year int, month time.Month, day, hour, minute int) time.Time {
+	if year == 0 || month < time.January || month > time.December || day < 1 || day > 31 {
+		return time.Time{}
+	}
+
+	t := time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	if t.Day() != day {
+		// The day does not exist in that month, e.g. 31 February
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,56 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestPageLastModifiedFooter(t *testing.T) {
+	result := parseFixture(t, NewExtractor(), "last_modified.html")
+
+	want := time.Date(2024, time.January, 9, 12, 34, 0, 0, time.UTC)
+	if !result.PageLastModified.Equal(want) {
+		t.Errorf("PageLastModified = %s, want %s", result.PageLastModified, want)
+	}
+}
+
+func TestPageLastModifiedJSONLD(t *testing.T) {
+	// The exact timestamp in the metadata wins over the footer
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><head>
+<script type="application/ld+json">{"@context":"https://schema.org","@type":"Article","dateModified":"2024-01-09T12:34:56Z"}</script>
+</head><body><li id="footer-info-lastmod">This page was last edited on 1 March 2020, at 08:00 (UTC).</li></body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := time.Date(2024, time.January, 9, 12, 34, 56, 0, time.UTC)
+	if got := parsePageLastModified(doc.Selection); !got.Equal(want) {
+		t.Errorf("parsePageLastModified = %s, want %s", got, want)
+	}
+}
+
+func TestParseFooterDate(t *testing.T) {
+	tests := []struct {
+		footer string
+		want   time.Time
+	}{
+		{"This page was last edited on 9 January 2024, at 12:34 (UTC).", time.Date(2024, 1, 9, 12, 34, 0, 0, time.UTC)},
+		{"This page was last edited on January 9, 2024, at 12:34 (UTC).", time.Date(2024, 1, 9, 12, 34, 0, 0, time.UTC)},
+		{"Diese Seite wurde zuletzt am 9. März 2024 um 07:05 Uhr bearbeitet.", time.Date(2024, 3, 9, 7, 5, 0, 0, time.UTC)},
+		{"La dernière modification de cette page a été faite le 21 août 2023 à 18:02.", time.Date(2023, 8, 21, 18, 2, 0, 0, time.UTC)},
+		{"Esta página se editó por última vez el 3 dic 2022 a las 9:15.", time.Date(2022, 12, 3, 9, 15, 0, 0, time.UTC)},
+		{"Questa pagina è stata modificata per l'ultima volta il 5 maggio 2021 alle 10:00.", time.Date(2021, 5, 5, 10, 0, 0, 0, time.UTC)},
+		{"Deze pagina is het laatst bewerkt op 14 mei 2024 om 23:59.", time.Date(2024, 5, 14, 23, 59, 0, 0, time.UTC)},
+		{"このページの最終更新日時は 2024年1月9日 (火) 12:34 です。", time.Date(2024, 1, 9, 12, 34, 0, 0, time.UTC)},
+		{"This page was last edited on 31 February 2024, at 12:34 (UTC).", time.Time{}},
+		{"", time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := parseFooterDate(tt.footer); !got.Equal(tt.want) {
+			t.Errorf("parseFooterDate(%q) = %s, want %s", tt.footer, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package extractor
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// LengthPolicy says what happens to a quad whose value or citation is longer
+// than its limit
+type LengthPolicy string
+
+const (
+	// LengthPolicyTruncate cuts the field to the limit, ending it with an
+	// ellipsis. It is the default.
+	LengthPolicyTruncate LengthPolicy = "truncate"
+
+	// LengthPolicySkip drops the quad
+	LengthPolicySkip LengthPolicy = "skip"
+
+	// LengthPolicyKeep keeps the field whole, disabling the limits
+	LengthPolicyKeep LengthPolicy = "keep"
+)
+
+const (
+	// DefaultMaxValueLength is the value length limit, in characters, used
+	// when MaxValueLength is zero
+	DefaultMaxValueLength = 1000
+
+	// DefaultMaxCitationLength is the citation length limit, in characters,
+	// used when MaxCitationLength is zero
+	DefaultMaxCitationLength = 4000
+)
+
+// ParseLengthPolicy parses a length policy name, with "" meaning
+// LengthPolicyTruncate
+func ParseLengthPolicy(name string) (LengthPolicy, error) {
+	switch policy := LengthPolicy(strings.ToLower(strings.TrimSpace(name))); policy {
+	case "":
+		return LengthPolicyTruncate, nil
+	case LengthPolicyTruncate, LengthPolicySkip, LengthPolicyKeep:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown length policy %q (valid policies: truncate, skip, keep)", name)
+	}
+}
+
+// limitLengths applies Options.LengthPolicy to the quads whose value or
+// citation exceeds its limit. Truncated fields are listed in the quad's
+// TruncatedFields, and skipped quads are counted in SkippedLongQuads.
+func (e *Extractor) limitLengths(result *ExtractResult) {
+	policy := e.Options.LengthPolicy
+	if policy == LengthPolicyKeep {
+		return
+	}
+	maxValue := e.Options.MaxValueLength
+	if maxValue <= 0 {
+		maxValue = DefaultMaxValueLength
+	}
+	maxCitation := e.Options.MaxCitationLength
+	if maxCitation <= 0 {
+		maxCitation = DefaultMaxCitationLength
+	}
+
+	kept := result.Quads[:0]
+	for _, quad := range result.Quads {
+		valueTooLong := runeCountExceeds(quad.Value, maxValue)
+		citationTooLong := runeCountExceeds(quad.Citation, maxCitation)
+		if !valueTooLong && !citationTooLong {
+			kept = append(kept, quad)
+			continue
+		}
+
+		if policy == LengthPolicySkip {
+			result.SkippedLongQuads++
+			e.logLongQuad("skipped quad with an overlong field", quad)
+			continue
+		}
+		e.logLongQuad("truncated overlong quad field", quad)
+		if valueTooLong {
+			quad.Value = truncateRunes(quad.Value, maxValue)
+			quad.TruncatedFields = append(quad.TruncatedFields, "value")
+		}
+		if citationTooLong {
+			quad.Citation = truncateRunes(quad.Citation, maxCitation)
+			quad.TruncatedFields = append(quad.TruncatedFields, "citation")
+		}
+		kept = append(kept, quad)
+	}
+	result.Quads = kept
+}
+
+// logLongQuad reports a quad truncated or skipped by limitLengths to
+// Options.Logger
+func (e *Extractor) logLongQuad(msg string, quad Quad) {
+	if e.Options.Logger == nil {
+		return
+	}
+	e.Options.Logger.Warn(msg,
+		slog.String("subject", quad.Subject),
+		slog.String("relationship", quad.Relationship),
+		slog.Int("value_length", len([]rune(quad.Value))),
+		slog.Int("citation_length", len([]rune(quad.Citation))),
+	)
+}
+
+// runeCountExceeds reports whether s has more than limit characters
+func runeCountExceeds(s string, limit int) bool {
+	if len(s) <= limit {
+		return false
+	}
+	count := 0
+	for range s {
+		count++
+		if count > limit {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateRunes shortens s to at most limit characters, the last of them an
+// ellipsis
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:limit-1])) + "…"
+}
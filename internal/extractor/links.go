@@ -0,0 +1,107 @@
+package extractor
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// nonArticleNamespaces are the MediaWiki namespace prefixes, lowercased, whose
+// pages are not articles
+var nonArticleNamespaces = map[string]bool{
+	"file":          true,
+	"image":         true,
+	"media":         true,
+	"special":       true,
+	"help":          true,
+	"wikipedia":     true,
+	"wp":            true,
+	"project":       true,
+	"category":      true,
+	"template":      true,
+	"portal":        true,
+	"draft":         true,
+	"module":        true,
+	"mediawiki":     true,
+	"user":          true,
+	"talk":          true,
+	"user talk":     true,
+	"template talk": true,
+	"timedtext":     true,
+}
+
+// infoboxLinks returns the hrefs of the article links in the infoboxes matched
+// by selector, in page order
+func infoboxLinks(doc *goquery.Selection, selector string) []string {
+	var hrefs []string
+	doc.Find(selector).Find("a[href]").Each(func(i int, a *goquery.Selection) {
+		// Citation markers link to the reference list, not to other articles
+		if a.Closest("sup.reference").Length() > 0 {
+			return
+		}
+		href, _ := a.Attr("href")
+		hrefs = append(hrefs, href)
+	})
+	return hrefs
+}
+
+// resolveArticleLinks resolves hrefs against the page URL, keeping distinct
+// links to articles on the same host without their fragments
+func resolveArticleLinks(pageURL string, hrefs []string) []string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+
+	var links []string
+	seen := map[string]bool{base.String(): true}
+	for _, href := range hrefs {
+		ref, err := url.Parse(href)
+		if err != nil {
+			continue
+		}
+		link := base.ResolveReference(ref)
+		link.Fragment = ""
+		link.RawQuery = ""
+		if link.Host != base.Host || !IsArticleURL(link.String()) {
+			continue
+		}
+		if s := link.String(); !seen[s] {
+			seen[s] = true
+			links = append(links, s)
+		}
+	}
+	return links
+}
+
+// IsArticleURL reports whether rawURL is a /wiki/ URL of a main namespace
+// article. Pages in other namespaces, such as Talk:, Help:, Category: and
+// Special:, are not articles. Titles that merely contain a colon, like
+// "Star Wars: Episode IV", are.
+func IsArticleURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return isArticlePath(u.EscapedPath())
+}
+
+// isArticlePath reports whether a URL path such as /wiki/Paris names an
+// article rather than a page in another namespace such as File: or Help:
+func isArticlePath(path string) bool {
+	title, ok := strings.CutPrefix(path, "/wiki/")
+	if !ok || title == "" {
+		return false
+	}
+	if unescaped, err := url.PathUnescape(title); err == nil {
+		title = unescaped
+	}
+
+	namespace, _, found := strings.Cut(title, ":")
+	if !found {
+		return true
+	}
+	namespace = strings.ToLower(strings.ReplaceAll(namespace, "_", " "))
+	return !nonArticleNamespaces[namespace] && !strings.HasSuffix(namespace, " talk")
+}
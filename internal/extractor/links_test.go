@@ -0,0 +1,55 @@
+package extractor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsArticleURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://en.wikipedia.org/wiki/Paris", true},
+		{"https://en.wikipedia.org/wiki/Star_Wars:_Episode_IV_%E2%80%93_A_New_Hope", true},
+		{"https://en.wikipedia.org/wiki/Star_Wars:_Episode_IV", true},
+		{"https://en.wikipedia.org/wiki/Caf%C3%A9", true},
+		{"/wiki/Paris", true},
+		{"https://en.wikipedia.org/wiki/Talk:Paris", false},
+		{"https://en.wikipedia.org/wiki/talk:Paris", false},
+		{"https://en.wikipedia.org/wiki/User_talk:Example", false},
+		{"https://en.wikipedia.org/wiki/Wikipedia_talk:Manual_of_Style", false},
+		{"https://en.wikipedia.org/wiki/File:Paris.jpg", false},
+		{"https://en.wikipedia.org/wiki/Category:Capitals_in_Europe", false},
+		{"https://en.wikipedia.org/wiki/Special:Random", false},
+		{"https://en.wikipedia.org/wiki/Help:Contents", false},
+		{"https://en.wikipedia.org/wiki/Template%3AInfobox", false},
+		{"https://en.wikipedia.org/wiki/", false},
+		{"https://en.wikipedia.org/w/index.php?title=Paris", false},
+		{"https://en.wikipedia.org/Paris", false},
+		{"://bad url", false},
+	}
+	for _, tt := range tests {
+		if got := IsArticleURL(tt.url); got != tt.want {
+			t.Errorf("IsArticleURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestResolveArticleLinks(t *testing.T) {
+	got := resolveArticleLinks("https://en.wikipedia.org/wiki/Paris", []string{
+		"/wiki/France",
+		"/wiki/France#History",
+		"/wiki/Paris",
+		"/wiki/File:Paris.jpg",
+		"https://fr.wikipedia.org/wiki/Paris",
+		"./Seine",
+	})
+	want := []string{
+		"https://en.wikipedia.org/wiki/France",
+		"https://en.wikipedia.org/wiki/Seine",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("resolveArticleLinks = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,194 @@
+package extractor
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// numberFormat describes how a language edition writes numbers
+type numberFormat struct {
+	decimal rune
+	groups  string
+}
+
+var (
+	// dotDecimal numbers are written "1,234,567.89", as in English
+	dotDecimal = numberFormat{decimal: '.', groups: ","}
+
+	// commaDecimal numbers are written "1.234.567,89", as in German
+	commaDecimal = numberFormat{decimal: ',', groups: ".\u00a0\u202f"}
+
+	// spaceGrouped numbers are written "1 234 567,89", as in French, with
+	// plain, no-break or thin spaces between groups
+	spaceGrouped = numberFormat{decimal: ',', groups: " \u00a0\u202f\u2009"}
+
+	// swissGrouped numbers are written "1'234'567.89"
+	swissGrouped = numberFormat{decimal: '.', groups: "'\u2019\u00a0\u202f"}
+)
+
+// numberFormats maps page languages to their number format; languages not
+// listed use dotDecimal
+var numberFormats = map[string]numberFormat{
+	"de": commaDecimal, "nl": commaDecimal, "it": commaDecimal, "pt": commaDecimal,
+	"id": commaDecimal, "tr": commaDecimal, "da": commaDecimal, "el": commaDecimal,
+	"ro": commaDecimal, "hr": commaDecimal, "sl": commaDecimal, "sr": commaDecimal,
+	"vi": commaDecimal,
+	"fr": spaceGrouped, "es": spaceGrouped, "ru": spaceGrouped, "pl": spaceGrouped,
+	"cs": spaceGrouped, "sk": spaceGrouped, "uk": spaceGrouped, "sv": spaceGrouped,
+	"fi": spaceGrouped, "nb": spaceGrouped, "no": spaceGrouped, "hu": spaceGrouped,
+	"bg": spaceGrouped, "lt": spaceGrouped, "lv": spaceGrouped, "et": spaceGrouped,
+	"de-ch": swissGrouped, "als": swissGrouped,
+}
+
+// pageLanguage returns the language of a page from the lang attribute of its
+// html element, lowercased, or "" when it is not set
+func pageLanguage(doc *goquery.Selection) string {
+	lang, ok := doc.Attr("lang")
+	if !ok {
+		lang = doc.Find("html").AttrOr("lang", "")
+	}
+	return strings.ToLower(lang)
+}
+
+// numberFormatFor returns the number format of a language, trying the full
+// tag before its primary subtag
+func numberFormatFor(lang string) numberFormat {
+	if format, ok := numberFormats[lang]; ok {
+		return format
+	}
+	if primary, _, found := strings.Cut(lang, "-"); found {
+		if format, ok := numberFormats[primary]; ok {
+			return format
+		}
+	}
+	return dotDecimal
+}
+
+// normalizeNumbers sets Number on quads whose value is a number written in
+// the page language's format. Quads already normalized to a typed value are
+// left alone.
+func normalizeNumbers(quads []Quad, lang string) {
+	format := numberFormatFor(lang)
+	for i := range quads {
+		if quads[i].ValueType != "" {
+			continue
+		}
+		quads[i].Number = parseLocalizedNumber(quads[i].Value, format)
+	}
+}
+
+// parseLocalizedNumber returns the number at the start of value in canonical
+// form, digits with an optional "-" and "." decimal point and no grouping,
+// e.g. "1.234.567,5" in German as "1234567.5". The number may be followed by
+// a unit or a parenthetical such as a year, but not by further numbers, so
+// dates and ranges are not mistaken for numbers. It returns "" when value
+// does not start with a well-formed number.
+func parseLocalizedNumber(value string, format numberFormat) string {
+	value = strings.TrimSpace(footnoteMarker.ReplaceAllString(value, ""))
+
+	runes := []rune(value)
+	negative := false
+	if len(runes) > 0 && (runes[0] == '-' || runes[0] == '−' || runes[0] == '+') {
+		negative = runes[0] != '+'
+		runes = runes[1:]
+	}
+
+	// The number runs over digits and separators, ending on a digit
+	end := 0
+	for i, r := range runes {
+		if unicode.IsDigit(r) {
+			end = i + 1
+		} else if r != format.decimal && !strings.ContainsRune(format.groups, r) {
+			break
+		}
+	}
+	if end == 0 || !unicode.IsDigit(runes[0]) {
+		return ""
+	}
+	token, rest := runes[:end], string(runes[end:])
+	if !isNumberSuffix(rest) {
+		return ""
+	}
+
+	var integer, fraction strings.Builder
+	var group []rune
+	groups := 0
+	inFraction := false
+	for _, r := range token {
+		switch {
+		case unicode.IsDigit(r) && inFraction:
+			fraction.WriteRune(r)
+		case unicode.IsDigit(r):
+			group = append(group, r)
+		case r == format.decimal && !inFraction:
+			inFraction = true
+		case strings.ContainsRune(format.groups, r) && !inFraction:
+			// Every group after the first has exactly three digits
+			if len(group) == 0 || len(group) > 3 || (groups > 0 && len(group) != 3) {
+				return ""
+			}
+			integer.WriteString(string(group))
+			group = group[:0]
+			groups++
+		default:
+			return ""
+		}
+	}
+	if groups > 0 && len(group) != 3 {
+		return ""
+	}
+	integer.WriteString(string(group))
+	if integer.Len() == 0 || (inFraction && fraction.Len() == 0) {
+		return ""
+	}
+
+	number := integer.String()
+	if digits := strings.TrimLeft(number, "0"); digits != "" {
+		number = digits
+	} else {
+		number = "0"
+	}
+	if inFraction {
+		number += "." + fraction.String()
+	}
+	if negative {
+		number = "-" + number
+	}
+	return number
+}
+
+// isNumberSuffix reports whether the text following a number leaves it
+// standing on its own: nothing, or a unit or parenthetical after a space,
+// without another number outside parentheses
+func isNumberSuffix(rest string) bool {
+	if rest == "" {
+		return true
+	}
+	first := []rune(rest)[0]
+	if !unicode.IsSpace(first) && first != '(' && first != '%' && !unicode.IsLetter(first) {
+		return false
+	}
+
+	// Parenthesized text such as "(2020 census)" may hold numbers
+	var outside strings.Builder
+	depth := 0
+	for _, r := range rest {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+			outside.WriteRune(' ')
+		case depth == 0:
+			outside.WriteRune(r)
+		}
+	}
+	for _, word := range strings.Fields(outside.String()) {
+		if unicode.IsDigit([]rune(word)[0]) {
+			return false
+		}
+	}
+	return true
+}
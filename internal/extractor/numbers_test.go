@@ -0,0 +1,90 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestParseLocalizedNumber(t *testing.T) {
+	tests := []struct {
+		lang  string
+		value string
+		want  string
+	}{
+		// German groups with dots and writes decimals after a comma
+		{"de", "1.234.567", "1234567"},
+		{"de", "1.234.567,89", "1234567.89"},
+		{"de", "3,5 km²", "3.5"},
+		{"de", "83.190.556 (30. Sep. 2020)", "83190556"},
+		{"de", "−12,5 °C", "-12.5"},
+		{"de", "1.234.567[1]", "1234567"},
+		{"de", "12.5", ""},
+		{"de", "3.10.1990", ""},
+		{"de", "1,234,567", ""},
+		{"de-at", "1.234,5", "1234.5"},
+		{"de-ch", "1'234'567.89", "1234567.89"},
+
+		// French groups with plain, no-break or thin spaces
+		{"fr", "2 165 423 habitants", "2165423"},
+		{"fr", "2\u00a0165\u00a0423", "2165423"},
+		{"fr", "2\u202f165\u2009423,5", "2165423.5"},
+		{"fr", "105,4 km2", "105.4"},
+		{"fr", "12 %", "12"},
+		{"fr", "1.234", ""},
+		{"fr", "1 23 456", ""},
+
+		// English, and languages without a listed format
+		{"en", "1,234,567.89", "1234567.89"},
+		{"", "007", "7"},
+		{"", "0.5", "0.5"},
+		{"en", "1990–2000", ""},
+		{"en", "3 to 5", ""},
+		{"en", "approx. 5", ""},
+		{"en", "12,34", ""},
+	}
+	for _, tt := range tests {
+		if got := parseLocalizedNumber(tt.value, numberFormatFor(tt.lang)); got != tt.want {
+			t.Errorf("parseLocalizedNumber(%q, %s) = %q, want %q", tt.value, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeNumbersPageLanguage(t *testing.T) {
+	tests := []struct {
+		lang   string
+		values []string
+		want   []string
+	}{
+		{"de", []string{"3.645.000", "891,7 km²"}, []string{"3645000", "891.7"}},
+		{"fr", []string{"2 165 423", "105,4 km2"}, []string{"2165423", "105.4"}},
+		{"en", []string{"3,645,000", "891.7 km2"}, []string{"3645000", "891.7"}},
+	}
+	for _, tt := range tests {
+		page := `<html lang="` + tt.lang + `"><body><h1 id="firstHeading">Stadt</h1>
+<table class="infobox">
+<tr><th>Einwohner</th><td>` + tt.values[0] + `</td></tr>
+<tr><th>Fläche</th><td>` + tt.values[1] + `</td></tr>
+</table></body></html>`
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		e := NewExtractor()
+		e.Options.NormalizeNumbers = true
+		result, err := e.ParseDocument(doc.Selection)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Quads) != len(tt.want) {
+			t.Fatalf("%s: got %d quads, want %d", tt.lang, len(result.Quads), len(tt.want))
+		}
+		for i, q := range result.Quads {
+			if q.Number != tt.want[i] {
+				t.Errorf("%s: number of %q = %q, want %q", tt.lang, q.Value, q.Number, tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package extractor
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProseOnlyPage(t *testing.T) {
+	e := NewExtractor()
+	result := parseFixture(t, e, "prose_only.html")
+	if !result.NoStructuredData {
+		t.Error("NoStructuredData not set for a prose-only page")
+	}
+	if len(result.Quads) != 0 {
+		t.Errorf("got %d quads, want none", len(result.Quads))
+	}
+	if result.Title != "Examplia River" {
+		t.Errorf("title = %q, want %q", result.Title, "Examplia River")
+	}
+
+	e.Options.Strict = true
+	if _, err := e.ParseDocument(loadFixture(t, "prose_only.html").Selection); !errors.Is(err, ErrNoStructuredData) {
+		t.Errorf("strict: got %v, want ErrNoStructuredData", err)
+	}
+}
+
+// A prose-only page's quads, such as its short description, go through the
+// same steps as any other page's before NoStructuredData is decided
+func TestProseOnlyPageQuads(t *testing.T) {
+	parse := func(e *Extractor) *ExtractResult {
+		t.Helper()
+		doc := loadFixture(t, "prose_only.html")
+		doc.Find(".mw-parser-output").PrependHtml(`<div class="shortdescription nomobile noexcerpt noprint searchaux" style="display:none">River in Examplia</div>`)
+		result, err := e.ParseDocument(doc.Selection)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	e := NewExtractor()
+	e.Options.ShortDescription = true
+	e.Options.Strict = true
+	result := parse(e)
+	if result.NoStructuredData {
+		t.Error("NoStructuredData set although the page yielded a quad")
+	}
+	assertQuads(t, result.Quads, []quadFields{
+		{"Examplia River", DescriptionRelationship, "River in Examplia", ""},
+	})
+	if got := result.Quads[0].SubjectURL; got != "https://en.wikipedia.org/wiki/Examplia_River" {
+		t.Errorf("subject URL = %q, want the canonical URL", got)
+	}
+
+	e.Options.Strict = false
+	e.Options.ExcludeRelationships = []string{DescriptionRelationship}
+	result = parse(e)
+	if !result.NoStructuredData || len(result.Quads) != 0 {
+		t.Errorf("excluded description: NoStructuredData = %v with %d quads, want true with none", result.NoStructuredData, len(result.Quads))
+	}
+}
@@ -0,0 +1,69 @@
+package extractor
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/gocolly/colly/v2/proxy"
+)
+
+// proxyDialTimeout bounds the reachability check performed for each proxy
+const proxyDialTimeout = 5 * time.Second
+
+// defaultProxyPorts are used for the reachability check when a proxy URL has no port
+var defaultProxyPorts = map[string]string{
+	"http":   "80",
+	"https":  "443",
+	"socks5": "1080",
+}
+
+// SetProxies routes all requests through the given proxies, rotating between
+// them round-robin. Each proxy URL must use the http, https or socks5 scheme
+// and must accept TCP connections, otherwise an error is returned and the
+// extractor's configuration is left unchanged.
+func (e *Extractor) SetProxies(proxyURLs []string) error {
+	for _, proxyURL := range proxyURLs {
+		if err := checkProxy(proxyURL); err != nil {
+			return err
+		}
+	}
+
+	switcher, err := proxy.RoundRobinProxySwitcher(proxyURLs...)
+	if err != nil {
+		return fmt.Errorf("invalid proxy configuration: %w", err)
+	}
+	e.colly.SetProxyFunc(switcher)
+
+	return nil
+}
+
+// checkProxy validates a proxy URL and verifies that the proxy is reachable
+func checkProxy(proxyURL string) error {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy URL %q: %w", proxyURL, err)
+	}
+
+	defaultPort, ok := defaultProxyPorts[u.Scheme]
+	if !ok {
+		return fmt.Errorf("invalid proxy URL %q: scheme must be http, https or socks5", proxyURL)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("invalid proxy URL %q: missing host", proxyURL)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(u.Hostname(), port), proxyDialTimeout)
+	if err != nil {
+		return fmt.Errorf("proxy %s is unreachable: %w", u.Redacted(), err)
+	}
+	conn.Close()
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package extractor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// SetRateLimit limits how fast pages are fetched: at most parallelism
+// requests run against a host at once, and each waits delay after finishing
+// before the next one to that host may start. The limit applies across all
+// concurrent extractions using this extractor.
+func (e *Extractor) SetRateLimit(delay time.Duration, parallelism int) error {
+	if parallelism < 1 {
+		return fmt.Errorf("invalid rate limit: parallelism must be at least 1, got %d", parallelism)
+	}
+
+	err := e.colly.Limit(&colly.LimitRule{
+		DomainGlob:  "*",
+		Parallelism: parallelism,
+		Delay:       delay,
+	})
+	if err != nil {
+		return fmt.Errorf("invalid rate limit: %w", err)
+	}
+
+	return nil
+}
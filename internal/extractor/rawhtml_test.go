@@ -0,0 +1,55 @@
+package extractor
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestKeepHTML(t *testing.T) {
+	e := NewExtractor()
+	e.Options.KeepHTML = true
+	result := parseFixture(t, e, "collapsible_infobox.html")
+
+	genre, ok := findQuad(result.Quads, "Genre")
+	if !ok {
+		t.Fatal("no Genre quad")
+	}
+	const link = `<a href="/wiki/Example_rock" title="Example rock">Example rock</a>`
+	if genre.RawHTML != link {
+		t.Errorf("RawHTML = %q, want %q", genre.RawHTML, link)
+	}
+	if genre.Value != "Example rock" {
+		t.Errorf("Value = %q, want the plain text", genre.Value)
+	}
+
+	website, _ := findQuad(result.Quads, "Website")
+	if !strings.Contains(website.RawHTML, `href="https://examples.example.org"`) {
+		t.Errorf("Website RawHTML = %q, want the external link", website.RawHTML)
+	}
+
+	// Table values keep their HTML too
+	e.Options.Relationships = nil
+	result = parseFixture(t, e, "spanned_table.html")
+	languages, _ := findQuad(result.Quads, "Languages")
+	if languages.RawHTML != "Examplish" {
+		t.Errorf("table RawHTML = %q, want %q", languages.RawHTML, "Examplish")
+	}
+}
+
+func TestKeepHTMLOff(t *testing.T) {
+	result := parseFixture(t, NewExtractor(), "collapsible_infobox.html")
+
+	for _, q := range result.Quads {
+		if q.RawHTML != "" {
+			t.Errorf("%s: RawHTML = %q without KeepHTML", q.Relationship, q.RawHTML)
+		}
+		encoded, err := json.Marshal(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(encoded), "raw_html") {
+			t.Errorf("%s: JSON has raw_html without KeepHTML: %s", q.Relationship, encoded)
+		}
+	}
+}
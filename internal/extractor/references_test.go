@@ -0,0 +1,161 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// referenceList parses a page whose reference list holds items, each given as
+// an id followed by its inner HTML
+func referenceList(t *testing.T, items ...string) *goquery.Selection {
+	t.Helper()
+	var page strings.Builder
+	page.WriteString(`<html><body><div class="reflist"><ol class="references">`)
+	for i := 0; i+1 < len(items); i += 2 {
+		page.WriteString(`<li id="` + items[i] + `"><span class="reference-text">` + items[i+1] + `</span></li>`)
+	}
+	page.WriteString(`</ol></div></body></html>`)
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc.Selection
+}
+
+// source is the inner HTML of a reference linking url
+func source(url string) string {
+	return `<a class="external text" href="` + url + `">Source</a>`
+}
+
+// note is the inner HTML of a footnote citing other reference list items
+func note(ids ...string) string {
+	html := "See"
+	for _, id := range ids {
+		html += `<sup class="reference"><a href="#` + id + `">[x]</a></sup>`
+	}
+	return html
+}
+
+func TestExtractReferencesGroupedNotes(t *testing.T) {
+	doc := referenceList(t,
+		"cite_note-a", note("cite_note-1"),
+		"cite_note-b", note("cite_note-missing", "cite_note-2", "cite_note-1"),
+		"cite_note-c", note("cite_note-a"),
+		"cite_note-d", note("cite_note-e"),
+		"cite_note-e", note("cite_note-d"),
+		"cite_note-f", note("cite_note-f"),
+		"cite_note-1", source("https://one.example.org/"),
+		"cite_note-2", source("https://two.example.org/")+note("cite_note-1"),
+	)
+	references := NewExtractor().extractReferences(doc)
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		// A note cites the source of the first reference it reaches
+		{"cite_note-a", "https://one.example.org/"},
+		{"cite_note-b", "https://two.example.org/"},
+		// Notes citing notes are followed
+		{"cite_note-c", "https://one.example.org/"},
+		// A reference with a link of its own keeps it
+		{"cite_note-2", "https://two.example.org/"},
+		// Cycles and notes citing themselves resolve to nothing
+		{"cite_note-d", ""},
+		{"cite_note-e", ""},
+		{"cite_note-f", ""},
+	}
+	for _, tt := range tests {
+		if got := references[tt.id]; got != tt.want {
+			t.Errorf("references[%s] = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestExtractReferencesCitationDepth(t *testing.T) {
+	doc := referenceList(t,
+		"cite_note-a", note("cite_note-b"),
+		"cite_note-b", note("cite_note-c"),
+		"cite_note-c", note("cite_note-1"),
+		"cite_note-1", source("https://one.example.org/"),
+	)
+
+	tests := []struct {
+		depth int
+		want  map[string]string
+	}{
+		{1, map[string]string{"cite_note-a": "", "cite_note-b": "", "cite_note-c": "https://one.example.org/"}},
+		{2, map[string]string{"cite_note-a": "", "cite_note-b": "https://one.example.org/", "cite_note-c": "https://one.example.org/"}},
+		{0, map[string]string{"cite_note-a": "https://one.example.org/", "cite_note-b": "https://one.example.org/", "cite_note-c": "https://one.example.org/"}},
+	}
+	for _, tt := range tests {
+		e := NewExtractor()
+		e.Options.MaxCitationDepth = tt.depth
+		references := e.extractReferences(doc)
+		for id, want := range tt.want {
+			if got := references[id]; got != want {
+				t.Errorf("depth %d: references[%s] = %q, want %q", tt.depth, id, got, want)
+			}
+		}
+	}
+}
+
+// A named reference's base name takes the link of its lowest numbered use,
+// comparing numbers rather than text
+func TestExtractReferencesBaseName(t *testing.T) {
+	doc := referenceList(t,
+		"cite_note-NYT-10", source("https://nyt.example.org/ten"),
+		"cite_note-NYT-3", source("https://nyt.example.org/three"),
+		"cite_note-NYT-25", source("https://nyt.example.org/twenty-five"),
+		"cite_note-7", source("https://seven.example.org/"),
+	)
+	references := NewExtractor().extractReferences(doc)
+
+	if got := references["cite_note-NYT"]; got != "https://nyt.example.org/three" {
+		t.Errorf("base name link = %q, want the link of cite_note-NYT-3", got)
+	}
+	if got, _ := lookupReference(references, "cite_note-NYT-4"); got != "https://nyt.example.org/three" {
+		t.Errorf("lookupReference(cite_note-NYT-4) = %q, want the base name link", got)
+	}
+	if _, ok := references["cite_note"]; ok {
+		t.Error("unnamed reference recorded under a base name")
+	}
+}
+
+func TestReferenceLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"cite_note-NYT-3", "cite_note-NYT-10", true},
+		{"cite_note-NYT-10", "cite_note-NYT-3", false},
+		{"cite_note-NYT-9", "cite_note-NYT-10", true},
+		{"cite_note-NYT-3", "cite_note-NYT-3", false},
+		{"cite_note-AP-10", "cite_note-NYT-3", true},
+		{"cite_note-NYT-3", "cite_note-AP-10", false},
+	}
+	for _, tt := range tests {
+		if got := referenceLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("referenceLess(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCitationBaseName(t *testing.T) {
+	tests := map[string]string{
+		"cite_note-NYT-3":         "cite_note-NYT",
+		"cite_note-smith-2020-12": "cite_note-smith-2020",
+		"cite_note-5":             "cite_note-5",
+		"cite_note-a":             "cite_note-a",
+		"cite_note-NYT-":          "cite_note-NYT-",
+		"cite_note-NYT-3b":        "cite_note-NYT-3b",
+	}
+	for id, want := range tests {
+		if got := citationBaseName(id); got != want {
+			t.Errorf("citationBaseName(%s) = %q, want %q", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package extractor
+
+import (
+	"strings"
+)
+
+// RelationshipMap maps relationship label synonyms to a canonical relationship
+// name. Lookups ignore case and surrounding whitespace.
+type RelationshipMap map[string]string
+
+// defaultRelationshipSynonyms lists common infobox label variants per canonical name
+var defaultRelationshipSynonyms = map[string][]string{
+	"Born":         {"Date of birth", "Birth date", "Birthdate"},
+	"Died":         {"Date of death", "Death date"},
+	"Spouse":       {"Spouse(s)", "Spouses"},
+	"Occupation":   {"Occupations", "Occupation(s)", "Profession"},
+	"Genre":        {"Genres", "Genre(s)"},
+	"Website":      {"Official website", "Web site", "URL"},
+	"Founded":      {"Date founded", "Founding date", "Foundation"},
+	"Founders":     {"Founder", "Founder(s)", "Founded by"},
+	"Headquarters": {"Headquarters location", "Head office", "HQ"},
+	"Capital":      {"Capital city"},
+	"Population":   {"Total population", "Population total"},
+}
+
+// DefaultRelationshipMap returns a map covering common synonyms found across
+// Wikipedia infoboxes, e.g. "Date of birth" and "Birth date" both map to "Born".
+func DefaultRelationshipMap() RelationshipMap {
+	m := RelationshipMap{}
+	for canonical, synonyms := range defaultRelationshipSynonyms {
+		m.Add(canonical, synonyms...)
+	}
+	return m
+}
+
+// Add maps each synonym to the canonical relationship name
+func (m RelationshipMap) Add(canonical string, synonyms ...string) {
+	for _, synonym := range synonyms {
+		m[normalizeLabel(synonym)] = canonical
+	}
+}
+
+// Canonical returns the canonical name for a relationship label, or the label
+// itself if it has no mapping
+func (m RelationshipMap) Canonical(label string) string {
+	if canonical, ok := m[normalizeLabel(label)]; ok {
+		return canonical
+	}
+	return label
+}
+
+// normalizeLabel lowercases a label and collapses internal whitespace
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// canonicalizeRelationships rewrites quad relationships to their canonical
+// names, keeping the original label in RawRelationship
+func (e *Extractor) canonicalizeRelationships(quads []Quad) {
+	if e.Options.Relationships == nil {
+		return
+	}
+
+	for i := range quads {
+		canonical := e.Options.Relationships.Canonical(quads[i].Relationship)
+		if canonical != quads[i].Relationship {
+			quads[i].RawRelationship = quads[i].Relationship
+			quads[i].Relationship = canonical
+		}
+	}
+}
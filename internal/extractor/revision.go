@@ -0,0 +1,78 @@
+package extractor
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var (
+	// articleIDPattern matches the page ID in MediaWiki's inline mw.config script
+	articleIDPattern = regexp.MustCompile(`"wgArticleId"\s*:\s*(\d+)`)
+
+	// revisionIDPattern matches the ID of the revision being viewed, which
+	// differs from wgCurRevisionId when an old revision is viewed
+	revisionIDPattern = regexp.MustCompile(`"wgRevisionId"\s*:\s*(\d+)`)
+
+	// curRevisionIDPattern matches the ID of the page's current revision,
+	// the fallback for pages that don't set wgRevisionId
+	curRevisionIDPattern = regexp.MustCompile(`"wgCurRevisionId"\s*:\s*(\d+)`)
+)
+
+// parsePageIDs reads the page ID and revision ID from the mw.config variables
+// MediaWiki embeds in an inline script. Missing values are returned as zero.
+func parsePageIDs(doc *goquery.Selection) (pageID, revisionID int64) {
+	var curRevisionID int64
+	doc.Find("script").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		script := s.Text()
+		if pageID == 0 {
+			pageID = matchID(articleIDPattern, script)
+		}
+		if revisionID == 0 {
+			revisionID = matchID(revisionIDPattern, script)
+		}
+		if curRevisionID == 0 {
+			curRevisionID = matchID(curRevisionIDPattern, script)
+		}
+		return pageID == 0 || revisionID == 0
+	})
+
+	if revisionID == 0 {
+		revisionID = curRevisionID
+	}
+	return pageID, revisionID
+}
+
+// matchID returns the ID captured by pattern in script, or zero
+func matchID(pattern *regexp.Regexp, script string) int64 {
+	match := pattern.FindStringSubmatch(script)
+	if match == nil {
+		return 0
+	}
+	id, _ := strconv.ParseInt(match[1], 10, 64)
+	return id
+}
+
+// RevisionURL returns the permanent link to a revision of the page at
+// sourceURL, e.g. https://en.wikipedia.org/w/index.php?oldid=123, or an empty
+// string if revisionID is zero or sourceURL cannot be parsed
+func RevisionURL(sourceURL string, revisionID int64) string {
+	if revisionID == 0 {
+		return ""
+	}
+
+	u, err := url.Parse(sourceURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	permalink := url.URL{
+		Scheme:   u.Scheme,
+		Host:     u.Host,
+		Path:     "/w/index.php",
+		RawQuery: "oldid=" + strconv.FormatInt(revisionID, 10),
+	}
+	return permalink.String()
+}
@@ -0,0 +1,77 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestParsePageIDs(t *testing.T) {
+	result := parseFixture(t, NewExtractor(), "page_ids.html")
+
+	if result.PageID != 48213 {
+		t.Errorf("PageID = %d, want 48213", result.PageID)
+	}
+	if result.RevisionID != 1187654321 {
+		t.Errorf("RevisionID = %d, want 1187654321", result.RevisionID)
+	}
+}
+
+func TestParsePageIDsScripts(t *testing.T) {
+	tests := []struct {
+		name               string
+		scripts            string
+		pageID, revisionID int64
+	}{
+		{
+			name:       "old revision",
+			scripts:    `<script>RLCONF={"wgCurRevisionId":200,"wgRevisionId":150,"wgArticleId":7};</script>`,
+			pageID:     7,
+			revisionID: 150,
+		},
+		{
+			name:       "current revision only",
+			scripts:    `<script>RLCONF={"wgArticleId":7,"wgCurRevisionId":200};</script>`,
+			pageID:     7,
+			revisionID: 200,
+		},
+		{
+			name:       "split across scripts",
+			scripts:    `<script>RLCONF={"wgArticleId": 7};</script><script>mw.config.set({"wgRevisionId": 150});</script>`,
+			pageID:     7,
+			revisionID: 150,
+		},
+		{
+			name:    "no config",
+			scripts: `<script>var x = 1;</script>`,
+		},
+	}
+	for _, tt := range tests {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><head>" + tt.scripts + "</head><body></body></html>"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pageID, revisionID := parsePageIDs(doc.Selection)
+		if pageID != tt.pageID || revisionID != tt.revisionID {
+			t.Errorf("%s: parsePageIDs = %d, %d, want %d, %d", tt.name, pageID, revisionID, tt.pageID, tt.revisionID)
+		}
+	}
+}
+
+func TestRevisionURL(t *testing.T) {
+	tests := []struct {
+		source   string
+		revision int64
+		want     string
+	}{
+		{"https://en.wikipedia.org/wiki/Examplia", 1187654321, "https://en.wikipedia.org/w/index.php?oldid=1187654321"},
+		{"https://en.wikipedia.org/wiki/Examplia", 0, ""},
+		{"not a url", 5, ""},
+	}
+	for _, tt := range tests {
+		if got := RevisionURL(tt.source, tt.revision); got != tt.want {
+			t.Errorf("RevisionURL(%q, %d) = %q, want %q", tt.source, tt.revision, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package extractor
+
+import (
+	"github.com/PuerkitoBio/goquery"
+)
+
+// seeAlsoLinks returns the hrefs of the links listed in the page's "See
+// also" section, in page order. The section runs from its heading to the
+// next top-level heading, and only links in its lists are taken, leaving out
+// navigation boxes and portal bars.
+func seeAlsoLinks(doc *goquery.Selection) []string {
+	// Current markup puts the id on the h2 inside a div.mw-heading, older
+	// markup on a .mw-headline span inside the h2
+	anchor := doc.Find("#See_also").First()
+	if anchor.Length() == 0 {
+		return nil
+	}
+	heading := anchor.Closest("div.mw-heading")
+	if heading.Length() == 0 {
+		heading = anchor.Closest("h2")
+	}
+	if heading.Length() == 0 {
+		return nil
+	}
+
+	var hrefs []string
+	heading.NextUntil("h2, div.mw-heading2").Find("li a[href]").Each(func(i int, a *goquery.Selection) {
+		if a.Closest(".navbox, .portalbox, .sistersitebox, .hatnote").Length() > 0 {
+			return
+		}
+		href, _ := a.Attr("href")
+		hrefs = append(hrefs, href)
+	})
+	return hrefs
+}
@@ -0,0 +1,91 @@
+package extractor
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// CitationPolicy says what happens when a page's footnote markers link to
+// reference list entries that don't exist
+type CitationPolicy string
+
+const (
+	// CitationPolicyOff only counts the unresolved anchors in
+	// ExtractResult.UnresolvedCitations. It is the default.
+	CitationPolicyOff CitationPolicy = "off"
+
+	// CitationPolicyWarn also logs a warning to Options.Logger
+	CitationPolicyWarn CitationPolicy = "warn"
+
+	// CitationPolicyError fails extraction with ErrUnresolvedCitations
+	CitationPolicyError CitationPolicy = "error"
+)
+
+// ParseCitationPolicy parses a citation policy name, with "" meaning
+// CitationPolicyOff
+func ParseCitationPolicy(name string) (CitationPolicy, error) {
+	switch policy := CitationPolicy(strings.ToLower(strings.TrimSpace(name))); policy {
+	case "":
+		return CitationPolicyOff, nil
+	case CitationPolicyOff, CitationPolicyWarn, CitationPolicyError:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown citation policy %q (valid policies: off, warn, error)", name)
+	}
+}
+
+// referenceListSelector matches the reference lists of a page
+const referenceListSelector = "#References, #references, .reflist, .references"
+
+// referenceIDs returns the IDs of every entry in the page's reference lists,
+// including those without an external link, such as book citations, along
+// with the base names of named references
+func referenceIDs(doc *goquery.Selection) map[string]bool {
+	ids := make(map[string]bool)
+	doc.Find(referenceListSelector).Find("li[id]").Each(func(i int, li *goquery.Selection) {
+		id := li.AttrOr("id", "")
+		ids[id] = true
+		ids[citationBaseName(id)] = true
+	})
+	return ids
+}
+
+// countUnresolvedCitations counts the #cite_note anchors in s that match no
+// entry of the reference lists, whose IDs are given by ids
+func countUnresolvedCitations(s *goquery.Selection, ids map[string]bool) int {
+	unresolved := 0
+	s.Find("a[href*='#cite_note']").Each(func(i int, a *goquery.Selection) {
+		key := citationKey(a.AttrOr("href", ""))
+		if key == "" {
+			return
+		}
+		// Named references may be numbered differently in the text
+		if !ids[key] && !ids[citationBaseName(key)] {
+			unresolved++
+		}
+	})
+	return unresolved
+}
+
+// checkCitations applies Options.CitationPolicy to a page with unresolved
+// citation anchors
+func (e *Extractor) checkCitations(result *ExtractResult) error {
+	if result.UnresolvedCitations == 0 {
+		return nil
+	}
+	switch e.Options.CitationPolicy {
+	case CitationPolicyError:
+		return fmt.Errorf("%w: %d footnote markers link to no reference", ErrUnresolvedCitations, result.UnresolvedCitations)
+	case CitationPolicyWarn:
+		if e.Options.Logger != nil {
+			e.Options.Logger.Warn("unresolved citation anchors",
+				slog.String("title", result.Title),
+				slog.Int("count", result.UnresolvedCitations),
+			)
+		}
+	}
+	return nil
+}
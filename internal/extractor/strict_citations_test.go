@@ -0,0 +1,67 @@
+package extractor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// bookCitationPage is an infobox whose footnotes point at a web source, a
+// book without an external link, a named reference and a missing entry
+const bookCitationPage = `<html><body>
+<h1 id="firstHeading">Example Book</h1>
+<table class="infobox"><tbody>
+<tr><th class="infobox-label">Author</th><td class="infobox-data">Ann Example<sup class="reference"><a href="#cite_note-1">[1]</a></sup></td></tr>
+<tr><th class="infobox-label">Published</th><td class="infobox-data">1901<sup class="reference"><a href="#cite_note-2">[2]</a></sup></td></tr>
+<tr><th class="infobox-label">Pages</th><td class="infobox-data">320<sup class="reference"><a href="#cite_note-Smith-3">[3]</a></sup></td></tr>
+<tr><th class="infobox-label">Publisher</th><td class="infobox-data">Example Press<sup class="reference"><a href="#cite_note-9">[9]</a></sup></td></tr>
+</tbody></table>
+<div class="reflist"><ol class="references">
+<li id="cite_note-1"><span class="reference-text"><a class="external text" href="https://example.org/ann">Profile</a></span></li>
+<li id="cite_note-2"><span class="reference-text">Smith, J. <i>A History of Examples</i>. Example Press, 1950. p. 12.</span></li>
+<li id="cite_note-Smith-4"><span class="reference-text">Smith, J. <i>Collected Examples</i>.</span></li>
+</ol></div>
+</body></html>`
+
+func TestCountUnresolvedCitations(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(bookCitationPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := referenceIDs(doc.Selection)
+	for _, id := range []string{"cite_note-1", "cite_note-2", "cite_note-Smith-4", "cite_note-Smith"} {
+		if !ids[id] {
+			t.Errorf("referenceIDs is missing %s", id)
+		}
+	}
+
+	// Only the marker for the missing cite_note-9 is unresolved; the book
+	// citation has no link but is still a reference
+	if got := countUnresolvedCitations(doc.Find("table.infobox"), ids); got != 1 {
+		t.Errorf("countUnresolvedCitations = %d, want 1", got)
+	}
+}
+
+func TestCitationPolicyBookCitation(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(bookCitationPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := NewExtractor()
+	e.Options.CitationPolicy = CitationPolicyError
+	if _, err := e.ParseDocument(doc.Selection); !errors.Is(err, ErrUnresolvedCitations) {
+		t.Fatalf("ParseDocument with a missing reference: err = %v, want ErrUnresolvedCitations", err)
+	}
+
+	// Without the missing reference, a page citing a book passes
+	doc.Find("td:contains('Example Press') sup").Remove()
+	result, err := e.ParseDocument(doc.Selection)
+	if err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+	if result.UnresolvedCitations != 0 {
+		t.Errorf("UnresolvedCitations = %d, want 0", result.UnresolvedCitations)
+	}
+}
@@ -0,0 +1,70 @@
+package extractor
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// TypeRelationship is the relationship of the quad giving the subject's
+// inferred type when ExtractorOptions.InferSubjectType is set
+const TypeRelationship = "type"
+
+// ValueTypeSchemaType marks a value that is a schema.org type name
+const ValueTypeSchemaType = "schema_type"
+
+// infoboxTypes maps infobox CSS classes to schema.org types. Infobox
+// templates add an "ib-" class named after the template, and some add a
+// class such as "biography". Generic microformat classes like "vevent" and
+// "vcard" are shared by too many templates to identify a type.
+var infoboxTypes = map[string]string{
+	"biography":               "Person",
+	"ib-person":               "Person",
+	"ib-officeholder":         "Person",
+	"ib-writer":               "Person",
+	"ib-scientist":            "Person",
+	"ib-artist":               "Person",
+	"ib-royalty":              "Person",
+	"ib-football-biography":   "Person",
+	"ib-musical-artist":       "MusicGroup",
+	"ib-settlement":           "Place",
+	"ib-building":             "Place",
+	"ib-park":                 "Park",
+	"ib-country":              "Country",
+	"ib-river":                "RiverBodyOfWater",
+	"ib-lake":                 "LakeBodyOfWater",
+	"ib-mountain":             "Mountain",
+	"ib-airport":              "Airport",
+	"ib-company":              "Organization",
+	"ib-organization":         "Organization",
+	"ib-political-party":      "Organization",
+	"ib-university":           "CollegeOrUniversity",
+	"ib-school":               "School",
+	"ib-sports-team":          "SportsTeam",
+	"ib-football-club":        "SportsTeam",
+	"ib-film":                 "Movie",
+	"ib-television":           "TVSeries",
+	"ib-album":                "MusicAlbum",
+	"ib-song":                 "MusicRecording",
+	"ib-book":                 "Book",
+	"ib-video-game":           "VideoGame",
+	"ib-software":             "SoftwareApplication",
+	"ib-programming-language": "ComputerLanguage",
+	"ib-language":             "Language",
+	"ib-military-conflict":    "Event",
+	"ib-election":             "Event",
+	"ib-chembox":              "ChemicalSubstance",
+	"chembox":                 "ChemicalSubstance",
+	"biota":                   "Taxon",
+}
+
+// inferSubjectType returns the schema.org type of the page's subject from
+// the classes of its first infobox, or "" when they don't identify one
+func inferSubjectType(infoboxes *goquery.Selection) string {
+	for _, class := range strings.Fields(infoboxes.First().AttrOr("class", "")) {
+		if schemaType, ok := infoboxTypes[class]; ok {
+			return schemaType
+		}
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestInferSubjectType(t *testing.T) {
+	tests := []struct {
+		html string
+		want string
+	}{
+		{`<table class="infobox biography vcard"></table>`, "Person"},
+		{`<table class="infobox ib-settlement vcard"></table>`, "Place"},
+		{`<table class="infobox ib-river"></table>`, "RiverBodyOfWater"},
+		{`<table class="infobox vevent ib-film"></table>`, "Movie"},
+		{`<table class="infobox bordered chembox"></table>`, "ChemicalSubstance"},
+		{`<table class="infobox biota"></table>`, "Taxon"},
+		// Microformat classes are shared by too many templates to tell
+		{`<table class="infobox vcard"></table>`, ""},
+		{`<table class="infobox vevent"></table>`, ""},
+		{`<table class="infobox ib-unknown-template"></table>`, ""},
+		{`<table class="infobox"></table>`, ""},
+		// Only the first infobox describes the subject
+		{`<table class="infobox ib-album"></table><table class="infobox ib-person"></table>`, "MusicAlbum"},
+		{``, ""},
+	}
+	for _, tt := range tests {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><body>" + tt.html + "</body></html>"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := inferSubjectType(doc.Find("table.infobox")); got != tt.want {
+			t.Errorf("inferSubjectType(%s) = %q, want %q", tt.html, got, tt.want)
+		}
+	}
+}
+
+func TestInferSubjectTypeQuad(t *testing.T) {
+	e := NewExtractor()
+	result := parseFixture(t, e, "converted_measurements.html")
+	if result.SubjectType != "Mountain" {
+		t.Errorf("SubjectType = %q, want %q", result.SubjectType, "Mountain")
+	}
+	if _, ok := findQuad(result.Quads, TypeRelationship); ok {
+		t.Error("type quad added without InferSubjectType")
+	}
+
+	e.Options.InferSubjectType = true
+	result = parseFixture(t, e, "converted_measurements.html")
+	if len(result.Quads) == 0 {
+		t.Fatal("no quads extracted")
+	}
+	first := result.Quads[0]
+	if first.Subject != "Mount Example" || first.Relationship != TypeRelationship ||
+		first.Value != "Mountain" || first.ValueType != ValueTypeSchemaType {
+		t.Errorf("first quad = %+v, want the subject's type", first)
+	}
+
+	// Pages whose infobox doesn't identify a type get no type quad
+	result = parseFixture(t, e, "collapsible_infobox.html")
+	if _, ok := findQuad(result.Quads, TypeRelationship); ok || result.SubjectType != "" {
+		t.Errorf("SubjectType = %q for an infobox with only microformat classes, want none", result.SubjectType)
+	}
+}
@@ -0,0 +1,133 @@
+package extractor
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	// PrecededByRelationship links a subject to its predecessor in an office
+	// listed in a succession box
+	PrecededByRelationship = "preceded_by"
+
+	// SucceededByRelationship links a subject to its successor in an office
+	// listed in a succession box
+	SucceededByRelationship = "succeeded_by"
+)
+
+// parseSuccessionBox extracts the predecessor and successor of the subject
+// from a succession box, the "Preceded by / Succeeded by" table at the foot
+// of biographies. Each office row yields one quad per linked predecessor and
+// successor, with the office in Section and the linked article in ValueURL.
+// Rows spanning several offices, such as one predecessor for two posts, are
+// repeated for each office. Cells without a link, like "New office", are
+// skipped.
+func (e *Extractor) parseSuccessionBox(table *goquery.Selection, subject string) []Quad {
+	var quads []Quad
+	for _, row := range buildTableGrid(table) {
+		// Office rows have a predecessor, office and successor cell; the
+		// header rows between them name a group of offices
+		if len(row) != 3 || row[0] == nil || row[1] == nil || row[2] == nil || row[1].Is("th") {
+			continue
+		}
+		office := successionOffice(row[1])
+		for _, side := range []struct {
+			cell         *goquery.Selection
+			prefix       string
+			relationship string
+		}{
+			{row[0], "preceded by", PrecededByRelationship},
+			{row[2], "succeeded by", SucceededByRelationship},
+		} {
+			if !strings.HasPrefix(strings.ToLower(collapseSpace(side.cell.Text())), side.prefix) {
+				continue
+			}
+			for _, entity := range successionEntities(side.cell) {
+				quads = append(quads, Quad{
+					Subject:      subject,
+					Relationship: side.relationship,
+					Value:        entity.name,
+					ValueURL:     entity.href,
+					Section:      office,
+				})
+			}
+		}
+	}
+	return quads
+}
+
+// successionOffice returns the title of the office in a succession box row,
+// the bold text above its term of office
+func successionOffice(cell *goquery.Selection) string {
+	if title := collapseSpace(cell.Find("b").First().Text()); title != "" {
+		return title
+	}
+	clone := cell.Clone()
+	clone.Find("br").ReplaceWithHtml("\n")
+	first, _, _ := strings.Cut(clone.Text(), "\n")
+	return collapseSpace(first)
+}
+
+// successionEntity is a predecessor or successor named in a succession box
+type successionEntity struct {
+	name string
+	href string
+}
+
+// successionEntities returns the people linked from a predecessor or
+// successor cell, skipping citation markers
+func successionEntities(cell *goquery.Selection) []successionEntity {
+	var entities []successionEntity
+	cell.Find("a[href]").Each(func(i int, a *goquery.Selection) {
+		if a.Closest("sup.reference").Length() > 0 {
+			return
+		}
+		name := collapseSpace(a.Text())
+		if name == "" {
+			return
+		}
+		entity := successionEntity{name: name}
+		// Red links point at the edit page and have no article yet
+		if href, _ := a.Attr("href"); isArticleHref(href) {
+			entity.href = href
+		}
+		entities = append(entities, entity)
+	})
+	return entities
+}
+
+// isArticleHref reports whether a link's href, relative or absolute, points
+// at a main namespace article
+func isArticleHref(href string) bool {
+	u, err := url.Parse(href)
+	return err == nil && isArticlePath(u.EscapedPath())
+}
+
+// resolveValueURLs makes the relative ValueURLs of quads absolute against
+// the page URL
+func resolveValueURLs(pageURL string, quads []Quad) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return
+	}
+	for i := range quads {
+		if quads[i].ValueURL == "" {
+			continue
+		}
+		ref, err := url.Parse(quads[i].ValueURL)
+		if err != nil || ref.IsAbs() {
+			continue
+		}
+		link := base.ResolveReference(ref)
+		link.Fragment = ""
+		quads[i].ValueURL = link.String()
+	}
+}
+
+// collapseSpace collapses runs of whitespace, including non-breaking spaces,
+// in text to single spaces
+func collapseSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,65 @@
+package extractor
+
+import "testing"
+
+func TestParseSuccessionBox(t *testing.T) {
+	e := NewExtractor()
+	e.Options.Relationships = nil
+	result := parseFixture(t, e, "succession_box.html")
+
+	type succession struct {
+		Relationship string
+		Value        string
+		ValueURL     string
+		Section      string
+	}
+	want := []succession{
+		{PrecededByRelationship, "John Former", "https://en.wikipedia.org/wiki/John_Former", "Example Party nominee for Governor of Exampleland"},
+		{SucceededByRelationship, "Red Candidate", "", "Example Party nominee for Governor of Exampleland"},
+		{PrecededByRelationship, "John Former", "https://en.wikipedia.org/wiki/John_Former", "Governor of Exampleland"},
+		{SucceededByRelationship, "Sam Later", "https://en.wikipedia.org/wiki/Sam_Later", "Governor of Exampleland"},
+		{PrecededByRelationship, "John Former", "https://en.wikipedia.org/wiki/John_Former", "Minister of Examples"},
+		{SucceededByRelationship, "Ann Next", "https://en.wikipedia.org/wiki/Ann_Next", "Minister of Examples"},
+		{SucceededByRelationship, "Bob Next", "https://en.wikipedia.org/wiki/Bob_Next", "Minister of Examples"},
+		{SucceededByRelationship, "Sam Later", "https://en.wikipedia.org/wiki/Sam_Later", "Chair of the Example Commission"},
+	}
+
+	var got []succession
+	for _, q := range result.Quads {
+		if q.Relationship != PrecededByRelationship && q.Relationship != SucceededByRelationship {
+			continue
+		}
+		if q.Subject != "Jane Example" {
+			t.Errorf("subject = %q, want %q", q.Subject, "Jane Example")
+		}
+		got = append(got, succession{q.Relationship, q.Value, q.ValueURL, q.Section})
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d succession quads, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("quad %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// The infobox is still extracted alongside the succession box
+	if q, ok := findQuad(result.Quads, "Political party"); !ok || q.Value != "Example Party" {
+		t.Errorf("Political party = %+v, %v, want Example Party", q, ok)
+	}
+}
+
+func TestResolveValueURLs(t *testing.T) {
+	quads := []Quad{
+		{ValueURL: "/wiki/John_Former"},
+		{ValueURL: ""},
+		{ValueURL: "https://fr.wikipedia.org/wiki/Paris"},
+	}
+	resolveValueURLs("https://en.wikipedia.org/wiki/Jane_Example", quads)
+	want := []string{"https://en.wikipedia.org/wiki/John_Former", "", "https://fr.wikipedia.org/wiki/Paris"}
+	for i, q := range quads {
+		if q.ValueURL != want[i] {
+			t.Errorf("ValueURL %d = %q, want %q", i, q.ValueURL, want[i])
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package extractor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+const (
+	// SummaryRelationship is the relationship of the quad giving the lead
+	// paragraph of a page fetched with ExtractSummary
+	SummaryRelationship = "summary"
+
+	// ThumbnailRelationship is the relationship of the quad giving the URL
+	// of a page's lead image fetched with ExtractSummary
+	ThumbnailRelationship = "thumbnail"
+)
+
+// summaryPath is the path of the REST API's page summary endpoint
+const summaryPath = "/api/rest_v1/page/summary/"
+
+// languageCode matches a Wikipedia language code such as "en", "simple" or
+// "zh-min-nan", which is also the first label of the wiki's host
+var languageCode = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
+
+// restSummary is the part of a REST API page summary that ExtractSummary reads
+type restSummary struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Titles struct {
+		Normalized string `json:"normalized"`
+	} `json:"titles"`
+	PageID      int64  `json:"pageid"`
+	Revision    string `json:"revision"`
+	Timestamp   string `json:"timestamp"`
+	Description string `json:"description"`
+	Extract     string `json:"extract"`
+	Thumbnail   struct {
+		Source string `json:"source"`
+	} `json:"thumbnail"`
+	ContentURLs struct {
+		Desktop struct {
+			Page string `json:"page"`
+		} `json:"desktop"`
+	} `json:"content_urls"`
+}
+
+// ExtractSummary fetches the page titled title from the REST API's
+// /page/summary endpoint of the lang Wikipedia, "en" when empty, instead of
+// scraping its HTML. The result carries the page's title, short description,
+// lead paragraph, thumbnail and canonical URL, with a quad for each of the
+// description, summary and thumbnail the page has, but no infobox data. With
+// Options.PrivateWiki set, the wiki's own REST API is used and lang is ignored.
+func (e *Extractor) ExtractSummary(title, lang string) (*ExtractResult, error) {
+	endpoint, err := e.summaryURL(title, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	maxBodyBytes := e.maxBodyBytes()
+
+	var body []byte
+	var statusCode int
+	var tooLarge bool
+	var decodeErr error
+
+	// Use a clone so callbacks don't accumulate on the shared collector
+	c := e.colly.Clone()
+	c.MaxBodySize = maxBodyBytes + 1
+
+	c.OnRequest(func(r *colly.Request) {
+		if e.Options.PrivateWiki != nil {
+			e.Options.PrivateWiki.authorize(r)
+		}
+		r.Headers.Set("Accept", "application/json")
+	})
+
+	c.OnResponse(func(r *colly.Response) {
+		body, decodeErr = decodeBody(r.Headers.Get("Content-Encoding"), r.Body, maxBodyBytes)
+		tooLarge = len(body) > maxBodyBytes
+	})
+
+	c.OnError(func(r *colly.Response, err error) {
+		statusCode = r.StatusCode
+	})
+
+	err = c.Visit(endpoint)
+	if tooLarge {
+		return nil, &FetchError{URL: endpoint, Err: fmt.Errorf("%w of %d bytes", ErrResponseTooLarge, maxBodyBytes)}
+	}
+	if decodeErr != nil {
+		return nil, &FetchError{URL: endpoint, Err: decodeErr}
+	}
+	if statusCode >= http.StatusBadRequest {
+		return nil, &StatusError{URL: endpoint, StatusCode: statusCode}
+	}
+	if err != nil {
+		return nil, &FetchError{URL: endpoint, Err: err}
+	}
+
+	var summary restSummary
+	if err := json.Unmarshal(body, &summary); err != nil {
+		return nil, &FetchError{URL: endpoint, Err: fmt.Errorf("invalid page summary: %w", err)}
+	}
+	if summary.Type == "disambiguation" {
+		return nil, fmt.Errorf("%w: %s", ErrDisambiguation, endpoint)
+	}
+	return e.summaryResult(summary), nil
+}
+
+// summaryURL returns the REST API URL of the summary of the page titled
+// title on the lang Wikipedia, or on Options.PrivateWiki when it is set
+func (e *Extractor) summaryURL(title, lang string) (string, error) {
+	title = strings.ReplaceAll(strings.TrimSpace(title), " ", "_")
+	if title == "" {
+		return "", fmt.Errorf("%w: empty page title", ErrInvalidURL)
+	}
+
+	base := ""
+	if e.Options.PrivateWiki != nil {
+		base = strings.TrimSuffix(e.Options.PrivateWiki.BaseURL, "/")
+	} else {
+		if lang == "" {
+			lang = "en"
+		}
+		lang = strings.ToLower(lang)
+		if !languageCode.MatchString(lang) {
+			return "", fmt.Errorf("%w: invalid language code %q", ErrInvalidURL, lang)
+		}
+		base = "https://" + lang + ".wikipedia.org"
+	}
+	return base + summaryPath + url.PathEscape(title), nil
+}
+
+// summaryResult turns a page summary into an extraction result, applying the
+// options that make sense without an infobox
+func (e *Extractor) summaryResult(summary restSummary) *ExtractResult {
+	title := summary.Titles.Normalized
+	if title == "" {
+		title = strings.ReplaceAll(summary.Title, "_", " ")
+	}
+	result := &ExtractResult{
+		Title:            title,
+		PageID:           summary.PageID,
+		ShortDescription: summary.Description,
+		Summary:          strings.TrimSpace(summary.Extract),
+		Thumbnail:        summary.Thumbnail.Source,
+	}
+	result.RevisionID, _ = strconv.ParseInt(summary.Revision, 10, 64)
+	if t, err := time.Parse(time.RFC3339, summary.Timestamp); err == nil {
+		result.PageLastModified = t.UTC()
+	}
+
+	if result.ShortDescription != "" {
+		result.Quads = append(result.Quads, Quad{Subject: title, Relationship: DescriptionRelationship, Value: result.ShortDescription})
+	}
+	if result.Summary != "" {
+		result.Quads = append(result.Quads, Quad{Subject: title, Relationship: SummaryRelationship, Value: result.Summary})
+	}
+	if result.Thumbnail != "" {
+		result.Quads = append(result.Quads, Quad{Subject: title, Relationship: ThumbnailRelationship, Value: result.Thumbnail, ValueURL: result.Thumbnail})
+	}
+
+	source := summary.ContentURLs.Desktop.Page
+	result.setSubjectURL(source)
+	if e.Options.Subject != "" {
+		result.setSubject(e.Options.Subject)
+	}
+	project := ProjectForURL(source)
+	for i := range result.Quads {
+		result.Quads[i].Source = source
+		result.Quads[i].Project = project
+	}
+	e.limitLengths(result)
+
+	if len(e.Options.IncludeRelationships) > 0 || len(e.Options.ExcludeRelationships) > 0 {
+		result.Quads = FilterRelationships(result.Quads, e.Options.IncludeRelationships, e.Options.ExcludeRelationships)
+	}
+	return result
+}
+
+// SummaryTitle returns the title and language of the page at a /wiki/ URL
+// such as https://fr.wikipedia.org/wiki/Paris, the arguments ExtractSummary
+// takes. Hosts other than a language Wikipedia, such as a private wiki, give
+// an empty language.
+func SummaryTitle(rawURL string) (title, lang string, err error) {
+	u, err := url.Parse(DesktopURL(rawURL))
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	escaped, ok := strings.CutPrefix(u.EscapedPath(), "/wiki/")
+	if !ok || escaped == "" {
+		return "", "", fmt.Errorf("%w: %s is not a /wiki/ page URL", ErrInvalidURL, rawURL)
+	}
+	title, err = url.PathUnescape(escaped)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if label, ok := strings.CutSuffix(host, ".wikipedia.org"); ok && languageCode.MatchString(label) {
+		lang = label
+	}
+	return title, lang, nil
+}
@@ -0,0 +1,81 @@
+package extractor
+
+import "testing"
+
+func TestParseTableSpannedCells(t *testing.T) {
+	e := NewExtractor()
+	e.Options.Relationships = nil
+	result := parseFixture(t, e, "spanned_table.html")
+
+	const (
+		districts = "https://districts.example.org/"
+		languages = "https://languages.example.org/"
+	)
+	// A label spanning several rows labels each of their values, and a
+	// value or heading spanning the whole row is not read as a pair
+	assertQuads(t, result.Quads, []quadFields{
+		{"Exampleburg", "Founded", "1203", ""},
+		{"Exampleburg", "Languages", "Examplish", ""},
+		{"Exampleburg", "Languages", "Samplese[2]", languages},
+		{"Exampleburg", "Twin towns", "Sampleton", ""},
+		{"Exampleburg", "Districts", "Old Town[1]", districts},
+		{"Exampleburg", "Districts", "Harbour", ""},
+		{"Exampleburg", "Districts", "Hillside", ""},
+		{"Exampleburg", "Mayor", "Ann Example", ""},
+	})
+	for _, q := range result.Quads {
+		if q.Section != "Facts" {
+			t.Errorf("%s: section = %q, want %q", q.Relationship, q.Section, "Facts")
+		}
+	}
+}
+
+func TestBuildTableGrid(t *testing.T) {
+	doc := loadFixture(t, "spanned_table.html")
+	grid := buildTableGrid(doc.Find("table.wikitable"))
+
+	if len(grid) != 10 {
+		t.Fatalf("got %d rows, want 10", len(grid))
+	}
+	for r, row := range grid {
+		if len(row) < 2 || row[0] == nil || row[1] == nil {
+			t.Errorf("row %d has gaps: %d columns", r, len(row))
+		}
+	}
+	if !grid[2][0].IsSelection(grid[3][0]) {
+		t.Error("rowspan=2 label not repeated in the next row")
+	}
+	if !grid[5][0].IsSelection(grid[7][0]) {
+		t.Error("rowspan=3 label not repeated in the third row")
+	}
+	if len(grid[4]) != 3 || !grid[4][1].IsSelection(grid[4][2]) {
+		t.Error("colspan=2 value not repeated in the third column")
+	}
+	if !grid[8][0].IsSelection(grid[8][1]) {
+		t.Error("colspan=2 cell not repeated in the second column")
+	}
+}
+
+func TestSpanAttr(t *testing.T) {
+	doc := loadFixture(t, "spanned_table.html")
+	tests := []struct {
+		html string
+		name string
+		want int
+	}{
+		{`<td>x</td>`, "rowspan", 1},
+		{`<td rowspan="3">x</td>`, "rowspan", 3},
+		{`<td rowspan="0">x</td>`, "rowspan", 0},
+		{`<td colspan="0">x</td>`, "colspan", 1},
+		{`<td colspan="-2">x</td>`, "colspan", 1},
+		{`<td colspan="two">x</td>`, "colspan", 1},
+		{`<td colspan=" 2 ">x</td>`, "colspan", 2},
+		{`<td rowspan="99999">x</td>`, "rowspan", maxSpan},
+	}
+	for _, tt := range tests {
+		body := doc.Find("body").SetHtml("<table><tr>" + tt.html + "</tr></table>")
+		if got := spanAttr(body.Find("td"), tt.name); got != tt.want {
+			t.Errorf("spanAttr(%s, %s) = %d, want %d", tt.html, tt.name, got, tt.want)
+		}
+	}
+}
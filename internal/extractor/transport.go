@@ -0,0 +1,48 @@
+package extractor
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultTimeout is how long a page fetch may take before it is abandoned,
+// matching colly's default
+const DefaultTimeout = 10 * time.Second
+
+// SetTransport replaces the transport used to fetch pages, e.g. to tune TLS
+// settings, keep-alives or connection pooling. Like proxies and rate limits it
+// configures the extractor's collector, so it applies to every extraction,
+// including concurrent ones; call it before extracting and before SetProxies,
+// which sets the proxy on the transport in place when it is an *http.Transport.
+func (e *Extractor) SetTransport(transport http.RoundTripper) {
+	e.colly.WithTransport(transport)
+}
+
+// SetHTTPClient adopts the transport and timeout of client. The collector
+// keeps its own cookie jar and redirect handling, which extraction relies on.
+func (e *Extractor) SetHTTPClient(client *http.Client) {
+	if client.Transport != nil {
+		e.SetTransport(client.Transport)
+	}
+	e.colly.SetRequestTimeout(client.Timeout)
+}
+
+// SetTimeout limits how long a page fetch may take, including reading the
+// body. Zero means no limit.
+func (e *Extractor) SetTimeout(timeout time.Duration) error {
+	if timeout < 0 {
+		return fmt.Errorf("invalid timeout: must not be negative, got %s", timeout)
+	}
+	e.colly.SetRequestTimeout(timeout)
+	return nil
+}
+
+// InsecureTransport returns a copy of http.DefaultTransport that accepts any
+// TLS certificate, for wikis behind self-signed or internal certificates
+func InsecureTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	return transport
+}
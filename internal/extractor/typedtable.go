@@ -0,0 +1,251 @@
+package extractor
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Value types assigned to quads whose values were recognized and normalized
+const (
+	// ValueTypePercent marks a percentage normalized to a fraction, e.g. "45.3%" as "0.453"
+	ValueTypePercent = "percent"
+	// ValueTypeRank marks a position normalized to an integer, e.g. "T-2nd" as "2"
+	ValueTypeRank = "rank"
+)
+
+// rankHeaders are the header labels, lowercased, that mark a rank column
+var rankHeaders = map[string]bool{
+	"rank":     true,
+	"rank.":    true,
+	"pos":      true,
+	"pos.":     true,
+	"position": true,
+	"place":    true,
+	"#":        true,
+}
+
+// percentHeaders are the header labels, lowercased, that mark a percent column
+// in addition to any header containing "%"
+var percentHeaders = map[string]bool{
+	"percent":    true,
+	"percentage": true,
+	"share":      true,
+	"pct":        true,
+	"pct.":       true,
+}
+
+var (
+	// footnoteMarker matches reference markers such as "[1]" or "[a]" left in cell text
+	footnoteMarker = regexp.MustCompile(`\[[^\]]*\]`)
+
+	// rankPattern matches ranks such as "1", "1st", "T-2", "T2", "=3" and "4="
+	rankPattern = regexp.MustCompile(`(?i)^(?:t-?|=)?(\d+)(?:st|nd|rd|th)?=?$`)
+)
+
+// parseTypedTable extracts quads from a multi-column table with a header row
+// and at least one rank or percent column, such as election results or a
+// league table. Each data row becomes a record: its first untyped column with
+// text names the subject and every remaining cell becomes a quad whose relationship is
+// the column header. Rank and percent values are normalized and typed. It
+// returns false when the table does not have that shape, so the caller can
+// fall back to parseTable.
+func (e *Extractor) parseTypedTable(table *goquery.Selection, references map[string]string) ([]Quad, bool) {
+	grid := buildTableGrid(table)
+
+	headerIndex := -1
+	for i, row := range grid {
+		if isHeaderRow(row) {
+			headerIndex = i
+			break
+		}
+	}
+	if headerIndex < 0 || len(grid[headerIndex]) < 3 {
+		return nil, false
+	}
+
+	headers := make([]string, len(grid[headerIndex]))
+	for i, cell := range grid[headerIndex] {
+		if cell != nil {
+			headers[i] = cleanCellText(cell)
+		}
+	}
+
+	rows := grid[headerIndex+1:]
+	types := make([]string, len(headers))
+	typed := false
+	for col, header := range headers {
+		types[col] = columnValueType(header, rows, col)
+		if types[col] != "" {
+			typed = true
+		}
+	}
+	if !typed {
+		return nil, false
+	}
+
+	section := sectionHeading(table)
+	var quads []Quad
+	for _, row := range rows {
+		if isHeaderRow(row) {
+			continue
+		}
+		keyColumn := recordKeyColumn(row, headers, types)
+		if keyColumn < 0 {
+			continue
+		}
+		subject := cleanCellText(row[keyColumn])
+
+		for col, cell := range row {
+			if col == keyColumn || col >= len(headers) || cell == nil || headers[col] == "" {
+				continue
+			}
+			// Cells spanning several columns (or into the key column) are read once
+			if cell.IsSelection(row[keyColumn]) || (col > 0 && cell.IsSelection(row[col-1])) {
+				continue
+			}
+
+			value := strings.TrimSpace(cell.Text())
+			if value == "" {
+				continue
+			}
+
+			quad := Quad{
+				Subject:      subject,
+				Relationship: headers[col],
+				Value:        value,
+				Citation:     e.extractCitations(cell, references),
+				Section:      section,
+			}
+			if normalized, ok := normalizeValue(types[col], value); ok {
+				quad.Value = normalized
+				quad.ValueType = types[col]
+				quad.RawValue = value
+			}
+			quad.RawHTML = e.rawHTML(cell)
+			quads = append(quads, quad)
+		}
+	}
+
+	return quads, true
+}
+
+// recordKeyColumn returns the column naming a row's record: the first
+// untyped column with text. Election tables often lead with an empty party
+// colour cell under the same header, which this skips. It returns -1 when the
+// row has no such column.
+func recordKeyColumn(row []*goquery.Selection, headers, types []string) int {
+	for col, cell := range row {
+		if col >= len(headers) || cell == nil || headers[col] == "" || types[col] != "" {
+			continue
+		}
+		if cleanCellText(cell) != "" {
+			return col
+		}
+	}
+	return -1
+}
+
+// isHeaderRow reports whether every cell of a grid row is a th cell
+func isHeaderRow(row []*goquery.Selection) bool {
+	found := false
+	for _, cell := range row {
+		if cell == nil {
+			continue
+		}
+		if !cell.Is("th") {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// columnValueType classifies a column as a rank or percent column from its
+// header, or as a percent column when every value in it ends with "%"
+func columnValueType(header string, rows [][]*goquery.Selection, col int) string {
+	lower := strings.ToLower(header)
+	if rankHeaders[lower] {
+		return ValueTypeRank
+	}
+	if strings.Contains(lower, "%") || percentHeaders[lower] {
+		return ValueTypePercent
+	}
+
+	values := 0
+	for _, row := range rows {
+		if col >= len(row) || row[col] == nil || isHeaderRow(row) {
+			continue
+		}
+		value := cleanCellText(row[col])
+		if value == "" {
+			continue
+		}
+		if !strings.HasSuffix(value, "%") {
+			return ""
+		}
+		values++
+	}
+	if values > 0 {
+		return ValueTypePercent
+	}
+	return ""
+}
+
+// cleanCellText returns the text of a cell without footnote markers
+func cleanCellText(cell *goquery.Selection) string {
+	return strings.TrimSpace(footnoteMarker.ReplaceAllString(cell.Text(), ""))
+}
+
+// normalizeValue normalizes a value of the given type, reporting false when
+// the value does not parse as that type
+func normalizeValue(valueType, value string) (string, bool) {
+	value = strings.TrimSpace(footnoteMarker.ReplaceAllString(value, ""))
+	switch valueType {
+	case ValueTypePercent:
+		return normalizePercent(value)
+	case ValueTypeRank:
+		return normalizeRank(value)
+	default:
+		return "", false
+	}
+}
+
+// normalizePercent converts a percentage such as "45.3%" or "45.3" to a
+// fraction such as "0.453"
+func normalizePercent(value string) (string, bool) {
+	value = strings.TrimSpace(strings.TrimSuffix(value, "%"))
+	value = strings.ReplaceAll(value, "−", "-")
+	value = strings.ReplaceAll(value, ",", "")
+
+	percent, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return "", false
+	}
+
+	// Keep the precision given on the page: two more decimals than the percentage
+	decimals := 2
+	if dot := strings.IndexByte(value, '.'); dot >= 0 {
+		decimals += len(value) - dot - 1
+	}
+	fraction := strconv.FormatFloat(percent/100, 'f', decimals, 64)
+	if strings.Contains(fraction, ".") {
+		fraction = strings.TrimRight(strings.TrimRight(fraction, "0"), ".")
+	}
+	return fraction, true
+}
+
+// normalizeRank converts a position such as "1st", "T-2" or "=3" to its number
+func normalizeRank(value string) (string, bool) {
+	match := rankPattern.FindStringSubmatch(strings.ReplaceAll(value, " ", ""))
+	if match == nil {
+		return "", false
+	}
+	rank, err := strconv.Atoi(match[1])
+	if err != nil {
+		return "", false
+	}
+	return strconv.Itoa(rank), true
+}
@@ -0,0 +1,145 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestParseTypedTableElectionResults(t *testing.T) {
+	e := NewExtractor()
+	e.Options.Relationships = nil
+	e.Options.TypedTables = true
+	result := parseFixture(t, e, "election_results.html")
+
+	const results = "https://elections.example.org/2020/results"
+	const page = "2020 Examplia general election"
+	// Each row is a record named by its party; the colour column has no
+	// text and is skipped, and the Total row spans the columns before Votes.
+	// The two-column Turnout table is still read as label/value pairs.
+	assertQuads(t, result.Quads, []quadFields{
+		{"Blue Party", "Rank", "1", ""},
+		{"Blue Party", "Candidate", "Alice Example", ""},
+		{"Blue Party", "Votes", "1,204,511", ""},
+		{"Blue Party", "%", "0.453", results},
+		{"Blue Party", "Seats", "62", ""},
+		{"Red Party", "Rank", "2", ""},
+		{"Red Party", "Candidate", "Bob Sample", ""},
+		{"Red Party", "Votes", "1,090,022", ""},
+		{"Red Party", "%", "0.41", ""},
+		{"Red Party", "Seats", "51", ""},
+		{"Green Party", "Rank", "3", ""},
+		{"Green Party", "Candidate", "Carol Instance", ""},
+		{"Green Party", "Votes", "181,117", ""},
+		{"Green Party", "%", "0.0685", ""},
+		{"Green Party", "Seats", "4", ""},
+		{"Yellow Party", "Rank", "3", ""},
+		{"Yellow Party", "Candidate", "Dan Placeholder", ""},
+		{"Yellow Party", "Votes", "181,117", ""},
+		{"Yellow Party", "%", "0.0685", ""},
+		{"Yellow Party", "Seats", "4", ""},
+		{"Total", "Votes", "2,656,767", ""},
+		{"Total", "%", "1", ""},
+		{"Total", "Seats", "121", ""},
+		{page, "Registered voters", "3,950,000", ""},
+		{page, "Turnout", "67.3%", ""},
+	})
+
+	types := map[string]string{"Rank": ValueTypeRank, "%": ValueTypePercent}
+	for _, q := range result.Quads {
+		if q.ValueType != types[q.Relationship] {
+			t.Errorf("%s %s: value type = %q, want %q", q.Subject, q.Relationship, q.ValueType, types[q.Relationship])
+		}
+		if q.ValueType != "" && q.RawValue == "" {
+			t.Errorf("%s %s: typed value without its raw value", q.Subject, q.Relationship)
+		}
+	}
+	if q, _ := findQuad(result.Quads, "Rank"); q.RawValue != "1st" {
+		t.Errorf("raw rank = %q, want %q", q.RawValue, "1st")
+	}
+}
+
+// Without TypedTables, tables with a rank or percent header are read as
+// label/value pairs, exactly as parseTable reads them
+func TestParseTypedTableOptIn(t *testing.T) {
+	pages := map[string]string{
+		"election results": "election_results.html",
+		"episode list":     "",
+	}
+	const episodes = `<html><head><title>Sample Show - Wikipedia</title></head><body>
+<h1 id="firstHeading">Sample Show</h1>
+<div class="mw-parser-output">
+<table class="wikitable">
+<tr><th>#</th><th>Title</th><th>Viewers (%)</th></tr>
+<tr><td>1</td><td>Pilot</td><td>12%</td></tr>
+<tr><td>2</td><td>The Return</td><td>10%</td></tr>
+</table>
+<table class="wikitable">
+<tr><th>Network</th><td>Example One</td></tr>
+</table>
+</div></body></html>`
+
+	for name, fixture := range pages {
+		t.Run(name, func(t *testing.T) {
+			var doc *goquery.Document
+			if fixture != "" {
+				doc = loadFixture(t, fixture)
+			} else {
+				var err error
+				if doc, err = goquery.NewDocumentFromReader(strings.NewReader(episodes)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			e := NewExtractor()
+			e.Options.Relationships = nil
+			result, err := e.ParseDocument(doc.Selection)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			title := doc.Find("h1#firstHeading").Text()
+			references := e.extractReferences(doc.Selection)
+			var want []Quad
+			doc.Find("table.wikitable").Each(func(i int, s *goquery.Selection) {
+				want = append(want, e.parseTable(s, title, references)...)
+			})
+			if len(want) == 0 {
+				t.Fatal("fixture has no label/value pairs")
+			}
+			assertQuads(t, result.Quads, fieldsOf(want))
+			for _, q := range result.Quads {
+				if q.ValueType != "" {
+					t.Errorf("%s: value type %q without --typed-tables", q.Relationship, q.ValueType)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeTypedValues(t *testing.T) {
+	tests := []struct {
+		fn    func(string) (string, bool)
+		value string
+		want  string
+		ok    bool
+	}{
+		{normalizePercent, "45.3%", "0.453", true},
+		{normalizePercent, "6.85%", "0.0685", true},
+		{normalizePercent, "100%", "1", true},
+		{normalizePercent, "−2.5%", "-0.025", true},
+		{normalizePercent, "n/a", "", false},
+		{normalizeRank, "1st", "1", true},
+		{normalizeRank, "T-3", "3", true},
+		{normalizeRank, "=3", "3", true},
+		{normalizeRank, "4=", "4", true},
+		{normalizeRank, "Total", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := tt.fn(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("normalize(%q) = %q, %v, want %q, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
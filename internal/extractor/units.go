@@ -0,0 +1,116 @@
+package extractor
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ValueTypeMeasurement marks a value given in both metric and imperial units
+const ValueTypeMeasurement = "measurement"
+
+// unitSystem is the system of measurement a unit belongs to
+type unitSystem int
+
+const (
+	noUnitSystem unitSystem = iota
+	metricUnits
+	imperialUnits
+)
+
+// unitSystems maps the units written by the {{convert}} template, in both
+// abbreviated and spelled out form, to their system. US customary units
+// count as imperial. Multi-word units are joined with "_" before lookup.
+var unitSystems = map[string]unitSystem{
+	"mm": metricUnits, "cm": metricUnits, "m": metricUnits, "km": metricUnits,
+	"millimetres": metricUnits, "millimeters": metricUnits, "centimetres": metricUnits, "centimeters": metricUnits,
+	"metres": metricUnits, "meters": metricUnits, "metre": metricUnits, "meter": metricUnits,
+	"kilometres": metricUnits, "kilometers": metricUnits, "kilometre": metricUnits, "kilometer": metricUnits,
+	"m2": metricUnits, "m²": metricUnits, "km2": metricUnits, "km²": metricUnits, "ha": metricUnits, "hectares": metricUnits,
+	"square_metres": metricUnits, "square_meters": metricUnits, "square_kilometres": metricUnits, "square_kilometers": metricUnits,
+	"m3": metricUnits, "m³": metricUnits, "cm3": metricUnits, "cm³": metricUnits, "cc": metricUnits,
+	"l": metricUnits, "ml": metricUnits, "litres": metricUnits, "liters": metricUnits,
+	"g": metricUnits, "kg": metricUnits, "t": metricUnits, "tonnes": metricUnits,
+	"grams": metricUnits, "kilograms": metricUnits,
+	"°c": metricUnits, "km/h": metricUnits, "m/s": metricUnits, "kw": metricUnits, "kpa": metricUnits,
+
+	"in": imperialUnits, "ft": imperialUnits, "yd": imperialUnits, "mi": imperialUnits,
+	"inches": imperialUnits, "inch": imperialUnits, "feet": imperialUnits, "foot": imperialUnits,
+	"yards": imperialUnits, "miles": imperialUnits, "mile": imperialUnits,
+	"sq_in": imperialUnits, "sq_ft": imperialUnits, "sq_mi": imperialUnits, "acres": imperialUnits, "acre": imperialUnits,
+	"square_feet": imperialUnits, "square_miles": imperialUnits,
+	"cu_in": imperialUnits, "cu_ft": imperialUnits, "cu_yd": imperialUnits,
+	"gal": imperialUnits, "us_gal": imperialUnits, "imp_gal": imperialUnits, "gallons": imperialUnits,
+	"oz": imperialUnits, "lb": imperialUnits, "st": imperialUnits, "pounds": imperialUnits, "ounces": imperialUnits,
+	"long_tons": imperialUnits, "short_tons": imperialUnits,
+	"°f": imperialUnits, "mph": imperialUnits, "hp": imperialUnits, "lbf": imperialUnits, "psi": imperialUnits,
+}
+
+// convertedValue matches a value followed by a parenthesized conversion, the
+// way {{convert}} renders "100 km (62 mi)"
+var convertedValue = regexp.MustCompile(`^(.+?)\s*\(([^()]+)\)$`)
+
+// measurementNumber matches the number of a measurement, such as "1,500",
+// "−40", "~3.5" or the range "10–20"
+var measurementNumber = regexp.MustCompile(`^[−\-~]?\d[\d,.]*(?:[–\-]\d[\d,.]*)?$`)
+
+// multiWordUnit matches the first word of a unit written as two words
+var multiWordUnit = regexp.MustCompile(`(?i)\b(sq|cu|square|us|imp|long|short) `)
+
+// splitMeasurement splits a value shown in both metric and imperial units,
+// such as "100 km (62 mi)" or "29,032 ft (8,849 m)", into its metric and
+// imperial parts. ok is false unless one part is entirely metric and the
+// other entirely imperial.
+func splitMeasurement(value string) (metric, imperial string, ok bool) {
+	value = footnoteMarker.ReplaceAllString(value, "")
+	match := convertedValue.FindStringSubmatch(strings.Join(strings.Fields(value), " "))
+	if match == nil {
+		return "", "", false
+	}
+	first, second := strings.TrimSpace(match[1]), strings.TrimSpace(match[2])
+	switch systems := [2]unitSystem{measurementSystem(first), measurementSystem(second)}; systems {
+	case [2]unitSystem{metricUnits, imperialUnits}:
+		return first, second, true
+	case [2]unitSystem{imperialUnits, metricUnits}:
+		return second, first, true
+	default:
+		return "", "", false
+	}
+}
+
+// measurementSystem returns the unit system of a measurement such as
+// "62 mi" or "6 ft 2 in": numbers each followed by a unit of one system
+func measurementSystem(measurement string) unitSystem {
+	fields := strings.Fields(multiWordUnit.ReplaceAllString(measurement, "${1}_"))
+	if len(fields) < 2 || len(fields)%2 != 0 {
+		return noUnitSystem
+	}
+
+	system := noUnitSystem
+	for i := 0; i < len(fields); i += 2 {
+		if !measurementNumber.MatchString(fields[i]) {
+			return noUnitSystem
+		}
+		unit := unitSystems[strings.ToLower(fields[i+1])]
+		if unit == noUnitSystem || (system != noUnitSystem && unit != system) {
+			return noUnitSystem
+		}
+		system = unit
+	}
+	return system
+}
+
+// splitMeasurements sets Metric and Imperial on the quads whose value is
+// given in both systems of units
+func splitMeasurements(quads []Quad) {
+	for i := range quads {
+		metric, imperial, ok := splitMeasurement(quads[i].Value)
+		if !ok {
+			continue
+		}
+		quads[i].Metric = metric
+		quads[i].Imperial = imperial
+		if quads[i].ValueType == "" {
+			quads[i].ValueType = ValueTypeMeasurement
+		}
+	}
+}
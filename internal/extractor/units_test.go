@@ -0,0 +1,74 @@
+package extractor
+
+import "testing"
+
+func TestSplitUnitsConvertedMeasurements(t *testing.T) {
+	type measurement struct {
+		Relationship string
+		Metric       string
+		Imperial     string
+		ValueType    string
+	}
+	want := []measurement{
+		{"Elevation", "8,848.86 m", "29,031.7 ft", ValueTypeMeasurement},
+		{"Prominence", "8,849 m", "29,032 ft", ValueTypeMeasurement},
+		{"Length", "100 km", "62 mi", ValueTypeMeasurement},
+		{"Area", "36.6 km2", "14.1 sq mi", ValueTypeMeasurement},
+		{"Summit height", "1.83 m", "6 ft 0 in", ValueTypeMeasurement},
+		{"Temperature", "−40 °C", "−40 °F", ValueTypeMeasurement},
+		{"Location", "", "", ""},
+		{"Range", "10–20 km", "6.2–12.4 mi", ValueTypeMeasurement},
+	}
+
+	e := NewExtractor()
+	e.Options.SplitUnits = true
+	result := parseFixture(t, e, "converted_measurements.html")
+	if len(result.Quads) != len(want) {
+		t.Fatalf("got %d quads, want %d", len(result.Quads), len(want))
+	}
+	for i, q := range result.Quads {
+		got := measurement{q.Relationship, q.Metric, q.Imperial, q.ValueType}
+		if got != want[i] {
+			t.Errorf("quad %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+	if q := result.Quads[0]; q.Citation != "https://survey.example.org/heights" {
+		t.Errorf("Elevation citation = %q, want it kept alongside the split", q.Citation)
+	}
+
+	// The values themselves are left as written, and nothing is split
+	// unless asked
+	e.Options.SplitUnits = false
+	result = parseFixture(t, e, "converted_measurements.html")
+	if q, _ := findQuad(result.Quads, "Length"); q.Value != "100 km (62 mi)" || q.Metric != "" || q.Imperial != "" {
+		t.Errorf("Length without SplitUnits = %+v", q)
+	}
+}
+
+func TestSplitMeasurement(t *testing.T) {
+	tests := []struct {
+		value    string
+		metric   string
+		imperial string
+		ok       bool
+	}{
+		{"100 km (62 mi)", "100 km", "62 mi", true},
+		{"29,032 ft (8,849 m)", "8,849 m", "29,032 ft", true},
+		{"5,000 square kilometres (1,900 sq mi)", "5,000 square kilometres", "1,900 sq mi", true},
+		{"~3.5 kg (7.7 lb)", "~3.5 kg", "7.7 lb", true},
+		// Knots are neither metric nor imperial
+		{"20 kn (37 km/h)", "", "", false},
+		{"20 kn (23 mph)", "", "", false},
+		{"100 km (100 km)", "", "", false},
+		{"12 m (39 ft; 13 yd)", "", "", false},
+		{"Exampleland (Northern Province)", "", "", false},
+		{"1990 (aged 40)", "", "", false},
+	}
+	for _, tt := range tests {
+		metric, imperial, ok := splitMeasurement(tt.value)
+		if metric != tt.metric || imperial != tt.imperial || ok != tt.ok {
+			t.Errorf("splitMeasurement(%q) = %q, %q, %v, want %q, %q, %v",
+				tt.value, metric, imperial, ok, tt.metric, tt.imperial, tt.ok)
+		}
+	}
+}
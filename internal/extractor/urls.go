@@ -0,0 +1,130 @@
+package extractor
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// sisterProjects maps the domains of Wikimedia sister projects, which share
+// Wikipedia's MediaWiki markup, to the project name recorded on their quads
+var sisterProjects = map[string]string{
+	"wiktionary.org":        "wiktionary",
+	"wikivoyage.org":        "wikivoyage",
+	"wikiquote.org":         "wikiquote",
+	"wikibooks.org":         "wikibooks",
+	"wikisource.org":        "wikisource",
+	"wikinews.org":          "wikinews",
+	"wikiversity.org":       "wikiversity",
+	"species.wikimedia.org": "wikispecies",
+}
+
+// ValidateWikipediaURL checks that rawURL is an absolute http(s) URL on a
+// wikipedia.org host. The returned error wraps ErrInvalidURL.
+func ValidateWikipediaURL(rawURL string) error {
+	return validateURL(rawURL, nil, false)
+}
+
+// ValidateWikimediaURL checks that rawURL is an absolute http(s) URL on a
+// wikipedia.org host or on a Wikimedia sister project such as wiktionary.org
+// or wikivoyage.org. The returned error wraps ErrInvalidURL.
+func ValidateWikimediaURL(rawURL string) error {
+	return validateURL(rawURL, nil, true)
+}
+
+// ValidateURL checks that rawURL can be extracted with the extractor's
+// options: a Wikipedia page, or a page on one of Options.AllowedHosts, the
+// Options.PrivateWiki host, or, with Options.AllowSisterProjects, a Wikimedia
+// sister project. The returned error wraps ErrInvalidURL.
+func (e *Extractor) ValidateURL(rawURL string) error {
+	allowedHosts := e.Options.AllowedHosts
+	if e.Options.PrivateWiki != nil {
+		allowedHosts = append(allowedHosts[:len(allowedHosts):len(allowedHosts)], e.Options.PrivateWiki.host)
+	}
+	return validateURL(rawURL, allowedHosts, e.Options.AllowSisterProjects)
+}
+
+// ProjectForURL returns the Wikimedia project a URL belongs to, such as
+// "wikipedia" or "wiktionary", or an empty string for other hosts
+func ProjectForURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return projectForHost(u.Hostname())
+}
+
+// projectForHost returns the Wikimedia project serving host, or an empty string
+func projectForHost(host string) string {
+	host = strings.ToLower(host)
+	if host == "wikipedia.org" || strings.HasSuffix(host, ".wikipedia.org") {
+		return "wikipedia"
+	}
+	for domain, project := range sisterProjects {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return project
+		}
+	}
+	return ""
+}
+
+// validateURL checks that rawURL is an absolute http(s) URL on a wikipedia.org
+// host or on one of allowedHosts. Allowed hosts may include a port. When
+// allowSisterProjects is set, hosts of Wikimedia sister projects are accepted too.
+func validateURL(rawURL string, allowedHosts []string, allowSisterProjects bool) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: unsupported scheme %q", ErrInvalidURL, u.Scheme)
+	}
+
+	for _, allowed := range allowedHosts {
+		if strings.EqualFold(u.Host, allowed) || strings.EqualFold(u.Hostname(), allowed) {
+			return nil
+		}
+	}
+
+	switch project := projectForHost(u.Hostname()); {
+	case project == "wikipedia":
+	case project != "" && allowSisterProjects:
+	case project != "":
+		return fmt.Errorf("%w: %s is a %s host; sister projects must be enabled explicitly", ErrInvalidURL, u.Host, project)
+	default:
+		return fmt.Errorf("%w: %s is not a Wikipedia host", ErrInvalidURL, u.Host)
+	}
+
+	return nil
+}
+
+// DesktopURL rewrites a URL on a mobile Wikimedia domain, such as
+// https://en.m.wikipedia.org/wiki/Go, to the same page on the desktop domain,
+// https://en.wikipedia.org/wiki/Go. Other URLs are returned unchanged.
+func DesktopURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	// The mobile label sits before the registered domain: en.m.wikipedia.org, species.m.wikimedia.org
+	labels := strings.Split(u.Hostname(), ".")
+	desktop := labels[:0:0]
+	for i, label := range labels {
+		if strings.EqualFold(label, "m") && i < len(labels)-2 {
+			continue
+		}
+		desktop = append(desktop, label)
+	}
+	host := strings.Join(desktop, ".")
+	if len(desktop) == len(labels) || projectForHost(host) == "" {
+		return rawURL
+	}
+
+	if port := u.Port(); port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+	return u.String()
+}
@@ -0,0 +1,25 @@
+package extractor
+
+import "testing"
+
+func TestDesktopURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://en.m.wikipedia.org/wiki/Go", "https://en.wikipedia.org/wiki/Go"},
+		{"https://EN.M.wikipedia.org/wiki/Go#History", "https://EN.wikipedia.org/wiki/Go#History"},
+		{"https://species.m.wikimedia.org/wiki/Panthera_leo", "https://species.wikimedia.org/wiki/Panthera_leo"},
+		{"https://de.m.wiktionary.org/wiki/Haus", "https://de.wiktionary.org/wiki/Haus"},
+		{"https://en.m.wikipedia.org:8443/wiki/Go", "https://en.wikipedia.org:8443/wiki/Go"},
+		{"https://en.wikipedia.org/wiki/Go", "https://en.wikipedia.org/wiki/Go"},
+		{"https://m.example.com/wiki/Go", "https://m.example.com/wiki/Go"},
+		{"https://en.m.example.org/wiki/Go", "https://en.m.example.org/wiki/Go"},
+		{"not a url\x7f", "not a url\x7f"},
+	}
+	for _, tt := range tests {
+		if got := DesktopURL(tt.url); got != tt.want {
+			t.Errorf("DesktopURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package extractor
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ValidationPolicy says what happens to a quad whose value fails the
+// validation rule of its relationship
+type ValidationPolicy string
+
+const (
+	// ValidationPolicyOff skips validation. It is the default.
+	ValidationPolicyOff ValidationPolicy = "off"
+
+	// ValidationPolicyFlag keeps failing quads, listing them in
+	// ExtractResult.ValidationFailures
+	ValidationPolicyFlag ValidationPolicy = "flag"
+
+	// ValidationPolicyDrop drops failing quads, listing them in
+	// ExtractResult.ValidationFailures
+	ValidationPolicyDrop ValidationPolicy = "drop"
+)
+
+// ParseValidationPolicy parses a validation policy name, with "" meaning
+// ValidationPolicyOff
+func ParseValidationPolicy(name string) (ValidationPolicy, error) {
+	switch policy := ValidationPolicy(strings.ToLower(strings.TrimSpace(name))); policy {
+	case "":
+		return ValidationPolicyOff, nil
+	case ValidationPolicyOff, ValidationPolicyFlag, ValidationPolicyDrop:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown validation policy %q (valid policies: off, flag, drop)", name)
+	}
+}
+
+// Validator names a check of a value's form
+type Validator string
+
+const (
+	// ValidatorURL accepts a web address, with or without its scheme, such
+	// as "https://example.com" or "example.com/about"
+	ValidatorURL Validator = "url"
+
+	// ValidatorDate accepts a value holding a date: an ISO date, a day or
+	// month with a year, as in "14 March 1879" or "March 1879", or a bare year
+	ValidatorDate Validator = "date"
+
+	// ValidatorNumber accepts a number written in the page language's
+	// format, optionally followed by a unit, as --normalize-numbers reads it
+	ValidatorNumber Validator = "number"
+)
+
+// ParseValidator parses a validator name
+func ParseValidator(name string) (Validator, error) {
+	switch validator := Validator(strings.ToLower(strings.TrimSpace(name))); validator {
+	case ValidatorURL, ValidatorDate, ValidatorNumber:
+		return validator, nil
+	default:
+		return "", fmt.Errorf("unknown validator %q (valid validators: url, date, number)", name)
+	}
+}
+
+// ValidationRules maps relationships to the validator their values must
+// pass. Lookups ignore case and surrounding whitespace.
+type ValidationRules map[string]Validator
+
+// defaultValidationRules validates the canonical relationships whose form is
+// known
+var defaultValidationRules = map[string]Validator{
+	"Website":    ValidatorURL,
+	"Born":       ValidatorDate,
+	"Died":       ValidatorDate,
+	"Founded":    ValidatorDate,
+	"Population": ValidatorNumber,
+}
+
+// DefaultValidationRules returns rules for the canonical relationships of
+// DefaultRelationshipMap whose form is known, e.g. "Website" must be a URL
+// and "Born" a date
+func DefaultValidationRules() ValidationRules {
+	rules := ValidationRules{}
+	for relationship, validator := range defaultValidationRules {
+		rules.Add(relationship, validator)
+	}
+	return rules
+}
+
+// Add sets the validator of relationship
+func (r ValidationRules) Add(relationship string, validator Validator) {
+	r[normalizeLabel(relationship)] = validator
+}
+
+// ValidationFailure is a quad whose value failed the validation rule of its
+// relationship
+type ValidationFailure struct {
+	Subject      string    `json:"subject"`
+	Relationship string    `json:"relationship"`
+	Value        string    `json:"value"`
+	Validator    Validator `json:"validator"`
+}
+
+var (
+	// isoDate matches a date written as 1879-03-14
+	isoDate = regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}\b`)
+
+	// dateYear matches a year of three or four digits
+	dateYear = regexp.MustCompile(`\b\d{3,4}\b`)
+
+	// bareYear matches a value that is only a year, such as "1879",
+	// "c. 1500" or "44 BC"
+	bareYear = regexp.MustCompile(`(?i)^(c\.\s*|circa\s+)?\d{1,4}(\s*(AD|BC|BCE|CE))?$`)
+
+	// dateWord matches the words of a value, to find a month name among them
+	dateWord = regexp.MustCompile(`\pL+`)
+)
+
+// isURL reports whether value is a web address, with or without its scheme
+func isURL(value string) bool {
+	if value == "" || strings.ContainsAny(value, " \t\n") {
+		return false
+	}
+	if !strings.Contains(value, "://") {
+		value = "http://" + value
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	host := u.Hostname()
+	dot := strings.LastIndex(host, ".")
+	return dot > 0 && dot < len(host)-1
+}
+
+// isDate reports whether value holds a date
+func isDate(value string) bool {
+	if isoDate.MatchString(value) || bareYear.MatchString(value) {
+		return true
+	}
+	if !dateYear.MatchString(value) {
+		return false
+	}
+	for _, word := range dateWord.FindAllString(value, -1) {
+		if _, ok := monthNames[strings.ToLower(word)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// valid reports whether a quad's value passes validator. lang is the page
+// language, which sets the number format.
+func (v Validator) valid(quad Quad, lang string) bool {
+	value := strings.TrimSpace(footnoteMarker.ReplaceAllString(quad.Value, ""))
+	switch v {
+	case ValidatorURL:
+		return isURL(value) || (quad.ValueURL != "" && isURL(quad.ValueURL))
+	case ValidatorDate:
+		return isDate(value)
+	case ValidatorNumber:
+		return quad.Number != "" || parseLocalizedNumber(value, numberFormatFor(lang)) != ""
+	default:
+		return true
+	}
+}
+
+// validateValues applies Options.ValidationPolicy to the quads whose value
+// fails the rule of their relationship in Options.ValidationRules, or
+// DefaultValidationRules when it is nil. lang is the page language.
+func (e *Extractor) validateValues(result *ExtractResult, lang string) {
+	policy := e.Options.ValidationPolicy
+	if policy == "" || policy == ValidationPolicyOff {
+		return
+	}
+	rules := e.Options.ValidationRules
+	if rules == nil {
+		rules = DefaultValidationRules()
+	}
+
+	kept := result.Quads[:0]
+	for _, quad := range result.Quads {
+		validator, ok := rules[normalizeLabel(quad.Relationship)]
+		if !ok || validator.valid(quad, lang) {
+			kept = append(kept, quad)
+			continue
+		}
+		result.ValidationFailures = append(result.ValidationFailures, ValidationFailure{
+			Subject:      quad.Subject,
+			Relationship: quad.Relationship,
+			Value:        quad.Value,
+			Validator:    validator,
+		})
+		if policy == ValidationPolicyFlag {
+			kept = append(kept, quad)
+		}
+	}
+	result.Quads = kept
+}
@@ -0,0 +1,176 @@
+package output
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+// AvroSchema is the Avro schema of the records written by the avro format,
+// one record per quad, for consumers to register with a schema registry.
+// Fields that are often empty are nullable, so empty values read back as
+// null rather than "".
+const AvroSchema = `{
+  "type": "record",
+  "name": "Quad",
+  "namespace": "com.github.chetankale.wikipedia_extraction",
+  "doc": "A fact extracted from a Wikipedia page: subject, relationship, value and citation, with provenance",
+  "fields": [
+    {"name": "subject", "type": "string"},
+    {"name": "subject_url", "type": ["null", "string"], "default": null},
+    {"name": "relationship", "type": "string"},
+    {"name": "value", "type": "string"},
+    {"name": "value_url", "type": ["null", "string"], "default": null},
+    {"name": "citation", "type": ["null", "string"], "default": null},
+    {"name": "raw_relationship", "type": ["null", "string"], "default": null},
+    {"name": "language", "type": ["null", "string"], "default": null},
+    {"name": "source", "type": ["null", "string"], "default": null},
+    {"name": "section", "type": ["null", "string"], "default": null},
+    {"name": "project", "type": ["null", "string"], "default": null},
+    {"name": "value_type", "type": ["null", "string"], "default": null},
+    {"name": "raw_value", "type": ["null", "string"], "default": null},
+    {"name": "number", "type": ["null", "string"], "default": null},
+    {"name": "metric", "type": ["null", "string"], "default": null},
+    {"name": "imperial", "type": ["null", "string"], "default": null},
+    {"name": "country_alpha2", "type": ["null", "string"], "default": null},
+    {"name": "country_alpha3", "type": ["null", "string"], "default": null},
+    {"name": "sources", "type": {"type": "array", "items": "string"}, "default": []},
+    {"name": "truncated_fields", "type": {"type": "array", "items": "string"}, "default": []},
+    {"name": "raw_html", "type": ["null", "string"], "default": null}
+  ]
+}`
+
+// Avro object container constants used by writeAvro
+const (
+	avroMagic = "Obj\x01"
+
+	// avroBlockMaxRecords is how many records are written per data block
+	avroBlockMaxRecords = 1000
+)
+
+// avroField is a field of AvroSchema and the quad field it holds. String
+// fields set value, array fields set values.
+type avroField struct {
+	optional bool
+	value    func(extractor.Quad) string
+	values   func(extractor.Quad) []string
+}
+
+// avroFields lists the fields of AvroSchema in schema order
+var avroFields = []avroField{
+	{value: func(q extractor.Quad) string { return q.Subject }},
+	{optional: true, value: func(q extractor.Quad) string { return q.SubjectURL }},
+	{value: func(q extractor.Quad) string { return q.Relationship }},
+	{value: func(q extractor.Quad) string { return q.Value }},
+	{optional: true, value: func(q extractor.Quad) string { return q.ValueURL }},
+	{optional: true, value: func(q extractor.Quad) string { return q.Citation }},
+	{optional: true, value: func(q extractor.Quad) string { return q.RawRelationship }},
+	{optional: true, value: func(q extractor.Quad) string { return q.Language }},
+	{optional: true, value: func(q extractor.Quad) string { return q.Source }},
+	{optional: true, value: func(q extractor.Quad) string { return q.Section }},
+	{optional: true, value: func(q extractor.Quad) string { return q.Project }},
+	{optional: true, value: func(q extractor.Quad) string { return q.ValueType }},
+	{optional: true, value: func(q extractor.Quad) string { return q.RawValue }},
+	{optional: true, value: func(q extractor.Quad) string { return q.Number }},
+	{optional: true, value: func(q extractor.Quad) string { return q.Metric }},
+	{optional: true, value: func(q extractor.Quad) string { return q.Imperial }},
+	{optional: true, value: func(q extractor.Quad) string { return q.CountryAlpha2 }},
+	{optional: true, value: func(q extractor.Quad) string { return q.CountryAlpha3 }},
+	{values: func(q extractor.Quad) []string { return q.Sources }},
+	{values: func(q extractor.Quad) []string { return q.TruncatedFields }},
+	{optional: true, value: func(q extractor.Quad) string { return q.RawHTML }},
+}
+
+// avroSyncMarker separates the blocks of a container file. It is derived
+// from the schema rather than drawn at random so the same quads always give
+// the same file.
+var avroSyncMarker = func() [16]byte {
+	sum := sha256.Sum256([]byte(AvroSchema))
+	var marker [16]byte
+	copy(marker[:], sum[:])
+	return marker
+}()
+
+// writeAvro writes quads as an uncompressed Avro object container file with
+// AvroSchema embedded in its header; --columns does not apply. The file is
+// written front to back in blocks of records, so w need not be seekable and
+// the output can be piped, and readers can consume every block written so far.
+func (f *Formatter) writeAvro(quads []extractor.Quad, w io.Writer) error {
+	var header bytes.Buffer
+	header.WriteString(avroMagic)
+	avroLong(&header, 2)
+	avroString(&header, "avro.schema")
+	avroString(&header, AvroSchema)
+	avroString(&header, "avro.codec")
+	avroString(&header, "null")
+	avroLong(&header, 0)
+	header.Write(avroSyncMarker[:])
+	if _, err := w.Write(header.Bytes()); err != nil {
+		return fmt.Errorf("failed to write Avro header: %w", err)
+	}
+
+	var records, block bytes.Buffer
+	for start := 0; start < len(quads); start += avroBlockMaxRecords {
+		rows := quads[start:min(start+avroBlockMaxRecords, len(quads))]
+
+		records.Reset()
+		for _, quad := range rows {
+			writeAvroRecord(&records, quad)
+		}
+		block.Reset()
+		avroLong(&block, int64(len(rows)))
+		avroLong(&block, int64(records.Len()))
+		block.Write(records.Bytes())
+		block.Write(avroSyncMarker[:])
+		if _, err := w.Write(block.Bytes()); err != nil {
+			return fmt.Errorf("failed to write Avro block: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// writeAvroRecord encodes quad as a record of AvroSchema
+func writeAvroRecord(buf *bytes.Buffer, quad extractor.Quad) {
+	for _, field := range avroFields {
+		switch {
+		case field.values != nil:
+			// Arrays are written as one block of items followed by an empty block
+			values := field.values(quad)
+			if len(values) > 0 {
+				avroLong(buf, int64(len(values)))
+				for _, value := range values {
+					avroString(buf, value)
+				}
+			}
+			avroLong(buf, 0)
+		case field.optional:
+			// Unions are written as the branch index, 0 for null and 1 for string
+			value := field.value(quad)
+			if value == "" {
+				avroLong(buf, 0)
+				continue
+			}
+			avroLong(buf, 1)
+			avroString(buf, value)
+		default:
+			avroString(buf, field.value(quad))
+		}
+	}
+}
+
+// avroLong writes v as an Avro long: a zigzag-encoded varint
+func avroLong(buf *bytes.Buffer, v int64) {
+	var varint [binary.MaxVarintLen64]byte
+	buf.Write(varint[:binary.PutVarint(varint[:], v)])
+}
+
+// avroString writes s as an Avro string or bytes: its length followed by its bytes
+func avroString(buf *bytes.Buffer, s string) {
+	avroLong(buf, int64(len(s)))
+	buf.WriteString(s)
+}
@@ -0,0 +1,174 @@
+package output
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+	"github.com/linkedin/goavro/v2"
+)
+
+// readAvro decodes a container file written by writeAvro with goavro,
+// checking that it embeds AvroSchema
+func readAvro(t *testing.T, data []byte) []map[string]interface{} {
+	t.Helper()
+	ocf, err := goavro.NewOCFReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("opening Avro file: %v", err)
+	}
+	if ocf.Codec().Schema() != goavroSchema(t) {
+		t.Errorf("embedded schema differs from AvroSchema")
+	}
+
+	var records []map[string]interface{}
+	for ocf.Scan() {
+		datum, err := ocf.Read()
+		if err != nil {
+			t.Fatalf("reading record %d: %v", len(records), err)
+		}
+		records = append(records, datum.(map[string]interface{}))
+	}
+	if err := ocf.Err(); err != nil {
+		t.Fatalf("reading Avro file: %v", err)
+	}
+	return records
+}
+
+// goavroSchema returns AvroSchema in goavro's canonical form
+func goavroSchema(t *testing.T) string {
+	t.Helper()
+	codec, err := goavro.NewCodec(AvroSchema)
+	if err != nil {
+		t.Fatalf("parsing AvroSchema: %v", err)
+	}
+	return codec.Schema()
+}
+
+// avroQuad turns a record decoded by goavro back into a quad
+func avroQuad(t *testing.T, record map[string]interface{}) extractor.Quad {
+	t.Helper()
+	str := func(name string) string {
+		switch v := record[name].(type) {
+		case string:
+			return v
+		case nil:
+			return ""
+		case map[string]interface{}:
+			s, ok := v["string"].(string)
+			if !ok || s == "" {
+				t.Errorf("%s = %v, want a non-empty string or null", name, v)
+			}
+			return s
+		default:
+			t.Fatalf("%s has type %T", name, v)
+			return ""
+		}
+	}
+	strs := func(name string) []string {
+		var values []string
+		for _, v := range record[name].([]interface{}) {
+			values = append(values, v.(string))
+		}
+		return values
+	}
+	return extractor.Quad{
+		Subject:         str("subject"),
+		SubjectURL:      str("subject_url"),
+		Relationship:    str("relationship"),
+		Value:           str("value"),
+		ValueURL:        str("value_url"),
+		Citation:        str("citation"),
+		RawRelationship: str("raw_relationship"),
+		Language:        str("language"),
+		Source:          str("source"),
+		Section:         str("section"),
+		Project:         str("project"),
+		ValueType:       str("value_type"),
+		RawValue:        str("raw_value"),
+		Number:          str("number"),
+		Metric:          str("metric"),
+		Imperial:        str("imperial"),
+		CountryAlpha2:   str("country_alpha2"),
+		CountryAlpha3:   str("country_alpha3"),
+		Sources:         strs("sources"),
+		TruncatedFields: strs("truncated_fields"),
+		RawHTML:         str("raw_html"),
+	}
+}
+
+func TestWriteAvroRoundTrip(t *testing.T) {
+	quads := []extractor.Quad{
+		{
+			Subject:         "Mount Example",
+			SubjectURL:      "https://en.wikipedia.org/wiki/Mount_Example",
+			Relationship:    "elevation",
+			Value:           "8,848.86 m (29,031.7 ft)",
+			ValueURL:        "https://en.wikipedia.org/wiki/Metre",
+			Citation:        "https://survey.example.org/heights",
+			RawRelationship: "Elevation",
+			Language:        "en",
+			Source:          "https://en.wikipedia.org/wiki/Mount_Example",
+			Section:         "Geography",
+			Project:         "wikipedia",
+			ValueType:       extractor.ValueTypeMeasurement,
+			RawValue:        "8,848.86 m (29,031.7 ft)",
+			Number:          "8848.86",
+			Metric:          "8,848.86 m",
+			Imperial:        "29,031.7 ft",
+			CountryAlpha2:   "NP CN",
+			CountryAlpha3:   "NPL CHN",
+			Sources:         []string{"https://en.wikipedia.org/wiki/Mount_Example", "https://de.wikipedia.org/wiki/Mount_Example"},
+			TruncatedFields: []string{"value"},
+			RawHTML:         `8,848.86&#160;m <sup class="reference">[1]</sup>`,
+		},
+		{Subject: "Zürich", Relationship: "Motto", Value: "«Ein Kanton» – 東京"},
+		{Subject: "Empty", Relationship: "Note", Value: ""},
+	}
+
+	var buf bytes.Buffer
+	if err := NewFormatter().WriteQuads(quads, &buf, "avro"); err != nil {
+		t.Fatal(err)
+	}
+	records := readAvro(t, buf.Bytes())
+	if len(records) != len(quads) {
+		t.Fatalf("read %d records, want %d", len(records), len(quads))
+	}
+	for i, record := range records {
+		if got := avroQuad(t, record); !reflect.DeepEqual(got, quads[i]) {
+			t.Errorf("record %d\n  got  %+v\n  want %+v", i, got, quads[i])
+		}
+	}
+}
+
+func TestWriteAvroEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewFormatter().WriteQuads(nil, &buf, "avro"); err != nil {
+		t.Fatal(err)
+	}
+	if records := readAvro(t, buf.Bytes()); len(records) != 0 {
+		t.Errorf("read %d records, want none", len(records))
+	}
+}
+
+func TestWriteAvroBlocks(t *testing.T) {
+	quads := make([]extractor.Quad, avroBlockMaxRecords+1)
+	for i := range quads {
+		quads[i] = extractor.Quad{Subject: "Subject " + strconv.Itoa(i), Relationship: "Index", Value: strconv.Itoa(i)}
+	}
+
+	var buf bytes.Buffer
+	if err := NewFormatter().WriteQuads(quads, &buf, "avro"); err != nil {
+		t.Fatal(err)
+	}
+	records := readAvro(t, buf.Bytes())
+	if len(records) != len(quads) {
+		t.Fatalf("read %d records, want %d", len(records), len(quads))
+	}
+	for i, record := range records {
+		if got := avroQuad(t, record); got.Value != strconv.Itoa(i) {
+			t.Fatalf("record %d has value %q", i, got.Value)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+// DefaultColumns are the quad fields written when no column spec is given
+var DefaultColumns = []string{"subject", "relationship", "value", "citation"}
+
+// columnHeaders are the header titles used for each column in tabular formats
+var columnHeaders = map[string]string{
+	"subject":        "Subject",
+	"subject_url":    "Subject URL",
+	"relationship":   "Relationship",
+	"value":          "Value",
+	"value_url":      "Value URL",
+	"citation":       "Citation",
+	"source":         "Source",
+	"language":       "Language",
+	"section":        "Section",
+	"project":        "Project",
+	"raw_html":       "Raw HTML",
+	"value_type":     "Value Type",
+	"raw_value":      "Raw Value",
+	"number":         "Number",
+	"metric":         "Metric",
+	"imperial":       "Imperial",
+	"country_alpha2": "Country Alpha-2",
+	"country_alpha3": "Country Alpha-3",
+	"sources":        "Sources",
+
+	// Record columns hold storage metadata, only filled in by WriteRecords
+	"id":           "ID",
+	"source_url":   "Source URL",
+	"extracted_at": "Extracted At",
+	"tag":          "Tag",
+}
+
+// reportHeaders are the header titles of the columns of the describe,
+// corroborated and stats reports that are not quad fields
+var reportHeaders = map[string]string{
+	"citations":       "Citations",
+	"total_quads":     "total_quads",
+	"total_subjects":  "total_subjects",
+	"total_sources":   "total_sources",
+	"last_extraction": "last_extraction",
+}
+
+// recordColumns are the columns holding storage metadata rather than quad fields
+var recordColumns = map[string]bool{
+	"id":           true,
+	"source_url":   true,
+	"extracted_at": true,
+	"tag":          true,
+}
+
+// HasRecordColumns reports whether columns include storage metadata, which
+// only stored quad records carry
+func HasRecordColumns(columns []string) bool {
+	for _, column := range columns {
+		if recordColumns[column] {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseColumns parses a comma-separated column spec such as
+// "subject,value,source", validating each column name
+func ParseColumns(spec string) ([]string, error) {
+	var columns []string
+	for _, column := range strings.Split(spec, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if column == "" {
+			continue
+		}
+		if _, ok := columnHeaders[column]; !ok {
+			return nil, fmt.Errorf("unknown column %q (valid columns: subject, subject_url, relationship, value, value_url, citation, source, language, section, project, raw_html, value_type, raw_value, number, metric, imperial, country_alpha2, country_alpha3, sources, id, source_url, extracted_at, tag)", column)
+		}
+		columns = append(columns, column)
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns specified")
+	}
+
+	return columns, nil
+}
+
+// ParseHeaders parses header title overrides such as
+// "subject=Entity,value=Fact", validating each column name
+func ParseHeaders(spec string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, entry := range strings.Split(spec, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		column, title, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid header override %q, expected column=title", entry)
+		}
+		column = strings.ToLower(strings.TrimSpace(column))
+		_, quadColumn := columnHeaders[column]
+		if _, reportColumn := reportHeaders[column]; !quadColumn && !reportColumn {
+			return nil, fmt.Errorf("unknown column %q in header override", column)
+		}
+		headers[column] = strings.TrimSpace(title)
+	}
+
+	if len(headers) == 0 {
+		return nil, fmt.Errorf("no header overrides specified")
+	}
+
+	return headers, nil
+}
+
+// columns returns the formatter's column spec, falling back to DefaultColumns
+func (f *Formatter) columns() []string {
+	if len(f.Columns) == 0 {
+		return DefaultColumns
+	}
+	return f.Columns
+}
+
+// columnValue returns the value of the named column for a quad
+func columnValue(quad extractor.Quad, column string) string {
+	switch column {
+	case "subject":
+		return quad.Subject
+	case "subject_url":
+		return quad.SubjectURL
+	case "relationship":
+		return quad.Relationship
+	case "value":
+		return quad.Value
+	case "value_url":
+		return quad.ValueURL
+	case "citation":
+		return quad.Citation
+	case "source":
+		return quad.Source
+	case "language":
+		return quad.Language
+	case "section":
+		return quad.Section
+	case "project":
+		return quad.Project
+	case "raw_html":
+		return quad.RawHTML
+	case "value_type":
+		return quad.ValueType
+	case "raw_value":
+		return quad.RawValue
+	case "number":
+		return quad.Number
+	case "metric":
+		return quad.Metric
+	case "imperial":
+		return quad.Imperial
+	case "country_alpha2":
+		return quad.CountryAlpha2
+	case "country_alpha3":
+		return quad.CountryAlpha3
+	case "sources":
+		return strings.Join(quad.Sources, " ")
+	default:
+		return ""
+	}
+}
+
+// headerRow returns the header titles for the formatter's columns
+func (f *Formatter) headerRow() []string {
+	columns := f.columns()
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		if header, ok := f.Headers[column]; ok {
+			headers[i] = header
+		} else {
+			headers[i] = columnHeaders[column]
+		}
+	}
+	return headers
+}
+
+// reportHeaderRow returns the header titles for the columns of a report,
+// such as describe's subject, relationship, value and citations
+func (f *Formatter) reportHeaderRow(columns ...string) []string {
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		switch header, ok := f.Headers[column]; {
+		case ok:
+			headers[i] = header
+		case reportHeaders[column] != "":
+			headers[i] = reportHeaders[column]
+		default:
+			headers[i] = columnHeaders[column]
+		}
+	}
+	return headers
+}
+
+// record returns the values of the formatter's columns for a quad
+func (f *Formatter) record(quad extractor.Quad) []string {
+	columns := f.columns()
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = columnValue(quad, column)
+	}
+	return values
+}
+
+// orderedRecord is a JSON object whose keys are written in column order
+type orderedRecord struct {
+	columns []string
+	values  []string
+}
+
+// MarshalJSON encodes the record as an object with keys in column order
+func (r orderedRecord) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, column := range r.columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(column)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(r.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,40 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// WriteCorroboratedFacts writes facts asserted by several sources to w in the
+// given format (json, yaml, csv or tsv)
+func (f *Formatter) WriteCorroboratedFacts(facts []storage.CorroboratedFact, w io.Writer, format string) error {
+	switch format {
+	case "json":
+		return f.newJSONEncoder(w).Encode(facts)
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		defer encoder.Close()
+		return encoder.Encode(facts)
+	case "csv", "tsv":
+		delimiter := ','
+		if format == "tsv" {
+			delimiter = '\t'
+		}
+		writer, err := f.newDelimitedWriter(w, delimiter)
+		if err != nil {
+			return err
+		}
+		writer.Write(f.reportHeaderRow("subject", "relationship", "value", "sources"))
+		for _, fact := range facts {
+			writer.Write([]string{fact.Subject, fact.Relationship, fact.Value, strconv.Itoa(fact.Sources)})
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("unsupported corroborated format: %s (valid formats: json, yaml, csv, tsv)", format)
+	}
+}
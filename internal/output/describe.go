@@ -0,0 +1,123 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+	"gopkg.in/yaml.v3"
+)
+
+// SubjectSummary is everything known about one subject, grouped by relationship
+type SubjectSummary struct {
+	Subject       string                `json:"subject" yaml:"subject"`
+	Relationships []RelationshipSummary `json:"relationships" yaml:"relationships"`
+}
+
+// RelationshipSummary lists the distinct values of one relationship of a subject
+type RelationshipSummary struct {
+	Relationship string         `json:"relationship" yaml:"relationship"`
+	Values       []ValueSummary `json:"values" yaml:"values"`
+}
+
+// ValueSummary is one value of a relationship and how many distinct
+// citations support it
+type ValueSummary struct {
+	Value     string `json:"value" yaml:"value"`
+	Citations int    `json:"citations" yaml:"citations"`
+}
+
+// SummarizeSubject groups quads about a subject by relationship, in order of
+// first appearance. Repeated values, such as those stored by several
+// extractions, are listed once with the citations of all of them.
+func SummarizeSubject(subject string, quads []extractor.Quad) SubjectSummary {
+	summary := SubjectSummary{Subject: subject, Relationships: []RelationshipSummary{}}
+	relationshipIndex := make(map[string]int)
+	valueIndex := make(map[[2]string]int)
+	citations := make(map[[2]string]map[string]bool)
+
+	for _, quad := range quads {
+		r, ok := relationshipIndex[quad.Relationship]
+		if !ok {
+			r = len(summary.Relationships)
+			relationshipIndex[quad.Relationship] = r
+			summary.Relationships = append(summary.Relationships, RelationshipSummary{Relationship: quad.Relationship})
+		}
+
+		key := [2]string{quad.Relationship, quad.Value}
+		v, ok := valueIndex[key]
+		if !ok {
+			v = len(summary.Relationships[r].Values)
+			valueIndex[key] = v
+			summary.Relationships[r].Values = append(summary.Relationships[r].Values, ValueSummary{Value: quad.Value})
+			citations[key] = make(map[string]bool)
+		}
+
+		if quad.Citation != "" && !citations[key][quad.Citation] {
+			citations[key][quad.Citation] = true
+			summary.Relationships[r].Values[v].Citations++
+		}
+	}
+
+	return summary
+}
+
+// WriteSubjectSummary writes a subject summary to w. The json and yaml
+// formats nest values under their relationship; csv and tsv write one row
+// per value.
+func (f *Formatter) WriteSubjectSummary(summary SubjectSummary, w io.Writer, format string) error {
+	switch format {
+	case "json":
+		return f.newJSONEncoder(w).Encode(summary)
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		defer encoder.Close()
+		return encoder.Encode(summary)
+	case "csv", "tsv":
+		delimiter := ','
+		if format == "tsv" {
+			delimiter = '\t'
+		}
+		writer, err := f.newDelimitedWriter(w, delimiter)
+		if err != nil {
+			return err
+		}
+		writer.Write(f.reportHeaderRow("subject", "relationship", "value", "citations"))
+		for _, relationship := range summary.Relationships {
+			for _, value := range relationship.Values {
+				writer.Write([]string{summary.Subject, relationship.Relationship, value.Value, strconv.Itoa(value.Citations)})
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("unsupported describe format: %s (valid formats: json, yaml, csv, tsv)", format)
+	}
+}
+
+// WriteSubjectSummaryText writes a subject summary as an indented listing for
+// reading in a terminal
+func WriteSubjectSummaryText(summary SubjectSummary, w io.Writer) error {
+	if _, err := fmt.Fprintln(w, summary.Subject); err != nil {
+		return err
+	}
+	for _, relationship := range summary.Relationships {
+		values := make([]string, len(relationship.Values))
+		for i, value := range relationship.Values {
+			switch value.Citations {
+			case 0:
+				values[i] = value.Value
+			case 1:
+				values[i] = value.Value + " (1 citation)"
+			default:
+				values[i] = fmt.Sprintf("%s (%d citations)", value.Value, value.Citations)
+			}
+		}
+		if _, err := fmt.Fprintf(w, "  %s: %s\n", relationship.Relationship, strings.Join(values, "; ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+// WriteDiff writes the differences between two extractions to w in the given
+// format (json, csv or tsv). Tabular formats write one row per quad with a
+// leading change type column; changed quads carry their previous value in a
+// trailing column.
+func (f *Formatter) WriteDiff(diff extractor.QuadDiff, w io.Writer, format string) error {
+	switch format {
+	case "json":
+		// Write empty lists rather than null so consumers can always iterate
+		if diff.Added == nil {
+			diff.Added = []extractor.Quad{}
+		}
+		if diff.Removed == nil {
+			diff.Removed = []extractor.Quad{}
+		}
+		if diff.Changed == nil {
+			diff.Changed = []extractor.QuadChange{}
+		}
+		encoder := f.newJSONEncoder(w)
+		return encoder.Encode(diff)
+	case "csv":
+		return f.writeDelimitedDiff(diff, w, ',')
+	case "tsv":
+		return f.writeDelimitedDiff(diff, w, '\t')
+	default:
+		return fmt.Errorf("unsupported diff format: %s", format)
+	}
+}
+
+// writeDelimitedDiff writes a diff as CSV (or TSV) with a header row
+func (f *Formatter) writeDelimitedDiff(diff extractor.QuadDiff, w io.Writer, delimiter rune) error {
+	writer, err := f.newDelimitedWriter(w, delimiter)
+	if err != nil {
+		return err
+	}
+
+	header := append([]string{"Change"}, f.headerRow()...)
+	header = append(header, "Previous Value")
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	writeRow := func(change string, quad extractor.Quad, previousValue string) error {
+		row := append([]string{change}, f.record(quad)...)
+		row = append(row, previousValue)
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV record: %w", err)
+		}
+		return nil
+	}
+
+	for _, quad := range diff.Added {
+		if err := writeRow("added", quad, ""); err != nil {
+			return err
+		}
+	}
+	for _, quad := range diff.Removed {
+		if err := writeRow("removed", quad, ""); err != nil {
+			return err
+		}
+	}
+	for _, change := range diff.Changed {
+		if err := writeRow("changed", change.New, change.Old.Value); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,74 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxFileNameLength bounds the length in bytes of a sanitized file name, excluding the extension
+const maxFileNameLength = 100
+
+// FileExtension returns the file extension for an output format
+func FileExtension(format string) string {
+	if writer, ok := lookupFormat(format); ok {
+		if typed, ok := writer.(interface{ FileExtension() string }); ok && typed.FileExtension() != "" {
+			return typed.FileExtension()
+		}
+	}
+	return "." + format
+}
+
+// SanitizeFileName turns a page title into a safe file name by replacing path
+// separators, reserved characters and whitespace, and limiting its length
+func SanitizeFileName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(name) {
+		switch {
+		case strings.ContainsRune(`/\:*?"<>|`, r), unicode.IsControl(r):
+			b.WriteRune('_')
+		case unicode.IsSpace(r):
+			b.WriteRune('_')
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	sanitized := b.String()
+	if len(sanitized) > maxFileNameLength {
+		// Cut on a rune boundary so multi-byte characters stay intact
+		cut := maxFileNameLength
+		for cut > 0 && !utf8.RuneStart(sanitized[cut]) {
+			cut--
+		}
+		sanitized = sanitized[:cut]
+	}
+
+	sanitized = strings.Trim(sanitized, "._")
+	if sanitized == "" {
+		return "untitled"
+	}
+	return sanitized
+}
+
+// UniqueFilePath returns a path in dir for the named file with the format's
+// extension. If the file already exists, a counter is appended to the name.
+func UniqueFilePath(dir, name, format string) string {
+	base := SanitizeFileName(name)
+	ext := FileExtension(format)
+
+	path := filepath.Join(dir, base+ext)
+	for i := 2; fileExists(path); i++ {
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d%s", base, i, ext))
+	}
+	return path
+}
+
+// fileExists reports whether a file exists at path
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
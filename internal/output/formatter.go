@@ -0,0 +1,231 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+// Formatter writes quads in the supported output formats
+type Formatter struct {
+	// Columns selects and orders the quad fields written by the csv, tsv, sql
+	// and json formats. When empty, csv, tsv and sql write DefaultColumns and
+	// json writes every populated field.
+	Columns []string
+
+	// Indent is the per-level indentation of JSON output. When empty, JSON is
+	// written compactly on a single line.
+	Indent string
+
+	// WithSchema precedes sql output with the CREATE TABLE statement for its columns
+	WithSchema bool
+
+	// Template, when set, formats each quad in place of the requested format.
+	// Create it with ParseTemplate.
+	Template *template.Template
+
+	// Headers overrides the header titles of csv and tsv columns, keyed by
+	// column name, in quad output and in the describe, corroborated and
+	// stats reports. Create it with ParseHeaders.
+	Headers map[string]string
+
+	// BOM starts csv and tsv output, including the describe, corroborated,
+	// stats and diff reports, with a UTF-8 byte order mark, which
+	// spreadsheet applications such as Excel need to detect the encoding
+	BOM bool
+
+	// MaxCellLength truncates markdown table cells longer than this many
+	// characters, ending them with "…". Zero means no limit.
+	MaxCellLength int
+
+	// BaseIRI is the namespace of the subject IRIs written by the turtle and
+	// nquads formats, in place of DefaultBaseIRI. When set, subjects and
+	// linked entities are named by their article title within it rather than
+	// by their article URL. Check it with ParseNamespaceIRI.
+	BaseIRI string
+
+	// PredicateNamespace is the namespace of the predicate IRIs written by
+	// the turtle and nquads formats, in place of DefaultPredicateNamespace.
+	// Check it with ParseNamespaceIRI.
+	PredicateNamespace string
+}
+
+// utf8BOM is the UTF-8 encoding of U+FEFF, written first when Formatter.BOM is set
+const utf8BOM = "\xef\xbb\xbf"
+
+// DefaultIndent is the JSON indentation used by NewFormatter
+const DefaultIndent = "  "
+
+// NewFormatter creates a new output formatter
+func NewFormatter() *Formatter {
+	return &Formatter{Indent: DefaultIndent}
+}
+
+// newJSONEncoder returns a JSON encoder for w using the formatter's indentation
+func (f *Formatter) newJSONEncoder(w io.Writer) *json.Encoder {
+	encoder := json.NewEncoder(w)
+	if f.Indent != "" {
+		encoder.SetIndent("", f.Indent)
+	}
+	return encoder
+}
+
+// defaultContentType is served for registered formats that don't declare a MIME type
+const defaultContentType = "application/octet-stream"
+
+// ContentType returns the MIME type for an output format, or an empty string
+// if the format is not registered
+func ContentType(format string) string {
+	writer, ok := lookupFormat(format)
+	if !ok {
+		return ""
+	}
+	if typed, ok := writer.(interface{ ContentType() string }); ok && typed.ContentType() != "" {
+		return typed.ContentType()
+	}
+	return defaultContentType
+}
+
+// FormatForMediaType returns the output format that produces the given MIME
+// type. Formats are checked in name order so the result is deterministic.
+func FormatForMediaType(mediaType string) (string, bool) {
+	for _, format := range Formats() {
+		writer, _ := lookupFormat(format)
+		if typed, ok := writer.(interface{ ContentType() string }); ok && typed.ContentType() == mediaType {
+			return format, true
+		}
+	}
+
+	return "", false
+}
+
+// xmlQuads is the XML document representation of a list of quads
+type xmlQuads struct {
+	XMLName xml.Name  `xml:"quads"`
+	Quads   []xmlQuad `xml:"quad"`
+}
+
+// xmlQuad is the XML element representation of a single quad
+type xmlQuad struct {
+	Subject      string `xml:"subject"`
+	Relationship string `xml:"relationship"`
+	Value        string `xml:"value"`
+	Citation     string `xml:"citation"`
+}
+
+// WriteQuads writes quads to w in the given format, which must be registered
+// with RegisterFormat. A formatter with a Template writes through it instead.
+func (f *Formatter) WriteQuads(quads []extractor.Quad, w io.Writer, format string) error {
+	if f.Template != nil {
+		return f.writeTemplate(quads, w)
+	}
+
+	writer, ok := lookupFormat(format)
+	if !ok {
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+	return writer.WriteQuads(f, quads, w)
+}
+
+// writeJSON writes quads as a JSON array
+func (f *Formatter) writeJSON(quads []extractor.Quad, w io.Writer) error {
+	encoder := f.newJSONEncoder(w)
+
+	if len(f.Columns) == 0 {
+		if quads == nil {
+			quads = []extractor.Quad{}
+		}
+		return encoder.Encode(quads)
+	}
+
+	records := make([]orderedRecord, len(quads))
+	for i, quad := range quads {
+		records[i] = orderedRecord{columns: f.Columns, values: f.record(quad)}
+	}
+	return encoder.Encode(records)
+}
+
+// writeJSONLines writes quads as newline-delimited JSON, one compact object
+// per line, so consumers can process quads as they arrive. Unlike json, the
+// output is not a single document and Indent does not apply.
+func (f *Formatter) writeJSONLines(quads []extractor.Quad, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for _, quad := range quads {
+		var err error
+		if len(f.Columns) == 0 {
+			err = encoder.Encode(quad)
+		} else {
+			err = encoder.Encode(orderedRecord{columns: f.Columns, values: f.record(quad)})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newDelimitedWriter returns a CSV (or TSV) writer to w, first writing the
+// byte order mark when BOM is set
+func (f *Formatter) newDelimitedWriter(w io.Writer, delimiter rune) (*csv.Writer, error) {
+	if f.BOM {
+		if _, err := io.WriteString(w, utf8BOM); err != nil {
+			return nil, err
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+	return writer, nil
+}
+
+// writeDelimited writes quads as CSV (or TSV) with a header row
+func (f *Formatter) writeDelimited(quads []extractor.Quad, w io.Writer, delimiter rune) error {
+	writer, err := f.newDelimitedWriter(w, delimiter)
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Write(f.headerRow()); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, quad := range quads {
+		if err := writer.Write(f.record(quad)); err != nil {
+			return fmt.Errorf("failed to write CSV record: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeXML writes quads as an indented XML document
+func (f *Formatter) writeXML(quads []extractor.Quad, w io.Writer) error {
+	doc := xmlQuads{}
+	for _, quad := range quads {
+		doc.Quads = append(doc.Quads, xmlQuad{
+			Subject:      quad.Subject,
+			Relationship: quad.Relationship,
+			Value:        quad.Value,
+			Citation:     quad.Citation,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode XML: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
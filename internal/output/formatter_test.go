@@ -0,0 +1,107 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+)
+
+// readDelimited splits csv or tsv output into its byte order mark, if any,
+// and its rows
+func readDelimited(t *testing.T, data []byte, delimiter rune) (bool, [][]string) {
+	t.Helper()
+	bom := bytes.HasPrefix(data, []byte(utf8BOM))
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte(utf8BOM))))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("reading output: %v", err)
+	}
+	return bom, rows
+}
+
+func TestDelimitedBOMAndHeaders(t *testing.T) {
+	quads := []extractor.Quad{{Subject: "Zürich", Relationship: "Country", Value: "Switzerland"}}
+	headers, err := ParseHeaders("subject=Entity,value=Fact,citations=Cited by,sources=Sites,total_quads=Quads")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		delimiter rune
+		write     func(f *Formatter, w io.Writer) error
+		header    []string
+	}{
+		{"quads csv", ',', func(f *Formatter, w io.Writer) error {
+			return f.WriteQuads(quads, w, "csv")
+		}, []string{"Entity", "Relationship", "Fact", "Citation"}},
+		{"quads tsv", '\t', func(f *Formatter, w io.Writer) error {
+			return f.WriteQuads(quads, w, "tsv")
+		}, []string{"Entity", "Relationship", "Fact", "Citation"}},
+		{"describe", ',', func(f *Formatter, w io.Writer) error {
+			return f.WriteSubjectSummary(SummarizeSubject("Zürich", quads), w, "csv")
+		}, []string{"Entity", "Relationship", "Fact", "Cited by"}},
+		{"describe tsv", '\t', func(f *Formatter, w io.Writer) error {
+			return f.WriteSubjectSummary(SummarizeSubject("Zürich", quads), w, "tsv")
+		}, []string{"Entity", "Relationship", "Fact", "Cited by"}},
+		{"corroborated", ',', func(f *Formatter, w io.Writer) error {
+			facts := []storage.CorroboratedFact{{Subject: "Zürich", Relationship: "Country", Value: "Switzerland", Sources: 2}}
+			return f.WriteCorroboratedFacts(facts, w, "csv")
+		}, []string{"Entity", "Relationship", "Fact", "Sites"}},
+		{"stats", ',', func(f *Formatter, w io.Writer) error {
+			return f.WriteStats(&storage.Stats{TotalQuads: 1, TotalSubjects: 1, TotalSources: 1}, w, "csv")
+		}, []string{"Quads", "total_subjects", "total_sources", "last_extraction"}},
+		{"diff", ',', func(f *Formatter, w io.Writer) error {
+			return f.WriteDiff(extractor.QuadDiff{Added: quads}, w, "csv")
+		}, []string{"Change", "Entity", "Relationship", "Fact", "Citation", "Previous Value"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, withBOM := range []bool{false, true} {
+				f := NewFormatter()
+				f.BOM = withBOM
+				f.Headers = headers
+				var buf bytes.Buffer
+				if err := tt.write(f, &buf); err != nil {
+					t.Fatal(err)
+				}
+
+				bom, rows := readDelimited(t, buf.Bytes(), tt.delimiter)
+				if bom != withBOM {
+					t.Errorf("BOM = %v, want %v", bom, withBOM)
+				}
+				if strings.Count(buf.String(), utf8BOM) > 1 {
+					t.Error("BOM written more than once")
+				}
+				if len(rows) < 2 || !reflect.DeepEqual(rows[0], tt.header) {
+					t.Errorf("header = %q, want %q", rows[0], tt.header)
+				}
+			}
+		})
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := ParseHeaders(" Subject = Entity ,total_sources=Pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"subject": "Entity", "total_sources": "Pages"}
+	if !reflect.DeepEqual(headers, want) {
+		t.Errorf("ParseHeaders = %v, want %v", headers, want)
+	}
+
+	for _, spec := range []string{"", "subject", "unknown=Title"} {
+		if _, err := ParseHeaders(spec); err == nil {
+			t.Errorf("ParseHeaders(%q) succeeded, want an error", spec)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package output
+
+import (
+	"io"
+
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+)
+
+// graphEdge is an outgoing edge in an adjacency list
+type graphEdge struct {
+	Target       string `json:"target"`
+	Relationship string `json:"relationship"`
+}
+
+// WriteAdjacencyList writes subject links as a JSON object mapping each
+// subject to its outgoing edges, labelled with the linking relationship
+func (f *Formatter) WriteAdjacencyList(links []storage.SubjectLink, w io.Writer) error {
+	graph := make(map[string][]graphEdge)
+	for _, link := range links {
+		graph[link.Subject] = append(graph[link.Subject], graphEdge{
+			Target:       link.Target,
+			Relationship: link.Relationship,
+		})
+	}
+
+	encoder := f.newJSONEncoder(w)
+	return encoder.Encode(graph)
+}
@@ -0,0 +1,54 @@
+package output
+
+import (
+	"io"
+	"strings"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+// markdownEscaper escapes the characters that would break a GitHub-flavored
+// Markdown table cell. Line breaks become <br>, which GitHub renders in cells.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"|", `\|`,
+	"\r\n", "<br>",
+	"\n", "<br>",
+	"\r", "<br>",
+)
+
+// writeMarkdown writes quads as a GitHub-flavored Markdown table with a
+// header row, truncating cells longer than MaxCellLength
+func (f *Formatter) writeMarkdown(quads []extractor.Quad, w io.Writer) error {
+	var b strings.Builder
+	writeMarkdownRow(&b, f.headerRow(), 0)
+
+	b.WriteString("|")
+	for range f.columns() {
+		b.WriteString(" --- |")
+	}
+	b.WriteString("\n")
+
+	for _, quad := range quads {
+		writeMarkdownRow(&b, f.record(quad), f.MaxCellLength)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMarkdownRow writes one table row, truncating cells to maxLength
+// characters when it is positive
+func writeMarkdownRow(b *strings.Builder, cells []string, maxLength int) {
+	b.WriteString("|")
+	for _, cell := range cells {
+		cell = strings.TrimSpace(cell)
+		if runes := []rune(cell); maxLength > 0 && len(runes) > maxLength {
+			cell = strings.TrimSpace(string(runes[:maxLength])) + "…"
+		}
+		b.WriteString(" ")
+		b.WriteString(markdownEscaper.Replace(cell))
+		b.WriteString(" |")
+	}
+	b.WriteString("\n")
+}
@@ -0,0 +1,60 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	quads := []extractor.Quad{
+		{Subject: "Paris", Relationship: "Mayor", Value: "Anne Hidalgo", Citation: "https://example.org/mayor"},
+		{Subject: "A|B", Relationship: "Motto", Value: "Fluctuat\nnec mergitur", Citation: `C:\path`},
+		{Subject: " Rome ", Relationship: "Founded", Value: "753 BC\r\n(legendary)"},
+	}
+
+	var buf bytes.Buffer
+	if err := NewFormatter().WriteQuads(quads, &buf, "markdown"); err != nil {
+		t.Fatal(err)
+	}
+	want := "| Subject | Relationship | Value | Citation |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| Paris | Mayor | Anne Hidalgo | https://example.org/mayor |\n" +
+		`| A\|B | Motto | Fluctuat<br>nec mergitur | C:\\path |` + "\n" +
+		"| Rome | Founded | 753 BC<br>(legendary) |  |\n"
+	if got := buf.String(); got != want {
+		t.Errorf("markdown =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteMarkdownOptions(t *testing.T) {
+	quads := []extractor.Quad{
+		{Subject: "Zürich", Relationship: "Motto", Value: "Ümlaut über alles", Section: "Facts"},
+	}
+
+	f := NewFormatter()
+	f.Columns = []string{"subject", "value", "section"}
+	f.Headers = map[string]string{"value": "Fact"}
+	f.MaxCellLength = 6
+
+	var buf bytes.Buffer
+	if err := f.WriteQuads(quads, &buf, "markdown"); err != nil {
+		t.Fatal(err)
+	}
+	// Cells are cut by character, not byte, and the header row is never cut
+	want := "| Subject | Fact | Section |\n" +
+		"| --- | --- | --- |\n" +
+		"| Zürich | Ümlaut… | Facts |\n"
+	if got := buf.String(); got != want {
+		t.Errorf("markdown =\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := f.WriteQuads(nil, &buf, "markdown"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "| Subject | Fact | Section |\n| --- | --- | --- |\n"; got != want {
+		t.Errorf("empty markdown = %q, want %q", got, want)
+	}
+}
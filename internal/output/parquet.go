@@ -0,0 +1,252 @@
+package output
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+// Parquet format constants used by writeParquet
+const (
+	parquetMagic = "PAR1"
+
+	parquetTypeByteArray   = 6
+	parquetRequired        = 0
+	parquetOptional        = 1
+	parquetConvertedUTF8   = 0
+	parquetEncodingPlain   = 0
+	parquetEncodingRLE     = 3
+	parquetUncompressed    = 0
+	parquetDataPage        = 0
+	parquetFormatVersion   = 1
+	parquetCreatedBy       = "wikipedia-extraction"
+	parquetRowGroupMaxRows = 100000
+)
+
+// parquetColumn is a column of the Parquet schema and the quad field it holds
+type parquetColumn struct {
+	name     string
+	optional bool
+	value    func(extractor.Quad) string
+}
+
+// parquetColumns is the Parquet schema of a quad. Fields that are often empty
+// are optional, so empty values read back as NULL rather than "".
+var parquetColumns = []parquetColumn{
+	{"subject", false, func(q extractor.Quad) string { return q.Subject }},
+	{"subject_url", true, func(q extractor.Quad) string { return q.SubjectURL }},
+	{"relationship", false, func(q extractor.Quad) string { return q.Relationship }},
+	{"value", false, func(q extractor.Quad) string { return q.Value }},
+	{"value_url", true, func(q extractor.Quad) string { return q.ValueURL }},
+	{"citation", true, func(q extractor.Quad) string { return q.Citation }},
+	{"raw_relationship", true, func(q extractor.Quad) string { return q.RawRelationship }},
+	{"language", true, func(q extractor.Quad) string { return q.Language }},
+	{"source", true, func(q extractor.Quad) string { return q.Source }},
+	{"section", true, func(q extractor.Quad) string { return q.Section }},
+	{"project", true, func(q extractor.Quad) string { return q.Project }},
+	{"value_type", true, func(q extractor.Quad) string { return q.ValueType }},
+	{"raw_value", true, func(q extractor.Quad) string { return q.RawValue }},
+	{"number", true, func(q extractor.Quad) string { return q.Number }},
+	{"metric", true, func(q extractor.Quad) string { return q.Metric }},
+	{"imperial", true, func(q extractor.Quad) string { return q.Imperial }},
+	{"country_alpha2", true, func(q extractor.Quad) string { return q.CountryAlpha2 }},
+	{"country_alpha3", true, func(q extractor.Quad) string { return q.CountryAlpha3 }},
+	{"sources", true, func(q extractor.Quad) string { return strings.Join(q.Sources, " ") }},
+	{"raw_html", true, func(q extractor.Quad) string { return q.RawHTML }},
+}
+
+// parquetChunk records where a column chunk was written, for the file footer
+type parquetChunk struct {
+	offset    int64
+	size      int64
+	numValues int64
+}
+
+// countingWriter tracks the number of bytes written, since Parquet's footer
+// records the offset of every column chunk
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// writeParquet writes quads as an uncompressed Parquet file with a string
+// column per quad field; --columns does not apply. The file is written front
+// to back with its footer last, so w need not be seekable and the output can
+// be piped, but readers need the complete file.
+func (f *Formatter) writeParquet(quads []extractor.Quad, w io.Writer) error {
+	out := &countingWriter{w: w}
+	if _, err := io.WriteString(out, parquetMagic); err != nil {
+		return err
+	}
+
+	var rowGroups [][]parquetChunk
+	var rowCounts []int
+	for start := 0; start < len(quads); start += parquetRowGroupMaxRows {
+		rows := quads[start:min(start+parquetRowGroupMaxRows, len(quads))]
+
+		chunks := make([]parquetChunk, len(parquetColumns))
+		for i, column := range parquetColumns {
+			chunk, err := writeParquetChunk(out, column, rows)
+			if err != nil {
+				return fmt.Errorf("failed to write Parquet column %s: %w", column.name, err)
+			}
+			chunks[i] = chunk
+		}
+		rowGroups = append(rowGroups, chunks)
+		rowCounts = append(rowCounts, len(rows))
+	}
+
+	footer := parquetFooter(rowGroups, rowCounts, len(quads))
+	if _, err := out.Write(footer); err != nil {
+		return fmt.Errorf("failed to write Parquet footer: %w", err)
+	}
+
+	var trailer [8]byte
+	binary.LittleEndian.PutUint32(trailer[:4], uint32(len(footer)))
+	copy(trailer[4:], parquetMagic)
+	_, err := out.Write(trailer[:])
+	return err
+}
+
+// writeParquetChunk writes one column of a row group as a single data page
+func writeParquetChunk(out *countingWriter, column parquetColumn, rows []extractor.Quad) (parquetChunk, error) {
+	var page bytes.Buffer
+
+	// Optional columns start with the definition levels: 1 for a value, 0 for NULL
+	if column.optional {
+		levels := make([]bool, len(rows))
+		for i, row := range rows {
+			levels[i] = column.value(row) != ""
+		}
+		encoded := rleDefinitionLevels(levels)
+		binary.Write(&page, binary.LittleEndian, uint32(len(encoded)))
+		page.Write(encoded)
+	}
+
+	// PLAIN byte arrays: a little-endian length followed by the bytes
+	for _, row := range rows {
+		value := column.value(row)
+		if column.optional && value == "" {
+			continue
+		}
+		binary.Write(&page, binary.LittleEndian, uint32(len(value)))
+		page.WriteString(value)
+	}
+
+	header := &thriftWriter{}
+	header.beginStruct()
+	header.i32Field(1, parquetDataPage)
+	header.i32Field(2, int32(page.Len()))
+	header.i32Field(3, int32(page.Len()))
+	header.structField(5)
+	header.i32Field(1, int32(len(rows)))
+	header.i32Field(2, parquetEncodingPlain)
+	header.i32Field(3, parquetEncodingRLE)
+	header.i32Field(4, parquetEncodingRLE)
+	header.endStruct()
+	header.endStruct()
+
+	chunk := parquetChunk{offset: out.n, numValues: int64(len(rows))}
+	if _, err := out.Write(header.buf.Bytes()); err != nil {
+		return chunk, err
+	}
+	if _, err := out.Write(page.Bytes()); err != nil {
+		return chunk, err
+	}
+	chunk.size = out.n - chunk.offset
+	return chunk, nil
+}
+
+// rleDefinitionLevels encodes 1-bit definition levels with the RLE/bit-packing
+// hybrid encoding, using only run-length runs
+func rleDefinitionLevels(levels []bool) []byte {
+	var buf bytes.Buffer
+	var varint [binary.MaxVarintLen64]byte
+	for start := 0; start < len(levels); {
+		end := start
+		for end < len(levels) && levels[end] == levels[start] {
+			end++
+		}
+		// A run header is the run length shifted left once; the low bit 0 marks an RLE run
+		buf.Write(varint[:binary.PutUvarint(varint[:], uint64(end-start)<<1)])
+		if levels[start] {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+		start = end
+	}
+	return buf.Bytes()
+}
+
+// parquetFooter encodes the FileMetaData describing the schema and the
+// location of every column chunk
+func parquetFooter(rowGroups [][]parquetChunk, rowCounts []int, numRows int) []byte {
+	t := &thriftWriter{}
+	t.beginStruct()
+	t.i32Field(1, parquetFormatVersion)
+
+	// The schema is a root element followed by one element per column
+	t.listField(2, thriftStruct, len(parquetColumns)+1)
+	t.beginStruct()
+	t.stringField(4, "schema")
+	t.i32Field(5, int32(len(parquetColumns)))
+	t.endStruct()
+	for _, column := range parquetColumns {
+		repetition := int32(parquetRequired)
+		if column.optional {
+			repetition = parquetOptional
+		}
+		t.beginStruct()
+		t.i32Field(1, parquetTypeByteArray)
+		t.i32Field(3, repetition)
+		t.stringField(4, column.name)
+		t.i32Field(6, parquetConvertedUTF8)
+		t.endStruct()
+	}
+
+	t.i64Field(3, int64(numRows))
+
+	t.listField(4, thriftStruct, len(rowGroups))
+	for i, chunks := range rowGroups {
+		var groupSize int64
+		t.beginStruct()
+		t.listField(1, thriftStruct, len(chunks))
+		for j, chunk := range chunks {
+			groupSize += chunk.size
+			t.beginStruct()
+			t.i64Field(2, chunk.offset)
+			t.structField(3)
+			t.i32Field(1, parquetTypeByteArray)
+			t.listField(2, thriftI32, 2)
+			t.i32Element(parquetEncodingPlain)
+			t.i32Element(parquetEncodingRLE)
+			t.listField(3, thriftBinary, 1)
+			t.string(parquetColumns[j].name)
+			t.i32Field(4, parquetUncompressed)
+			t.i64Field(5, chunk.numValues)
+			t.i64Field(6, chunk.size)
+			t.i64Field(7, chunk.size)
+			t.i64Field(9, chunk.offset)
+			t.endStruct()
+			t.endStruct()
+		}
+		t.i64Field(2, groupSize)
+		t.i64Field(3, int64(rowCounts[i]))
+		t.endStruct()
+	}
+
+	t.stringField(6, parquetCreatedBy)
+	t.endStruct()
+	return t.buf.Bytes()
+}
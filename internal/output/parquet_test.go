@@ -0,0 +1,136 @@
+package output
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+// parquetRow is a quad as read back by parquet-go, with optional columns as
+// pointers so that NULL and "" can be told apart
+type parquetRow struct {
+	Subject         string  `parquet:"name=subject, type=BYTE_ARRAY, convertedtype=UTF8"`
+	SubjectURL      *string `parquet:"name=subject_url, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	Relationship    string  `parquet:"name=relationship, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Value           string  `parquet:"name=value, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ValueURL        *string `parquet:"name=value_url, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	Citation        *string `parquet:"name=citation, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	RawRelationship *string `parquet:"name=raw_relationship, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	Language        *string `parquet:"name=language, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	Source          *string `parquet:"name=source, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	Section         *string `parquet:"name=section, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	Project         *string `parquet:"name=project, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	ValueType       *string `parquet:"name=value_type, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	RawValue        *string `parquet:"name=raw_value, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	Number          *string `parquet:"name=number, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	Metric          *string `parquet:"name=metric, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	Imperial        *string `parquet:"name=imperial, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	CountryAlpha2   *string `parquet:"name=country_alpha2, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	CountryAlpha3   *string `parquet:"name=country_alpha3, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	Sources         *string `parquet:"name=sources, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	RawHTML         *string `parquet:"name=raw_html, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+}
+
+// readParquet decodes a file written by writeParquet with parquet-go
+func readParquet(t *testing.T, data []byte) []parquetRow {
+	t.Helper()
+	file, err := buffer.NewBufferFile(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, err := reader.NewParquetReader(file, new(parquetRow), 1)
+	if err != nil {
+		t.Fatalf("opening parquet: %v", err)
+	}
+	defer pr.ReadStop()
+
+	rows := make([]parquetRow, pr.GetNumRows())
+	if err := pr.Read(&rows); err != nil {
+		t.Fatalf("reading parquet: %v", err)
+	}
+	return rows
+}
+
+func TestWriteParquetReadBack(t *testing.T) {
+	quads := []extractor.Quad{
+		{
+			Subject:      "Paris",
+			SubjectURL:   "https://en.wikipedia.org/wiki/Paris",
+			Relationship: "Population",
+			Value:        "2,102,650",
+			Citation:     "https://www.insee.fr/",
+			Number:       "2102650",
+			Sources:      []string{"https://en.wikipedia.org/wiki/Paris", "https://fr.wikipedia.org/wiki/Paris"},
+		},
+		{Subject: "Zürich", Relationship: "Motto", Value: "«Ein Kanton» – 東京"},
+		{Subject: "Empty", Relationship: "Note", Value: ""},
+	}
+
+	var buf bytes.Buffer
+	if err := NewFormatter().WriteQuads(quads, &buf, "parquet"); err != nil {
+		t.Fatal(err)
+	}
+	rows := readParquet(t, buf.Bytes())
+	if len(rows) != len(quads) {
+		t.Fatalf("read %d rows, want %d", len(rows), len(quads))
+	}
+
+	for i, q := range quads {
+		row := rows[i]
+		if row.Subject != q.Subject || row.Relationship != q.Relationship || row.Value != q.Value {
+			t.Errorf("row %d = %q %q %q, want %q %q %q", i, row.Subject, row.Relationship, row.Value, q.Subject, q.Relationship, q.Value)
+		}
+		optional := map[string]struct {
+			got  *string
+			want string
+		}{
+			"subject_url": {row.SubjectURL, q.SubjectURL},
+			"citation":    {row.Citation, q.Citation},
+			"number":      {row.Number, q.Number},
+			"sources":     {row.Sources, strings.Join(q.Sources, " ")},
+			"raw_html":    {row.RawHTML, q.RawHTML},
+		}
+		for name, column := range optional {
+			switch {
+			case column.want == "" && column.got != nil:
+				t.Errorf("row %d %s = %q, want NULL", i, name, *column.got)
+			case column.want != "" && (column.got == nil || *column.got != column.want):
+				t.Errorf("row %d %s = %v, want %q", i, name, column.got, column.want)
+			}
+		}
+	}
+}
+
+func TestWriteParquetEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewFormatter().WriteQuads(nil, &buf, "parquet"); err != nil {
+		t.Fatal(err)
+	}
+	if rows := readParquet(t, buf.Bytes()); !reflect.DeepEqual(rows, []parquetRow{}) {
+		t.Errorf("read %d rows, want none", len(rows))
+	}
+}
+
+func TestWriteParquetRowGroups(t *testing.T) {
+	quads := make([]extractor.Quad, parquetRowGroupMaxRows+1)
+	for i := range quads {
+		quads[i] = extractor.Quad{Subject: "S", Relationship: "R", Value: strconv.Itoa(i)}
+	}
+	var buf bytes.Buffer
+	if err := NewFormatter().WriteQuads(quads, &buf, "parquet"); err != nil {
+		t.Fatal(err)
+	}
+	rows := readParquet(t, buf.Bytes())
+	if len(rows) != len(quads) {
+		t.Fatalf("read %d rows, want %d", len(rows), len(quads))
+	}
+	if last := rows[len(rows)-1].Value; last != strconv.Itoa(parquetRowGroupMaxRows) {
+		t.Errorf("last row of the second row group = %q, want %d", last, parquetRowGroupMaxRows)
+	}
+}
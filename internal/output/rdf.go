@@ -0,0 +1,173 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+const (
+	// DefaultBaseIRI is the namespace used to build subject IRIs from page titles
+	DefaultBaseIRI = "https://en.wikipedia.org/wiki/"
+
+	// DefaultPredicateNamespace is the namespace used to build predicate IRIs from relationships
+	DefaultPredicateNamespace = "https://github.com/chetankale/wikipedia-extraction/relationship/"
+)
+
+// iriSegment converts a title or label into an IRI path segment the way
+// Wikipedia does, replacing spaces with underscores
+func iriSegment(name string) string {
+	name = strings.Join(strings.Fields(name), "_")
+	return url.PathEscape(name)
+}
+
+// ParseNamespaceIRI checks that iri can prefix the names of subjects or
+// predicates: an absolute IRI with a scheme and no characters that would need
+// escaping, ending in "/" or "#" so the names form its last segment or fragment
+func ParseNamespaceIRI(iri string) (string, error) {
+	iri = strings.TrimSpace(iri)
+	if !isIRI(iri) {
+		return "", fmt.Errorf("invalid namespace IRI %q: must not be empty or contain spaces or any of <>\"{}|^`\\", iri)
+	}
+	u, err := url.Parse(iri)
+	if err != nil {
+		return "", fmt.Errorf("invalid namespace IRI %q: %w", iri, err)
+	}
+	if !u.IsAbs() || (u.Host == "" && u.Opaque == "") {
+		return "", fmt.Errorf("invalid namespace IRI %q: must be absolute, e.g. https://example.org/entity/", iri)
+	}
+	if !strings.HasSuffix(iri, "/") && !strings.HasSuffix(iri, "#") {
+		return "", fmt.Errorf("invalid namespace IRI %q: must end with / or #", iri)
+	}
+	return iri, nil
+}
+
+// baseIRI returns Formatter.BaseIRI, or DefaultBaseIRI when it is empty
+func (f *Formatter) baseIRI() string {
+	if f.BaseIRI == "" {
+		return DefaultBaseIRI
+	}
+	return f.BaseIRI
+}
+
+// predicateNamespace returns Formatter.PredicateNamespace, or
+// DefaultPredicateNamespace when it is empty
+func (f *Formatter) predicateNamespace() string {
+	if f.PredicateNamespace == "" {
+		return DefaultPredicateNamespace
+	}
+	return f.PredicateNamespace
+}
+
+// articleSegment returns the escaped title segment of a /wiki/ article URL
+func articleSegment(articleURL string) (string, bool) {
+	u, err := url.Parse(articleURL)
+	if err != nil {
+		return "", false
+	}
+	_, title, ok := strings.Cut(u.EscapedPath(), "/wiki/")
+	return title, ok && title != ""
+}
+
+// entityIRI returns the IRI term for an entity known by its article URL.
+// With the default base IRI the article URL itself is used; a custom base
+// IRI takes the article's title instead, so entities that link to each other
+// still share one IRI.
+func (f *Formatter) entityIRI(articleURL string) string {
+	if f.BaseIRI != "" {
+		if segment, ok := articleSegment(articleURL); ok {
+			return "<" + f.BaseIRI + segment + ">"
+		}
+	}
+	return "<" + articleURL + ">"
+}
+
+// subjectIRI returns the IRI term for a quad's subject: its canonical article
+// URL when known, otherwise an IRI built from the subject name
+func (f *Formatter) subjectIRI(quad extractor.Quad) string {
+	if isIRI(quad.SubjectURL) {
+		return f.entityIRI(quad.SubjectURL)
+	}
+	return "<" + f.baseIRI() + iriSegment(quad.Subject) + ">"
+}
+
+// objectTerm returns the RDF term for a quad's value: the IRI of the linked
+// article when the value is an entity with a known URL, otherwise a literal
+func (f *Formatter) objectTerm(quad extractor.Quad) string {
+	if isIRI(quad.ValueURL) {
+		return f.entityIRI(quad.ValueURL)
+	}
+	return rdfLiteral(quad.Value)
+}
+
+// isIRI reports whether s is non-empty and can be written between angle
+// brackets as an IRI without escaping
+func isIRI(s string) bool {
+	return s != "" && !strings.ContainsAny(s, "<>\"{}|^`\\ ")
+}
+
+// predicateIRI returns the IRI term for a quad's relationship
+func (f *Formatter) predicateIRI(relationship string) string {
+	return "<" + f.predicateNamespace() + iriSegment(relationship) + ">"
+}
+
+// rdfLiteral returns value as a quoted, escaped RDF string literal
+func rdfLiteral(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// writeTurtle writes quads as RDF Turtle triples. Citations have no place in a
+// plain triple and are omitted.
+func (f *Formatter) writeTurtle(quads []extractor.Quad, w io.Writer) error {
+	for _, quad := range quads {
+		_, err := fmt.Fprintf(w, "%s %s %s .\n",
+			f.subjectIRI(quad), f.predicateIRI(quad.Relationship), f.objectTerm(quad))
+		if err != nil {
+			return fmt.Errorf("failed to write triple: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// writeNQuads writes quads as RDF N-Quads, one statement per line, naming the
+// graph after the quad's source URL so provenance stays with each fact.
+// Quads without a source, such as those parsed from a local file, are written
+// to the default graph.
+func (f *Formatter) writeNQuads(quads []extractor.Quad, w io.Writer) error {
+	for _, quad := range quads {
+		graph := ""
+		if isIRI(quad.Source) {
+			graph = " <" + quad.Source + ">"
+		}
+		_, err := fmt.Fprintf(w, "%s %s %s%s .\n",
+			f.subjectIRI(quad), f.predicateIRI(quad.Relationship), f.objectTerm(quad), graph)
+		if err != nil {
+			return fmt.Errorf("failed to write quad: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+)
+
+// WriteRecords writes stored quad records in the given format (json, jsonl,
+// csv or tsv). Unlike WriteQuads, the formatter's columns may include the record
+// columns id, source_url, extracted_at and tag.
+func (f *Formatter) WriteRecords(records []storage.QuadRecord, w io.Writer, format string) error {
+	switch format {
+	case "json":
+		if len(f.Columns) == 0 {
+			if records == nil {
+				records = []storage.QuadRecord{}
+			}
+			return f.newJSONEncoder(w).Encode(records)
+		}
+		rows := make([]orderedRecord, len(records))
+		for i, record := range records {
+			rows[i] = orderedRecord{columns: f.Columns, values: f.recordRow(record)}
+		}
+		return f.newJSONEncoder(w).Encode(rows)
+	case "jsonl":
+		encoder := json.NewEncoder(w)
+		for _, record := range records {
+			var err error
+			if len(f.Columns) == 0 {
+				err = encoder.Encode(record)
+			} else {
+				err = encoder.Encode(orderedRecord{columns: f.Columns, values: f.recordRow(record)})
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case "csv", "tsv":
+		if f.BOM {
+			if _, err := io.WriteString(w, utf8BOM); err != nil {
+				return err
+			}
+		}
+		writer := csv.NewWriter(w)
+		if format == "tsv" {
+			writer.Comma = '\t'
+		}
+		if err := writer.Write(f.headerRow()); err != nil {
+			return fmt.Errorf("failed to write CSV header: %w", err)
+		}
+		for _, record := range records {
+			if err := writer.Write(f.recordRow(record)); err != nil {
+				return fmt.Errorf("failed to write CSV record: %w", err)
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("unsupported record format: %s (valid formats: json, jsonl, csv, tsv)", format)
+	}
+}
+
+// recordRow returns the values of the formatter's columns for a stored record
+func (f *Formatter) recordRow(record storage.QuadRecord) []string {
+	quad := record.Quad()
+	columns := f.columns()
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		switch column {
+		case "id":
+			values[i] = strconv.FormatInt(record.ID, 10)
+		case "source_url":
+			values[i] = record.SourceURL
+		case "extracted_at":
+			values[i] = record.ExtractedAt.UTC().Format(time.RFC3339)
+		case "tag":
+			values[i] = record.Tag
+		default:
+			values[i] = columnValue(quad, column)
+		}
+	}
+	return values
+}
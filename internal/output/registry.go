@@ -0,0 +1,127 @@
+package output
+
+import (
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+// Writer writes quads in one output format. The Formatter passed in carries
+// output options such as Columns.
+//
+// A Writer may also implement ContentType() string and FileExtension() string
+// to declare the MIME type served over HTTP and the extension used for
+// per-page files. Without them, formats are served as
+// application/octet-stream and written with the format name as extension.
+// A Writer that makes one write per quad may implement Streaming() bool,
+// reporting true, so the HTTP service flushes its output as it is written.
+type Writer interface {
+	WriteQuads(f *Formatter, quads []extractor.Quad, w io.Writer) error
+}
+
+// WriterFunc adapts an ordinary function to the Writer interface
+type WriterFunc func(f *Formatter, quads []extractor.Quad, w io.Writer) error
+
+// WriteQuads calls fn(f, quads, w)
+func (fn WriterFunc) WriteQuads(f *Formatter, quads []extractor.Quad, w io.Writer) error {
+	return fn(f, quads, w)
+}
+
+// builtinWriter is a Writer for the formats that ship with the tool
+type builtinWriter struct {
+	contentType string
+	extension   string
+	write       func(f *Formatter, quads []extractor.Quad, w io.Writer) error
+}
+
+func (b builtinWriter) WriteQuads(f *Formatter, quads []extractor.Quad, w io.Writer) error {
+	return b.write(f, quads, w)
+}
+
+func (b builtinWriter) ContentType() string {
+	return b.contentType
+}
+
+func (b builtinWriter) FileExtension() string {
+	return b.extension
+}
+
+// streamingWriter is a builtinWriter whose format writes one quad at a time
+type streamingWriter struct {
+	builtinWriter
+}
+
+func (streamingWriter) Streaming() bool {
+	return true
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Writer)
+)
+
+// RegisterFormat makes a format available to every command and the HTTP
+// service under name, replacing any format already registered under it
+func RegisterFormat(name string, writer Writer) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = writer
+}
+
+// lookupFormat returns the Writer registered for a format
+func lookupFormat(name string) (Writer, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	writer, ok := registry[name]
+	return writer, ok
+}
+
+// IsFormat reports whether a format is registered
+func IsFormat(name string) bool {
+	_, ok := lookupFormat(name)
+	return ok
+}
+
+// IsStreamingFormat reports whether a format writes its output one quad at
+// a time, so that it is worth flushing to a client as it is written
+func IsStreamingFormat(name string) bool {
+	writer, ok := lookupFormat(name)
+	if !ok {
+		return false
+	}
+	streaming, ok := writer.(interface{ Streaming() bool })
+	return ok && streaming.Streaming()
+}
+
+// Formats returns the names of all registered formats in sorted order
+func Formats() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterFormat("json", builtinWriter{"application/json", ".json", (*Formatter).writeJSON})
+	RegisterFormat("jsonl", streamingWriter{builtinWriter{"application/x-ndjson", ".jsonl", (*Formatter).writeJSONLines}})
+	RegisterFormat("csv", builtinWriter{"text/csv", ".csv", func(f *Formatter, quads []extractor.Quad, w io.Writer) error {
+		return f.writeDelimited(quads, w, ',')
+	}})
+	RegisterFormat("tsv", builtinWriter{"text/tab-separated-values", ".tsv", func(f *Formatter, quads []extractor.Quad, w io.Writer) error {
+		return f.writeDelimited(quads, w, '\t')
+	}})
+	RegisterFormat("xml", builtinWriter{"application/xml", ".xml", (*Formatter).writeXML})
+	RegisterFormat("turtle", builtinWriter{"text/turtle", ".ttl", (*Formatter).writeTurtle})
+	RegisterFormat("nquads", streamingWriter{builtinWriter{"application/n-quads", ".nq", (*Formatter).writeNQuads}})
+	RegisterFormat("markdown", builtinWriter{"text/markdown", ".md", (*Formatter).writeMarkdown})
+	RegisterFormat("sql", builtinWriter{"application/sql", ".sql", (*Formatter).writeSQL})
+	RegisterFormat("parquet", builtinWriter{"application/vnd.apache.parquet", ".parquet", (*Formatter).writeParquet})
+	RegisterFormat("avro", builtinWriter{"application/avro", ".avro", (*Formatter).writeAvro})
+}
@@ -0,0 +1,75 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// WriteQualityReport writes a data-quality report to w in the given format
+// (json, yaml, csv or tsv). csv and tsv write one row per metric, sparse
+// subject, relationship and empty source, keyed by the Metric column.
+func (f *Formatter) WriteQualityReport(report *storage.QualityReport, w io.Writer, format string) error {
+	switch format {
+	case "json":
+		return f.newJSONEncoder(w).Encode(report)
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		defer encoder.Close()
+		return encoder.Encode(report)
+	case "csv", "tsv":
+		writer := csv.NewWriter(w)
+		if format == "tsv" {
+			writer.Comma = '\t'
+		}
+		writer.Write([]string{"Metric", "Name", "Value"})
+		writer.Write([]string{"total_quads", "", strconv.Itoa(report.TotalQuads)})
+		writer.Write([]string{"cited_quads", "", strconv.Itoa(report.CitedQuads)})
+		writer.Write([]string{"cited_percent", "", strconv.FormatFloat(report.CitedPercent, 'f', 1, 64)})
+		writer.Write([]string{"total_subjects", "", strconv.Itoa(report.TotalSubjects)})
+		for _, subject := range report.SparseSubjects {
+			writer.Write([]string{"sparse_subject", subject.Subject, strconv.Itoa(subject.Facts)})
+		}
+		for _, relationship := range report.TopRelationships {
+			writer.Write([]string{"relationship", relationship.Relationship, strconv.Itoa(relationship.Quads)})
+		}
+		for _, source := range report.EmptySources {
+			writer.Write([]string{"empty_source", source, "0"})
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("unsupported report format: %s (valid formats: json, yaml, csv, tsv)", format)
+	}
+}
+
+// WriteQualityReportText writes a data-quality report as a listing for
+// reading in a terminal
+func WriteQualityReportText(report *storage.QualityReport, w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Quads: %d (%d cited, %.1f%%)\n", report.TotalQuads, report.CitedQuads, report.CitedPercent)
+	fmt.Fprintf(&b, "Subjects: %d\n", report.TotalSubjects)
+
+	fmt.Fprintf(&b, "\nSubjects with fewer than %d facts: %d\n", report.MinFacts, len(report.SparseSubjects))
+	for _, subject := range report.SparseSubjects {
+		fmt.Fprintf(&b, "  %s (%d)\n", subject.Subject, subject.Facts)
+	}
+
+	b.WriteString("\nMost common relationships:\n")
+	for _, relationship := range report.TopRelationships {
+		fmt.Fprintf(&b, "  %s (%d)\n", relationship.Relationship, relationship.Quads)
+	}
+
+	fmt.Fprintf(&b, "\nSources with no quads: %d\n", len(report.EmptySources))
+	for _, source := range report.EmptySources {
+		fmt.Fprintf(&b, "  %s\n", source)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
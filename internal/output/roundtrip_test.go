@@ -0,0 +1,292 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// roundTripQuads are quads whose text needs quoting or escaping in at least
+// one format. Carriage returns are left out: CSV readers return a quoted
+// "\r\n" as "\n".
+var roundTripQuads = []extractor.Quad{
+	{
+		Subject:      `Café "Le Monde"`,
+		Relationship: "Owner, founder",
+		Value:        "Line one\nLine two",
+		Citation:     "https://example.org/?a=1&b=2",
+		Source:       "https://en.wikipedia.org/wiki/Caf%C3%A9_Le_Monde",
+	},
+	{
+		Subject:      "O'Brien",
+		Relationship: "Tab\tseparated",
+		Value:        `C:\path\to "file"; DROP TABLE quads; --`,
+	},
+	{
+		Subject:      "東京",
+		Relationship: "Population",
+		Value:        "13,960,000 <approx> & more",
+		Citation:     "'quoted' citation",
+	},
+	{
+		Subject:      "Spacing",
+		Relationship: "Notes",
+		Value:        "  padded  ",
+	},
+}
+
+// writeFormat writes quads in format with f
+func writeFormat(t *testing.T, f *Formatter, quads []extractor.Quad, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := f.WriteQuads(quads, &buf, format); err != nil {
+		t.Fatalf("WriteQuads(%s): %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+// fourFields keeps the subject, relationship, value and citation of quads,
+// the fields written by the tabular formats
+func fourFields(quads []extractor.Quad) []extractor.Quad {
+	kept := make([]extractor.Quad, len(quads))
+	for i, q := range quads {
+		kept[i] = extractor.Quad{Subject: q.Subject, Relationship: q.Relationship, Value: q.Value, Citation: q.Citation}
+	}
+	return kept
+}
+
+// rdfFields keeps what the RDF formats write of quads: the subject and
+// relationship with runs of whitespace collapsed, as IRI segments keep them, and
+// the value. nquads also keeps the source as the graph.
+func rdfFields(withGraph bool) func([]extractor.Quad) []extractor.Quad {
+	return func(quads []extractor.Quad) []extractor.Quad {
+		kept := make([]extractor.Quad, len(quads))
+		for i, q := range quads {
+			kept[i] = extractor.Quad{
+				Subject:      strings.Join(strings.Fields(q.Subject), " "),
+				Relationship: strings.Join(strings.Fields(q.Relationship), " "),
+				Value:        q.Value,
+			}
+			if withGraph {
+				kept[i].Source = q.Source
+			}
+		}
+		return kept
+	}
+}
+
+func TestWriteQuadsRoundTrip(t *testing.T) {
+	tests := []struct {
+		format string
+		read   func(t *testing.T, data []byte) []extractor.Quad
+		kept   func([]extractor.Quad) []extractor.Quad
+	}{
+		{"json", readJSON, nil},
+		{"jsonl", readJSONLines, nil},
+		{"csv", readDelimitedQuads(','), fourFields},
+		{"tsv", readDelimitedQuads('\t'), fourFields},
+		{"xml", readXML, fourFields},
+		{"turtle", readRDF, rdfFields(false)},
+		{"nquads", readRDF, rdfFields(true)},
+		{"sql", readSQL, fourFields},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			f := NewFormatter()
+			f.WithSchema = true
+			want := roundTripQuads
+			if tt.kept != nil {
+				want = tt.kept(roundTripQuads)
+			}
+
+			got := tt.read(t, writeFormat(t, f, roundTripQuads, tt.format))
+			if len(got) != len(want) {
+				t.Fatalf("read back %d quads, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if !reflect.DeepEqual(got[i], want[i]) {
+					t.Errorf("quad %d\n  got  %+v\n  want %+v", i, got[i], want[i])
+				}
+			}
+
+			if got := tt.read(t, writeFormat(t, f, nil, tt.format)); len(got) != 0 {
+				t.Errorf("read back %d quads from empty output, want none", len(got))
+			}
+		})
+	}
+}
+
+func readJSON(t *testing.T, data []byte) []extractor.Quad {
+	var quads []extractor.Quad
+	if err := json.Unmarshal(data, &quads); err != nil {
+		t.Fatalf("decoding JSON: %v\n%s", err, data)
+	}
+	return quads
+}
+
+func readJSONLines(t *testing.T, data []byte) []extractor.Quad {
+	var quads []extractor.Quad
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var quad extractor.Quad
+		if err := json.Unmarshal(scanner.Bytes(), &quad); err != nil {
+			t.Fatalf("decoding JSON line %q: %v", scanner.Text(), err)
+		}
+		quads = append(quads, quad)
+	}
+	return quads
+}
+
+func readDelimitedQuads(delimiter rune) func(t *testing.T, data []byte) []extractor.Quad {
+	return func(t *testing.T, data []byte) []extractor.Quad {
+		_, rows := readDelimited(t, data, delimiter)
+		if len(rows) == 0 || !reflect.DeepEqual(rows[0], []string{"Subject", "Relationship", "Value", "Citation"}) {
+			t.Fatalf("header row = %q", rows)
+		}
+		var quads []extractor.Quad
+		for _, row := range rows[1:] {
+			quads = append(quads, extractor.Quad{Subject: row[0], Relationship: row[1], Value: row[2], Citation: row[3]})
+		}
+		return quads
+	}
+}
+
+func readXML(t *testing.T, data []byte) []extractor.Quad {
+	var doc xmlQuads
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("decoding XML: %v\n%s", err, data)
+	}
+	var quads []extractor.Quad
+	for _, q := range doc.Quads {
+		quads = append(quads, extractor.Quad{Subject: q.Subject, Relationship: q.Relationship, Value: q.Value, Citation: q.Citation})
+	}
+	return quads
+}
+
+// readRDF parses turtle or nquads output written with the default
+// namespaces, turning subject and predicate IRIs back into names
+func readRDF(t *testing.T, data []byte) []extractor.Quad {
+	var quads []extractor.Quad
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		terms, err := parseRDFStatement(scanner.Text())
+		if err != nil {
+			t.Fatalf("parsing %q: %v", scanner.Text(), err)
+		}
+		if len(terms) < 3 || len(terms) > 4 {
+			t.Fatalf("statement %q has %d terms", scanner.Text(), len(terms))
+		}
+		quad := extractor.Quad{
+			Subject:      rdfName(t, terms[0], DefaultBaseIRI),
+			Relationship: rdfName(t, terms[1], DefaultPredicateNamespace),
+			Value:        terms[2],
+		}
+		if len(terms) == 4 {
+			quad.Source = terms[3]
+		}
+		quads = append(quads, quad)
+	}
+	return quads
+}
+
+// rdfName returns the name an IRI gives within namespace, with "_" read as a space
+func rdfName(t *testing.T, iri, namespace string) string {
+	segment, ok := strings.CutPrefix(iri, namespace)
+	if !ok {
+		t.Fatalf("IRI %q is not in %s", iri, namespace)
+	}
+	name, err := url.PathUnescape(segment)
+	if err != nil {
+		t.Fatalf("unescaping %q: %v", segment, err)
+	}
+	return strings.ReplaceAll(name, "_", " ")
+}
+
+// parseRDFStatement splits an N-Triples or N-Quads line into its terms,
+// returning IRIs without their angle brackets and literals unescaped
+func parseRDFStatement(line string) ([]string, error) {
+	var terms []string
+	for i := 0; i < len(line); {
+		switch line[i] {
+		case ' ':
+			i++
+		case '.':
+			if strings.TrimSpace(line[i+1:]) != "" {
+				return nil, fmt.Errorf("text after the final dot")
+			}
+			return terms, nil
+		case '<':
+			end := strings.IndexByte(line[i:], '>')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated IRI")
+			}
+			terms = append(terms, line[i+1:i+end])
+			i += end + 1
+		case '"':
+			end := i + 1
+			for ; end < len(line) && line[end] != '"'; end++ {
+				if line[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(line) {
+				return nil, fmt.Errorf("unterminated literal")
+			}
+			literal, err := strconv.Unquote(line[i : end+1])
+			if err != nil {
+				return nil, err
+			}
+			terms = append(terms, literal)
+			i = end + 1
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d", line[i], i)
+		}
+	}
+	return nil, fmt.Errorf("missing final dot")
+}
+
+// readSQL runs sql output against an in-memory SQLite database and reads
+// the quads table back
+func readSQL(t *testing.T, data []byte) []extractor.Quad {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(string(data)); err != nil {
+		t.Fatalf("running SQL: %v\n%s", err, data)
+	}
+	rows, err := db.Query("SELECT subject, relationship, value, citation FROM quads ORDER BY rowid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var quads []extractor.Quad
+	for rows.Next() {
+		var quad extractor.Quad
+		var citation sql.NullString
+		if err := rows.Scan(&quad.Subject, &quad.Relationship, &quad.Value, &citation); err != nil {
+			t.Fatal(err)
+		}
+		quad.Citation = citation.String
+		quads = append(quads, quad)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return quads
+}
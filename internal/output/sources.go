@@ -0,0 +1,42 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// WriteSources writes the stored sources to w in the given format (json,
+// yaml, csv or tsv)
+func (f *Formatter) WriteSources(sources []storage.SourceInfo, w io.Writer, format string) error {
+	switch format {
+	case "json":
+		if sources == nil {
+			// An empty database is an empty catalog, not null
+			sources = []storage.SourceInfo{}
+		}
+		return f.newJSONEncoder(w).Encode(sources)
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		defer encoder.Close()
+		return encoder.Encode(sources)
+	case "csv", "tsv":
+		writer := csv.NewWriter(w)
+		if format == "tsv" {
+			writer.Comma = '\t'
+		}
+		writer.Write([]string{"Source URL", "Quads", "Last Extracted"})
+		for _, source := range sources {
+			writer.Write([]string{source.SourceURL, strconv.Itoa(source.Quads), source.LastExtracted.Format(time.RFC3339)})
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("unsupported sources format: %s (valid formats: json, yaml, csv, tsv)", format)
+	}
+}
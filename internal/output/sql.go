@@ -0,0 +1,71 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+// sqlTable is the table named in SQL output
+const sqlTable = "quads"
+
+// sqlRequiredColumns are the columns declared NOT NULL in the SQL schema.
+// Other columns are written as NULL when empty.
+var sqlRequiredColumns = map[string]bool{
+	"subject":      true,
+	"relationship": true,
+	"value":        true,
+}
+
+// writeSQL writes quads as INSERT statements, one per quad, preceded by the
+// CREATE TABLE statement when WithSchema is set
+func (f *Formatter) writeSQL(quads []extractor.Quad, w io.Writer) error {
+	columns := f.columns()
+
+	if f.WithSchema {
+		if _, err := io.WriteString(w, sqlSchema(columns)); err != nil {
+			return err
+		}
+	}
+
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (", sqlTable, strings.Join(columns, ", "))
+	for _, quad := range quads {
+		values := f.record(quad)
+		for i, value := range values {
+			values[i] = sqlLiteral(value, !sqlRequiredColumns[columns[i]])
+		}
+		if _, err := fmt.Fprintf(w, "%s%s);\n", insert, strings.Join(values, ", ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqlSchema returns the CREATE TABLE statement for a quads table with the given columns
+func sqlSchema(columns []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE IF NOT EXISTS %s (\n", sqlTable)
+	for i, column := range columns {
+		fmt.Fprintf(&b, "    %s TEXT", column)
+		if sqlRequiredColumns[column] {
+			b.WriteString(" NOT NULL")
+		}
+		if i < len(columns)-1 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString(");\n")
+	return b.String()
+}
+
+// sqlLiteral returns value as a single-quoted SQL string literal, doubling
+// embedded quotes. Empty values are written as NULL when nullable is set.
+func sqlLiteral(value string, nullable bool) string {
+	if value == "" && nullable {
+		return "NULL"
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,39 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/chetankale/wikipedia-extraction/internal/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// WriteStats writes storage statistics to w in the given format (json, csv or yaml)
+func (f *Formatter) WriteStats(stats *storage.Stats, w io.Writer, format string) error {
+	switch format {
+	case "json":
+		encoder := f.newJSONEncoder(w)
+		return encoder.Encode(stats)
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		defer encoder.Close()
+		return encoder.Encode(stats)
+	case "csv":
+		writer, err := f.newDelimitedWriter(w, ',')
+		if err != nil {
+			return err
+		}
+		writer.Write(f.reportHeaderRow("total_quads", "total_subjects", "total_sources", "last_extraction"))
+		writer.Write([]string{
+			strconv.Itoa(stats.TotalQuads),
+			strconv.Itoa(stats.TotalSubjects),
+			strconv.Itoa(stats.TotalSources),
+			stats.LastExtraction,
+		})
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("unsupported stats format: %s", format)
+	}
+}
@@ -0,0 +1,40 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+// ParseTemplate parses a text/template executed once per quad, with the
+// fields of extractor.Quad (Subject, Relationship, Value, Citation, Source,
+// ...) available as {{.Subject}} and so on. The template is also executed
+// against an empty quad so references to unknown fields are reported now
+// rather than part way through the output.
+func ParseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("quad").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(io.Discard, extractor.Quad{}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// writeTemplate writes each quad through the formatter's template, followed by a newline
+func (f *Formatter) writeTemplate(quads []extractor.Quad, w io.Writer) error {
+	buffered := bufio.NewWriter(w)
+	for _, quad := range quads {
+		if err := f.Template.Execute(buffered, quad); err != nil {
+			return fmt.Errorf("failed to execute template: %w", err)
+		}
+		if err := buffered.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return buffered.Flush()
+}
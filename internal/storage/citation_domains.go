@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+// citationHostPattern matches the host of each http(s) URL in a citation.
+// A quad's citation joins its URLs with a configurable separator, so the
+// host stops at any character a host can't contain.
+var citationHostPattern = regexp.MustCompile(`(?i)\bhttps?://([^/\s?#:;,|<>"']+)`)
+
+// citationDomains returns the distinct domains of the URLs in a citation, in
+// the order they are cited
+func citationDomains(citation string) []string {
+	var domains []string
+	for _, match := range citationHostPattern.FindAllStringSubmatch(citation, -1) {
+		domain := normalizeDomain(match[1])
+		if domain != "" && !slices.Contains(domains, domain) {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+// normalizeDomain lowercases a host and drops a "www." prefix, so that
+// www.nytimes.com and nytimes.com are the same domain
+func normalizeDomain(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	return strings.TrimPrefix(host, "www.")
+}
+
+// insertCitationDomains records the citation domains of quads inserted with
+// consecutive row IDs starting at firstID, setting each quad's
+// citation_domain to its first cited domain and indexing every domain
+func insertCitationDomains(tx *sql.Tx, quads []extractor.Quad, firstID int64) error {
+	for i, quad := range quads {
+		if quad.Citation == "" {
+			continue
+		}
+		if err := saveCitationDomains(tx, firstID+int64(i), citationDomains(quad.Citation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveCitationDomains sets a quad's citation_domain and indexes each of its
+// domains. A cited quad without any URL gets an empty citation_domain, so
+// it isn't looked at again by backfillCitationDomains.
+func saveCitationDomains(tx *sql.Tx, quadID int64, domains []string) error {
+	first := ""
+	if len(domains) > 0 {
+		first = domains[0]
+	}
+	if _, err := tx.Exec("UPDATE quads SET citation_domain = ? WHERE id = ?", first, quadID); err != nil {
+		return fmt.Errorf("failed to set citation domain: %w", err)
+	}
+	for _, domain := range domains {
+		if _, err := tx.Exec("INSERT INTO citation_domains (quad_id, domain) VALUES (?, ?)", quadID, domain); err != nil {
+			return fmt.Errorf("failed to index citation domain: %w", err)
+		}
+	}
+	return nil
+}
+
+// backfillCitationDomains indexes the citation domains of quads stored
+// before citation domains were recorded
+func backfillCitationDomains(db *sql.DB) error {
+	rows, err := db.Query("SELECT id, citation FROM quads WHERE citation IS NOT NULL AND citation_domain IS NULL")
+	if err != nil {
+		return err
+	}
+	citations := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var citation string
+		if err := rows.Scan(&id, &citation); err != nil {
+			rows.Close()
+			return err
+		}
+		citations[id] = citation
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(citations) == 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for id, citation := range citations {
+		if err := saveCitationDomains(tx, id, citationDomains(citation)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetByCitationDomain retrieves all quads with a citation to a domain or any
+// of its subdomains, so nytimes.com also matches cooking.nytimes.com
+func (s *SQLiteStorage) GetByCitationDomain(domain string) ([]extractor.Quad, error) {
+	domain = normalizeDomain(domain)
+	if domain == "" {
+		return nil, nil
+	}
+	where, args := citationDomainClause(domain)
+	return s.queryQuads(`
+		SELECT subject, relationship, value, citation, subject_url
+		FROM quads
+		WHERE `+where+`
+		ORDER BY extracted_at DESC
+	`, args...)
+}
+
+// escapeLike escapes the LIKE wildcards in s for use with ESCAPE '\'
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// citationDomainClause returns a WHERE condition matching the quads cited to
+// domain or its subdomains, for QueryRecords
+func citationDomainClause(domain string) (string, []interface{}) {
+	domain = normalizeDomain(domain)
+	return `id IN (SELECT quad_id FROM citation_domains WHERE domain = ? OR domain LIKE ? ESCAPE '\')`,
+		[]interface{}{domain, "%." + escapeLike(domain)}
+}
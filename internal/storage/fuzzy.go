@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+// DefaultFuzzyLimit is the number of subjects GetBySubjectFuzzy returns when
+// limit is zero
+const DefaultFuzzyLimit = 5
+
+// SubjectMatch is a stored subject found by GetBySubjectFuzzy, with its
+// Levenshtein distance from the query and its quads
+type SubjectMatch struct {
+	Subject  string           `json:"subject" yaml:"subject"`
+	Distance int              `json:"distance" yaml:"distance"`
+	Quads    []extractor.Quad `json:"quads" yaml:"quads"`
+}
+
+// GetBySubjectFuzzy ranks the stored subjects by their edit distance from
+// subject, ignoring case, and returns the closest limit of them, closest
+// first, each with its quads. Subjects sharing no character position with the
+// query, whose distance equals the longer length, are left out.
+func (s *SQLiteStorage) GetBySubjectFuzzy(subject string, limit int) ([]SubjectMatch, error) {
+	if limit <= 0 {
+		limit = DefaultFuzzyLimit
+	}
+
+	rows, err := s.db.Query("SELECT DISTINCT subject FROM quads")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subjects: %w", err)
+	}
+	defer rows.Close()
+
+	query := []rune(strings.ToLower(subject))
+	var matches []SubjectMatch
+	for rows.Next() {
+		var candidate string
+		if err := rows.Scan(&candidate); err != nil {
+			return nil, fmt.Errorf("failed to scan subject: %w", err)
+		}
+		runes := []rune(strings.ToLower(candidate))
+		distance := levenshtein(query, runes)
+		if distance >= max(len(query), len(runes)) {
+			continue
+		}
+		matches = append(matches, SubjectMatch{Subject: candidate, Distance: distance})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read subjects: %w", err)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Distance != matches[j].Distance {
+			return matches[i].Distance < matches[j].Distance
+		}
+		return matches[i].Subject < matches[j].Subject
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	for i := range matches {
+		matches[i].Quads, err = s.queryQuads(`
+			SELECT subject, relationship, value, citation, subject_url
+			FROM quads
+			WHERE subject = ?
+			ORDER BY extracted_at DESC
+		`, matches[i].Subject)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return matches, nil
+}
+
+// levenshtein returns the number of single character insertions, deletions
+// and substitutions needed to turn a into b
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// ErrDatabaseBusy is returned by Maintain when another connection is writing
+// to the database
+var ErrDatabaseBusy = errors.New("database is busy with another writer")
+
+// MaintenanceReport describes the effect of Maintain on the database file
+type MaintenanceReport struct {
+	// SizeBefore and SizeAfter are the database size in bytes, not counting
+	// the write-ahead log, before and after maintenance
+	SizeBefore int64 `json:"size_before"`
+	SizeAfter  int64 `json:"size_after"`
+	// RebuiltIndexes lists the full-text search tables that were rebuilt
+	RebuiltIndexes []string `json:"rebuilt_indexes,omitempty"`
+}
+
+// Maintain compacts the database with VACUUM, refreshes the query planner's
+// statistics with ANALYZE and rebuilds any full-text search tables. It
+// returns ErrDatabaseBusy rather than waiting indefinitely when another
+// connection, such as a running store or HTTP service, holds the write lock
+// that a step needs.
+func (s *SQLiteStorage) Maintain(ctx context.Context) (*MaintenanceReport, error) {
+	// Everything runs on one connection, as the sizes are read from it
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	report := &MaintenanceReport{}
+	if report.SizeBefore, err = databaseSize(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	// VACUUM can't run inside a transaction, so no lock is held across the
+	// steps: each one that writes takes the write lock itself, failing with
+	// SQLITE_BUSY after the busy timeout while another connection is writing,
+	// which maintenanceError reports as ErrDatabaseBusy
+	if report.RebuiltIndexes, err = rebuildFullTextIndexes(ctx, conn); err != nil {
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, "VACUUM"); err != nil {
+		return nil, maintenanceError("vacuum", err)
+	}
+	if _, err := conn.ExecContext(ctx, "ANALYZE"); err != nil {
+		return nil, maintenanceError("analyze", err)
+	}
+	// Fold the write-ahead log back into the database file and truncate it
+	if _, err := conn.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return nil, maintenanceError("checkpoint", err)
+	}
+
+	if report.SizeAfter, err = databaseSize(ctx, conn); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// maintenanceError wraps a failed maintenance step, reporting lock
+// contention as ErrDatabaseBusy
+func maintenanceError(step string, err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked) {
+		return fmt.Errorf("failed to %s: %w", step, ErrDatabaseBusy)
+	}
+	return fmt.Errorf("failed to %s: %w", step, err)
+}
+
+// databaseSize returns the size in bytes of the main database file
+func databaseSize(ctx context.Context, conn *sql.Conn) (int64, error) {
+	var pageCount, pageSize int64
+	if err := conn.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pageCount); err != nil {
+		return 0, fmt.Errorf("failed to read page count: %w", err)
+	}
+	if err := conn.QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("failed to read page size: %w", err)
+	}
+	return pageCount * pageSize, nil
+}
+
+// rebuildFullTextIndexes rebuilds every FTS3, FTS4 and FTS5 table in the
+// database and returns their names
+func rebuildFullTextIndexes(ctx context.Context, conn *sql.Conn) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, `
+		SELECT name FROM sqlite_master
+		WHERE type = 'table' AND lower(sql) LIKE '%using fts%'
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list full-text tables: %w", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan full-text table: %w", err)
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list full-text tables: %w", err)
+	}
+
+	for _, table := range tables {
+		quoted := `"` + table + `"`
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s(%s) VALUES ('rebuild')", quoted, quoted)); err != nil {
+			return nil, maintenanceError("rebuild "+table, err)
+		}
+	}
+	return tables, nil
+}
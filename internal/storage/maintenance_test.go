@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMaintain(t *testing.T) {
+	store := newTestStorage(t)
+	if err := store.Store(testQuads(500), "https://en.wikipedia.org/wiki/Examplia", time.Now(), ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.db.Exec("DELETE FROM quads"); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := store.Maintain(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.SizeBefore <= 0 || report.SizeAfter >= report.SizeBefore {
+		t.Errorf("size went from %d to %d bytes, want it to shrink", report.SizeBefore, report.SizeAfter)
+	}
+}
+
+// Maintain reports another connection's write lock as ErrDatabaseBusy once
+// the busy timeout runs out, rather than checking for it up front
+func TestMaintainBusy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	// The first _busy_timeout in the DSN wins, so waiting stays short
+	store, err := NewSQLiteStorage(path + "?_busy_timeout=50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	writer, err := NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+
+	tx, err := writer.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Store(testQuads(1), "https://en.wikipedia.org/wiki/Examplia", time.Now(), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Maintain(context.Background()); !errors.Is(err, ErrDatabaseBusy) {
+		t.Errorf("Maintain during a write: error = %v, want ErrDatabaseBusy", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Maintain(context.Background()); err != nil {
+		t.Errorf("Maintain after the write: %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+// QueryFilter selects the quad records returned by QueryRecords. Every field
+// that is set must match; a zero QueryFilter matches all records.
+type QueryFilter struct {
+	// Subject and Relationship match as case-insensitive substrings, like
+	// GetBySubject and GetByRelationship
+	Subject      string
+	Relationship string
+	// Value matches as a case-insensitive substring, or exactly when
+	// ValueExact is set, like GetByValue
+	Value      string
+	ValueExact bool
+	// SourceURL and Tag match exactly
+	SourceURL string
+	Tag       string
+	// CitationDomain matches quads citing the domain or its subdomains
+	CitationDomain string
+	// Search is a full-text query as accepted by Search
+	Search string
+	// Since and Until bound the extraction time; zero times are unbounded
+	Since time.Time
+	Until time.Time
+	// Limit caps the number of records returned; zero means unlimited
+	Limit int
+}
+
+// Quad returns the record as a quad, with Source set to its source URL
+func (r QuadRecord) Quad() extractor.Quad {
+	return extractor.Quad{
+		Subject:      r.Subject,
+		SubjectURL:   r.SubjectURL,
+		Relationship: r.Relationship,
+		Value:        r.Value,
+		Citation:     r.Citation,
+		Source:       r.SourceURL,
+	}
+}
+
+// QueryRecords retrieves the stored quads matching filter along with their
+// provenance: row ID, source URL, extraction time and tag. Records are
+// returned most recently extracted first.
+func (s *SQLiteStorage) QueryRecords(filter QueryFilter) ([]QuadRecord, error) {
+	query := `
+		SELECT id, subject, relationship, value, citation, subject_url, source_url, extracted_at, tag
+		FROM quads
+		WHERE 1 = 1`
+	var args []interface{}
+
+	if filter.Subject != "" {
+		query += " AND subject LIKE ?"
+		args = append(args, "%"+filter.Subject+"%")
+	}
+	if filter.Relationship != "" {
+		query += " AND relationship LIKE ?"
+		args = append(args, "%"+filter.Relationship+"%")
+	}
+	if filter.Value != "" && filter.ValueExact {
+		query += " AND value = ?"
+		args = append(args, filter.Value)
+	} else if filter.Value != "" {
+		query += " AND value LIKE ?"
+		args = append(args, "%"+filter.Value+"%")
+	}
+	if filter.SourceURL != "" {
+		query += " AND source_url = ?"
+		args = append(args, filter.SourceURL)
+	}
+	if filter.Tag != "" {
+		query += " AND tag = ?"
+		args = append(args, filter.Tag)
+	}
+	if filter.CitationDomain != "" {
+		where, domainArgs := citationDomainClause(filter.CitationDomain)
+		query += " AND " + where
+		args = append(args, domainArgs...)
+	}
+	if filter.Search != "" {
+		where, searchArgs := searchClause(filter.Search)
+		query += " AND (" + where + ")"
+		args = append(args, searchArgs...)
+	}
+	if !filter.Since.IsZero() {
+		query += " AND julianday(extracted_at) >= julianday(?)"
+		args = append(args, filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		query += " AND julianday(extracted_at) <= julianday(?)"
+		args = append(args, filter.Until.UTC())
+	}
+	query += " ORDER BY julianday(extracted_at) DESC, id"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []QuadRecord
+	for rows.Next() {
+		var record QuadRecord
+		var citation, subjectURL, tag sql.NullString
+		err := rows.Scan(&record.ID, &record.Subject, &record.Relationship, &record.Value, &citation,
+			&subjectURL, &record.SourceURL, &record.ExtractedAt, &tag)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan record: %w", err)
+		}
+		record.Citation = citation.String
+		record.SubjectURL = subjectURL.String
+		record.Tag = tag.String
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read records: %w", err)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"fmt"
+)
+
+// QualityReport summarizes the health of the stored data for data stewards
+type QualityReport struct {
+	TotalQuads    int `json:"total_quads" yaml:"total_quads"`
+	CitedQuads    int `json:"cited_quads" yaml:"cited_quads"`
+	TotalSubjects int `json:"total_subjects" yaml:"total_subjects"`
+	// CitedPercent is the share of quads with a citation, from 0 to 100
+	CitedPercent float64 `json:"cited_percent" yaml:"cited_percent"`
+	// MinFacts is the threshold below which a subject counts as sparse
+	MinFacts int `json:"min_facts" yaml:"min_facts"`
+	// SparseSubjects are the subjects with fewer than MinFacts distinct
+	// facts, fewest first
+	SparseSubjects []SubjectFacts `json:"sparse_subjects" yaml:"sparse_subjects"`
+	// TopRelationships are the most common relationships, most quads first
+	TopRelationships []RelationshipCount `json:"top_relationships" yaml:"top_relationships"`
+	// EmptySources are the fetched source URLs without any stored quads
+	EmptySources []string `json:"empty_sources" yaml:"empty_sources"`
+}
+
+// SubjectFacts counts the distinct relationship and value pairs stored
+// about a subject
+type SubjectFacts struct {
+	Subject string `json:"subject" yaml:"subject"`
+	Facts   int    `json:"facts" yaml:"facts"`
+}
+
+// RelationshipCount counts the quads stored with a relationship
+type RelationshipCount struct {
+	Relationship string `json:"relationship" yaml:"relationship"`
+	Quads        int    `json:"quads" yaml:"quads"`
+}
+
+// latestQuads selects the quads of the latest extraction of each source, so
+// that a page stored again is counted once rather than once per extraction
+const latestQuads = `
+	SELECT quads.*
+	FROM quads
+	JOIN (
+		SELECT source_url, MAX(julianday(extracted_at)) AS latest
+		FROM quads
+		GROUP BY source_url
+	) AS latest USING (source_url)
+	WHERE julianday(quads.extracted_at) = latest.latest
+`
+
+// GetQualityReport computes a QualityReport over the latest extraction of
+// each source. Subjects with fewer than minFacts distinct facts are listed as
+// sparse, along with the topRelationships most common relationships.
+func (s *SQLiteStorage) GetQualityReport(minFacts, topRelationships int) (*QualityReport, error) {
+	report := &QualityReport{
+		MinFacts:         minFacts,
+		SparseSubjects:   []SubjectFacts{},
+		TopRelationships: []RelationshipCount{},
+		EmptySources:     []string{},
+	}
+
+	err := s.db.QueryRow(`
+		SELECT COUNT(*), COUNT(citation), COUNT(DISTINCT subject)
+		FROM (`+latestQuads+`)
+	`).Scan(&report.TotalQuads, &report.CitedQuads, &report.TotalSubjects)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count quads: %w", err)
+	}
+	if report.TotalQuads > 0 {
+		report.CitedPercent = 100 * float64(report.CitedQuads) / float64(report.TotalQuads)
+	}
+
+	if err := s.getSparseSubjects(report); err != nil {
+		return nil, err
+	}
+	if err := s.getTopRelationships(report, topRelationships); err != nil {
+		return nil, err
+	}
+	if err := s.getEmptySources(report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// getSparseSubjects fills in the subjects with fewer than report.MinFacts
+// distinct facts
+func (s *SQLiteStorage) getSparseSubjects(report *QualityReport) error {
+	rows, err := s.db.Query(`
+		SELECT subject, COUNT(*) AS facts
+		FROM (SELECT DISTINCT subject, relationship, value FROM (`+latestQuads+`))
+		GROUP BY subject
+		HAVING COUNT(*) < ?
+		ORDER BY facts, subject
+	`, report.MinFacts)
+	if err != nil {
+		return fmt.Errorf("failed to query sparse subjects: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var subject SubjectFacts
+		if err := rows.Scan(&subject.Subject, &subject.Facts); err != nil {
+			return fmt.Errorf("failed to scan sparse subject: %w", err)
+		}
+		report.SparseSubjects = append(report.SparseSubjects, subject)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read sparse subjects: %w", err)
+	}
+	return nil
+}
+
+// getTopRelationships fills in the limit most common relationships
+func (s *SQLiteStorage) getTopRelationships(report *QualityReport, limit int) error {
+	rows, err := s.db.Query(`
+		SELECT relationship, COUNT(*) AS quads
+		FROM (`+latestQuads+`)
+		GROUP BY relationship
+		ORDER BY quads DESC, relationship
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return fmt.Errorf("failed to query top relationships: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var relationship RelationshipCount
+		if err := rows.Scan(&relationship.Relationship, &relationship.Quads); err != nil {
+			return fmt.Errorf("failed to scan relationship count: %w", err)
+		}
+		report.TopRelationships = append(report.TopRelationships, relationship)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read relationship counts: %w", err)
+	}
+	return nil
+}
+
+// getEmptySources fills in the source URLs that were fetched but have no
+// stored quads, such as pages whose extraction came back empty
+func (s *SQLiteStorage) getEmptySources(report *QualityReport) error {
+	rows, err := s.db.Query(`
+		SELECT source_url
+		FROM sources
+		WHERE source_url NOT IN (SELECT source_url FROM quads)
+		ORDER BY source_url
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to query empty sources: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sourceURL string
+		if err := rows.Scan(&sourceURL); err != nil {
+			return fmt.Errorf("failed to scan empty source: %w", err)
+		}
+		report.EmptySources = append(report.EmptySources, sourceURL)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read empty sources: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+func TestGetQualityReportLatestExtraction(t *testing.T) {
+	store := newTestStorage(t)
+	const (
+		paris  = "https://en.wikipedia.org/wiki/Paris"
+		berlin = "https://en.wikipedia.org/wiki/Berlin"
+	)
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Paris is stored, then refreshed with one citation gone and a quad
+	// added; only the refresh should count
+	err := store.Store([]extractor.Quad{
+		{Subject: "Paris", Relationship: "Country", Value: "France", Citation: "https://example.org/a"},
+		{Subject: "Paris", Relationship: "Population", Value: "2,102,650", Citation: "https://example.org/b"},
+	}, paris, first, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.RefreshSource([]extractor.Quad{
+		{Subject: "Paris", Relationship: "Country", Value: "France", Citation: "https://example.org/a"},
+		{Subject: "Paris", Relationship: "Population", Value: "2,102,650"},
+		{Subject: "Paris", Relationship: "Mayor", Value: "Anne Hidalgo"},
+	}, paris, first.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Stored twice with the same quads, as a re-run of store does
+	berlinQuads := []extractor.Quad{{Subject: "Berlin", Relationship: "Country", Value: "Germany"}}
+	for _, at := range []time.Time{first, first.Add(time.Hour)} {
+		if err := store.Store(berlinQuads, berlin, at, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := store.GetQualityReport(3, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.TotalQuads != 4 || report.CitedQuads != 1 || report.TotalSubjects != 2 {
+		t.Errorf("totals = %d quads, %d cited, %d subjects, want 4, 1, 2",
+			report.TotalQuads, report.CitedQuads, report.TotalSubjects)
+	}
+	if report.CitedPercent != 25 {
+		t.Errorf("CitedPercent = %v, want 25", report.CitedPercent)
+	}
+	wantSparse := []SubjectFacts{{"Berlin", 1}}
+	if !reflect.DeepEqual(report.SparseSubjects, wantSparse) {
+		t.Errorf("SparseSubjects = %+v, want %+v", report.SparseSubjects, wantSparse)
+	}
+	wantTop := []RelationshipCount{{"Country", 2}, {"Mayor", 1}, {"Population", 1}}
+	if !reflect.DeepEqual(report.TopRelationships, wantTop) {
+		t.Errorf("TopRelationships = %+v, want %+v", report.TopRelationships, wantTop)
+	}
+}
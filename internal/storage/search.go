@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"strings"
+)
+
+// searchFields are the quad columns a search term is matched against
+var searchFields = []string{"subject", "relationship", "value", "citation"}
+
+// parseSearchQuery parses a search query into alternatives, each a list of
+// terms that must all match. The grammar is:
+//
+//	query  = group { "OR" group }
+//	group  = term { ["AND"] term }
+//	term   = word | '"' phrase '"'
+//
+// AND binds tighter than OR, and terms next to each other without an
+// operator must both match. The operators are only recognized in upper case,
+// so "and" and "or" are ordinary words. Empty alternatives are dropped.
+func parseSearchQuery(query string) [][]string {
+	var alternatives [][]string
+	var group []string
+
+	for _, token := range searchTokens(query) {
+		switch {
+		case !token.quoted && token.text == "OR":
+			if len(group) > 0 {
+				alternatives = append(alternatives, group)
+			}
+			group = nil
+		case !token.quoted && token.text == "AND":
+			// Adjacent terms are ANDed anyway
+		default:
+			group = append(group, token.text)
+		}
+	}
+	if len(group) > 0 {
+		alternatives = append(alternatives, group)
+	}
+
+	return alternatives
+}
+
+// searchToken is a word or quoted phrase of a search query
+type searchToken struct {
+	text   string
+	quoted bool
+}
+
+// searchTokens splits a query into whitespace-separated words and
+// double-quoted phrases. An unterminated quote runs to the end of the query.
+func searchTokens(query string) []searchToken {
+	var tokens []searchToken
+	for {
+		query = strings.TrimLeft(query, " \t\n")
+		if query == "" {
+			return tokens
+		}
+
+		if query[0] == '"' {
+			phrase, rest, _ := strings.Cut(query[1:], `"`)
+			if phrase = strings.TrimSpace(phrase); phrase != "" {
+				tokens = append(tokens, searchToken{text: phrase, quoted: true})
+			}
+			query = rest
+			continue
+		}
+
+		end := strings.IndexAny(query, " \t\n\"")
+		if end < 0 {
+			end = len(query)
+		}
+		tokens = append(tokens, searchToken{text: query[:end]})
+		query = query[end:]
+	}
+}
+
+// searchClause builds the WHERE condition and arguments for a search query.
+// Each term must appear as a substring of one of the searchFields.
+func searchClause(query string) (string, []interface{}) {
+	alternatives := parseSearchQuery(query)
+	if len(alternatives) == 0 {
+		return "1 = 1", nil
+	}
+
+	var args []interface{}
+	ors := make([]string, len(alternatives))
+	for i, terms := range alternatives {
+		ands := make([]string, len(terms))
+		for j, term := range terms {
+			matches := make([]string, len(searchFields))
+			for k, field := range searchFields {
+				matches[k] = field + " LIKE ?"
+				args = append(args, "%"+term+"%")
+			}
+			ands[j] = "(" + strings.Join(matches, " OR ") + ")"
+		}
+		ors[i] = "(" + strings.Join(ands, " AND ") + ")"
+	}
+
+	return strings.Join(ors, " OR "), args
+}
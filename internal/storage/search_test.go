@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  [][]string
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"paris", [][]string{{"paris"}}},
+		{"paris france", [][]string{{"paris", "france"}}},
+		{"paris AND france", [][]string{{"paris", "france"}}},
+		{"paris OR london", [][]string{{"paris"}, {"london"}}},
+		{"capital AND paris OR london", [][]string{{"capital", "paris"}, {"london"}}},
+		{"paris OR london AND capital", [][]string{{"paris"}, {"london", "capital"}}},
+		{"paris or london", [][]string{{"paris", "or", "london"}}},
+		{`"New York" OR "Los Angeles"`, [][]string{{"New York"}, {"Los Angeles"}}},
+		{`"AND" "OR"`, [][]string{{"AND", "OR"}}},
+		{`"unterminated phrase`, [][]string{{"unterminated phrase"}}},
+		{`""  paris`, [][]string{{"paris"}}},
+		{"OR paris OR OR london OR", [][]string{{"paris"}, {"london"}}},
+		{`city"state"`, [][]string{{"city", "state"}}},
+	}
+	for _, tt := range tests {
+		if got := parseSearchQuery(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSearchQuery(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	store := newTestStorage(t)
+	quads := []extractor.Quad{
+		{Subject: "Paris", Relationship: "Country", Value: "France"},
+		{Subject: "Paris", Relationship: "Mayor", Value: "Anne Hidalgo"},
+		{Subject: "London", Relationship: "Country", Value: "United Kingdom"},
+		{Subject: "New York City", Relationship: "Country", Value: "United States", Citation: "https://census.example.org/"},
+		{Subject: "York", Relationship: "Country", Value: "United Kingdom"},
+	}
+	if err := store.Store(quads, "https://en.wikipedia.org/wiki/Cities", time.Now(), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"paris", []string{"Paris Country", "Paris Mayor"}},
+		{"Paris AND France", []string{"Paris Country"}},
+		{"Paris France", []string{"Paris Country"}},
+		{"France OR Kingdom", []string{"London Country", "Paris Country", "York Country"}},
+		{"Country AND Kingdom OR Mayor", []string{"London Country", "Paris Mayor", "York Country"}},
+		{`"New York"`, []string{"New York City Country"}},
+		{"York", []string{"New York City Country", "York Country"}},
+		{"census", []string{"New York City Country"}},
+		{"Berlin", nil},
+		{"", []string{"London Country", "New York City Country", "Paris Country", "Paris Mayor", "York Country"}},
+	}
+	for _, tt := range tests {
+		results, err := store.Search(tt.query)
+		if err != nil {
+			t.Fatalf("Search(%q): %v", tt.query, err)
+		}
+		var got []string
+		for _, q := range results {
+			got = append(got, q.Subject+" "+q.Relationship)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Search(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
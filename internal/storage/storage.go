@@ -1,8 +1,10 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/chetankale/wikipedia-extraction/internal/extractor"
@@ -11,34 +13,114 @@ import (
 
 // Storage interface defines methods for storing and retrieving quads
 type Storage interface {
-	// Store stores a collection of quads with metadata
-	Store(quads []extractor.Quad, sourceURL string, extractedAt time.Time) error
+	// Store stores a collection of quads with metadata, labelled with an optional tag
+	Store(quads []extractor.Quad, sourceURL string, extractedAt time.Time, tag string) error
+	
+	// Begin starts a transaction grouping the stores of several sources into one atomic unit
+	Begin() (Tx, error)
+	
+	// ReplaceSource replaces all stored quads of a source URL with a new extraction, under the tag of the latest
+	ReplaceSource(quads []extractor.Quad, sourceURL string, extractedAt time.Time) error
+	
+	// RefreshSource stores a new extraction of a source URL next to its earlier ones, under the tag of the latest
+	RefreshSource(quads []extractor.Quad, sourceURL string, extractedAt time.Time) error
+	
+	// GetSourceURLs lists every distinct source URL with stored quads
+	GetSourceURLs() ([]string, error)
+	
+	// ListSources lists every source URL with stored quads, with its quad count and last extraction time
+	ListSources() ([]SourceInfo, error)
 	
 	// GetBySubject retrieves all quads for a given subject
 	GetBySubject(subject string) ([]extractor.Quad, error)
 	
+	// GetBySubjectFuzzy retrieves the quads of the stored subjects closest to a subject by edit distance
+	GetBySubjectFuzzy(subject string, limit int) ([]SubjectMatch, error)
+	
 	// GetByRelationship retrieves all quads with a specific relationship
 	GetByRelationship(relationship string) ([]extractor.Quad, error)
 	
+	// GetByValue retrieves all quads whose value contains value, or equals it when exact is set
+	GetByValue(value string, exact bool) ([]extractor.Quad, error)
+	
 	// GetBySourceURL retrieves all quads from a specific source URL
 	GetBySourceURL(sourceURL string) ([]extractor.Quad, error)
 	
-	// Search searches quads by text in any field
+	// GetByTag retrieves all quads stored with a tag
+	GetByTag(tag string) ([]extractor.Quad, error)
+	
+	// GetByCitationDomain retrieves all quads citing a domain or its subdomains
+	GetByCitationDomain(domain string) ([]extractor.Quad, error)
+	
+	// QueryRecords retrieves the quads matching a filter with their IDs, source URLs, extraction times and tags
+	QueryRecords(filter QueryFilter) ([]QuadRecord, error)
+	
+	// Search searches quads by text in any field, supporting AND and OR
 	Search(query string) ([]extractor.Quad, error)
 	
+	// GetLatestBySourceURL retrieves the quads from the most recent extraction of a source URL
+	GetLatestBySourceURL(sourceURL string) ([]extractor.Quad, error)
+	
+	// GetByTimeRange retrieves all quads extracted within a time range; zero times are unbounded
+	GetByTimeRange(since, until time.Time) ([]extractor.Quad, error)
+	
+	// GetExtractionTimes lists when a source URL was extracted, most recent first
+	GetExtractionTimes(sourceURL string) ([]time.Time, error)
+	
+	// GetSnapshot retrieves the quads from the latest extraction of a source URL at or before a time,
+	// along with when that extraction happened
+	GetSnapshot(sourceURL string, at time.Time) ([]extractor.Quad, time.Time, error)
+	
+	// GetSubjectLinks retrieves the links between stored subjects
+	GetSubjectLinks() ([]SubjectLink, error)
+	
+	// GetCorroborated retrieves the facts stored from at least minSources distinct source URLs
+	GetCorroborated(minSources int) ([]CorroboratedFact, error)
+	
 	// GetStats returns storage statistics
 	GetStats() (*Stats, error)
 	
+	// GetQualityReport computes citation coverage, sparse subjects, the most common relationships and empty sources
+	GetQualityReport(minFacts, topRelationships int) (*QualityReport, error)
+	
+	// GetSource retrieves the fetch metadata recorded for a source URL, or nil if none exists
+	GetSource(sourceURL string) (*SourceRecord, error)
+	
+	// SaveSource records the fetch metadata for a source URL, replacing any previous record
+	SaveSource(source SourceRecord) error
+	
+	// GetIdempotencyRecord retrieves the response recorded for an idempotency key, or nil if none exists
+	GetIdempotencyRecord(key string) (*IdempotencyRecord, error)
+	
+	// SaveIdempotencyRecord records the response for an idempotency key
+	SaveIdempotencyRecord(record IdempotencyRecord) error
+	
+	// DeleteIdempotencyRecordsBefore removes idempotency records created before a time
+	DeleteIdempotencyRecordsBefore(before time.Time) error
+	
+	// Ping checks that the database answers a query
+	Ping(ctx context.Context) error
+	
 	// Close closes the storage connection
 	Close() error
 }
 
 // Stats represents storage statistics
 type Stats struct {
-	TotalQuads     int    `json:"total_quads"`
-	TotalSubjects  int    `json:"total_subjects"`
-	TotalSources   int    `json:"total_sources"`
-	LastExtraction string `json:"last_extraction"`
+	TotalQuads     int    `json:"total_quads" yaml:"total_quads"`
+	TotalSubjects  int    `json:"total_subjects" yaml:"total_subjects"`
+	TotalSources   int    `json:"total_sources" yaml:"total_sources"`
+	LastExtraction string `json:"last_extraction" yaml:"last_extraction"`
+	// Tags breaks the totals down by tag; it is empty when no quads are tagged
+	Tags []TagStats `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// TagStats counts the quads stored with one tag. Untagged quads have an empty Tag.
+type TagStats struct {
+	Tag      string `json:"tag" yaml:"tag"`
+	Quads    int    `json:"quads" yaml:"quads"`
+	Subjects int    `json:"subjects" yaml:"subjects"`
+	Sources  int    `json:"sources" yaml:"sources"`
 }
 
 // QuadRecord represents a quad with metadata for storage
@@ -48,8 +130,57 @@ type QuadRecord struct {
 	Relationship string   `json:"relationship"`
 	Value       string    `json:"value"`
 	Citation    string    `json:"citation"`
+	SubjectURL  string    `json:"subject_url,omitempty"`
 	SourceURL   string    `json:"source_url"`
 	ExtractedAt time.Time `json:"extracted_at"`
+	Tag         string    `json:"tag,omitempty"`
+}
+
+// SourceRecord represents the fetch metadata tracked for a source URL
+type SourceRecord struct {
+	SourceURL    string    `json:"source_url"`
+	ETag         string    `json:"etag"`
+	LastModified string    `json:"last_modified"`
+	ContentHash  string    `json:"content_hash"`
+	PageID       int64     `json:"page_id"`
+	RevisionID   int64     `json:"revision_id"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	// PageLastModified is when the page was last edited according to the page itself; zero if unknown
+	PageLastModified time.Time `json:"page_last_modified"`
+}
+
+// SourceInfo summarizes the quads stored from one source URL
+type SourceInfo struct {
+	SourceURL     string    `json:"source_url" yaml:"source_url"`
+	Quads         int       `json:"quads" yaml:"quads"`
+	LastExtracted time.Time `json:"last_extracted" yaml:"last_extracted"`
+}
+
+// SubjectLink is a directed edge between two stored subjects: a quad of
+// Subject whose value names another stored subject
+type SubjectLink struct {
+	Subject      string `json:"subject"`
+	Relationship string `json:"relationship"`
+	Target       string `json:"target"`
+}
+
+// CorroboratedFact is a (subject, relationship, value) triple asserted by
+// several source pages, with the number of distinct sources asserting it
+type CorroboratedFact struct {
+	Subject      string `json:"subject" yaml:"subject"`
+	Relationship string `json:"relationship" yaml:"relationship"`
+	Value        string `json:"value" yaml:"value"`
+	Sources      int    `json:"sources" yaml:"sources"`
+}
+
+// IdempotencyRecord is the response recorded for a request carrying an
+// Idempotency-Key, replayed when the request is retried
+type IdempotencyRecord struct {
+	Key         string    `json:"key"`
+	RequestHash string    `json:"request_hash"`
+	StatusCode  int       `json:"status_code"`
+	Body        string    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 // SQLiteStorage implements Storage interface using SQLite
@@ -57,15 +188,47 @@ type SQLiteStorage struct {
 	db *sql.DB
 }
 
-// NewSQLiteStorage creates a new SQLite storage instance
+const (
+	// busyTimeoutMillis is how long a connection waits for a lock before failing with "database is locked"
+	busyTimeoutMillis = 5000
+	
+	// maxOpenConns bounds the connection pool. WAL mode lets these connections
+	// read concurrently while a single writer is active.
+	maxOpenConns = 4
+
+	// maxIdleConns is how many pooled connections are kept open between queries
+	maxIdleConns = 2
+)
+
+// NewSQLiteStorage creates a new SQLite storage instance.
+//
+// The database is opened in WAL journal mode with a busy timeout and foreign
+// keys enabled, so queries can run while a store is in progress. The pragmas
+// are passed as DSN parameters so that every pooled connection gets them,
+// since busy_timeout and foreign_keys are per-connection settings.
 func NewSQLiteStorage(dbPath string) (*SQLiteStorage, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+	sep := "?"
+	if strings.Contains(dbPath, "?") {
+		sep = "&"
+	}
+	dsn := fmt.Sprintf("%s%s_journal_mode=WAL&_busy_timeout=%d&_foreign_keys=on&_synchronous=NORMAL",
+		dbPath, sep, busyTimeoutMillis)
+	
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(maxIdleConns)
+	
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
 	
 	// Create tables if they don't exist
 	if err := createTables(db); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to create tables: %w", err)
 	}
 	
@@ -81,101 +244,528 @@ func createTables(db *sql.DB) error {
 		relationship TEXT NOT NULL,
 		value TEXT NOT NULL,
 		citation TEXT,
+		citation_domain TEXT,
+		subject_url TEXT,
 		source_url TEXT NOT NULL,
+		tag TEXT,
 		extracted_at DATETIME NOT NULL,
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	);
 	`
 	
+	sourcesTable := `
+	CREATE TABLE IF NOT EXISTS sources (
+		source_url TEXT PRIMARY KEY,
+		etag TEXT,
+		last_modified TEXT,
+		content_hash TEXT,
+		page_id INTEGER,
+		revision_id INTEGER,
+		page_last_modified DATETIME,
+		fetched_at DATETIME NOT NULL
+	);
+	`
+	
+	// A quad citing several URLs has a row for each distinct domain
+	citationDomainsTable := `
+	CREATE TABLE IF NOT EXISTS citation_domains (
+		quad_id INTEGER NOT NULL REFERENCES quads(id) ON DELETE CASCADE,
+		domain TEXT NOT NULL
+	);
+	`
+	
+	idempotencyTable := `
+	CREATE TABLE IF NOT EXISTS idempotency_keys (
+		key TEXT PRIMARY KEY,
+		request_hash TEXT NOT NULL,
+		status_code INTEGER NOT NULL,
+		body TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	`
+	
 	// Create indexes for better performance
 	indexes := []string{
 		"CREATE INDEX IF NOT EXISTS idx_quads_subject ON quads(subject);",
 		"CREATE INDEX IF NOT EXISTS idx_quads_relationship ON quads(relationship);",
+		"CREATE INDEX IF NOT EXISTS idx_quads_value ON quads(value);",
 		"CREATE INDEX IF NOT EXISTS idx_quads_source_url ON quads(source_url);",
 		"CREATE INDEX IF NOT EXISTS idx_quads_extracted_at ON quads(extracted_at);",
+		"CREATE INDEX IF NOT EXISTS idx_quads_tag ON quads(tag);",
+		"CREATE INDEX IF NOT EXISTS idx_citation_domains_domain ON citation_domains(domain);",
+		"CREATE INDEX IF NOT EXISTS idx_citation_domains_quad_id ON citation_domains(quad_id);",
 	}
 	
 	if _, err := db.Exec(quadsTable); err != nil {
 		return err
 	}
 	
+	if _, err := db.Exec(sourcesTable); err != nil {
+		return err
+	}
+	
+	if _, err := db.Exec(citationDomainsTable); err != nil {
+		return err
+	}
+	
+	if _, err := db.Exec(idempotencyTable); err != nil {
+		return err
+	}
+	
+	// Add columns introduced after a table was first created
+	if err := ensureColumn(db, "sources", "content_hash", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "sources", "page_id", "INTEGER"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "sources", "revision_id", "INTEGER"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "sources", "page_last_modified", "DATETIME"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "quads", "subject_url", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "quads", "tag", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "quads", "citation_domain", "TEXT"); err != nil {
+		return err
+	}
+	
+	if err := migrate(db); err != nil {
+		return err
+	}
+	
 	for _, index := range indexes {
 		if _, err := db.Exec(index); err != nil {
 			return err
 		}
 	}
 	
+	return backfillCitationDomains(db)
+}
+
+// migrations are one-off data fixes for databases written by older
+// versions, applied in order. PRAGMA user_version records how many have run,
+// so each runs once per database instead of scanning the quads on every open.
+// New fixes are appended; existing entries must never change.
+var migrations = []string{
+	// Uncited quads used to be stored with a "no citation" placeholder
+	"UPDATE quads SET citation = NULL WHERE citation = 'no citation'",
+}
+
+// migrate applies the migrations a database has not had yet, each in its own
+// transaction together with the user_version recording it
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	
+	for ; version < len(migrations); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin migration: %w", err)
+		}
+		if _, err := tx.Exec(migrations[version]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply migration %d: %w", version+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", version+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", version+1, err)
+		}
+	}
+	
 	return nil
 }
 
-// Store stores a collection of quads with metadata
-func (s *SQLiteStorage) Store(quads []extractor.Quad, sourceURL string, extractedAt time.Time) error {
+// ensureColumn adds a column to an existing table if it is missing, so that
+// databases created by older versions pick up new columns
+func ensureColumn(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+// insertBatchSize is the number of quads written by each multi-row INSERT statement
+const insertBatchSize = 500
+
+// Store stores a collection of quads with metadata. A non-empty tag labels
+// the quads, e.g. with the campaign that extracted them, for GetByTag.
+func (s *SQLiteStorage) Store(quads []extractor.Quad, sourceURL string, extractedAt time.Time, tag string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	
+	if err := insertQuads(tx, quads, sourceURL, extractedAt, tag); err != nil {
+		return err
+	}
+	
+	return tx.Commit()
+}
+
+// ReplaceSource replaces all stored quads of a source URL, including earlier
+// extractions, with a new extraction in a single transaction. The new quads
+// keep the tag of the source's latest extraction.
+func (s *SQLiteStorage) ReplaceSource(quads []extractor.Quad, sourceURL string, extractedAt time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	
+	tag, err := latestTag(tx, sourceURL)
+	if err != nil {
+		return err
+	}
+	
+	if _, err := tx.Exec("DELETE FROM quads WHERE source_url = ?", sourceURL); err != nil {
+		return fmt.Errorf("failed to delete quads: %w", err)
+	}
+	
+	if err := insertQuads(tx, quads, sourceURL, extractedAt, tag); err != nil {
+		return err
+	}
+	
+	return tx.Commit()
+}
+
+// RefreshSource stores a new extraction of a source URL in a single
+// transaction. Earlier extractions are kept, so the source's history stays
+// available to GetSnapshot and diff. The new quads keep the tag of the
+// source's latest extraction.
+func (s *SQLiteStorage) RefreshSource(quads []extractor.Quad, sourceURL string, extractedAt time.Time) error {
 	tx, err := s.db.Begin()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 	
-	stmt, err := tx.Prepare(`
-		INSERT INTO quads (subject, relationship, value, citation, source_url, extracted_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+	tag, err := latestTag(tx, sourceURL)
+	if err != nil {
+		return err
+	}
+	
+	if err := insertQuads(tx, quads, sourceURL, extractedAt, tag); err != nil {
+		return err
+	}
+	
+	return tx.Commit()
+}
+
+// latestTag returns the tag of the latest stored extraction of a source URL
+func latestTag(tx *sql.Tx, sourceURL string) (string, error) {
+	var tag sql.NullString
+	err := tx.QueryRow("SELECT tag FROM quads WHERE source_url = ? ORDER BY julianday(extracted_at) DESC, id DESC LIMIT 1", sourceURL).Scan(&tag)
+	if err != nil && err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to look up tag: %w", err)
+	}
+	return tag.String, nil
+}
+
+// ListSources lists every source URL with stored quads, with its quad count
+// and the time of its most recent extraction, ordered by URL
+func (s *SQLiteStorage) ListSources() ([]SourceInfo, error) {
+	// The bare extracted_at column takes its value from the row with the
+	// latest time, and keeps its declared type so it scans into a time.Time
+	rows, err := s.db.Query(`
+		SELECT source_url, COUNT(*), MAX(julianday(extracted_at)), extracted_at
+		FROM quads
+		GROUP BY source_url
+		ORDER BY source_url
 	`)
 	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %w", err)
+		return nil, fmt.Errorf("failed to query sources: %w", err)
+	}
+	defer rows.Close()
+	
+	var sources []SourceInfo
+	for rows.Next() {
+		var source SourceInfo
+		var latest float64
+		if err := rows.Scan(&source.SourceURL, &source.Quads, &latest, &source.LastExtracted); err != nil {
+			return nil, fmt.Errorf("failed to scan source: %w", err)
+		}
+		sources = append(sources, source)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read sources: %w", err)
+	}
+	
+	return sources, nil
+}
+
+// GetSourceURLs lists every distinct source URL with stored quads
+func (s *SQLiteStorage) GetSourceURLs() ([]string, error) {
+	rows, err := s.db.Query("SELECT DISTINCT source_url FROM quads ORDER BY source_url")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query source URLs: %w", err)
+	}
+	defer rows.Close()
+	
+	var urls []string
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, fmt.Errorf("failed to scan source URL: %w", err)
+		}
+		urls = append(urls, url)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read source URLs: %w", err)
+	}
+	
+	return urls, nil
+}
+
+// insertQuads inserts quads in batches of insertBatchSize
+func insertQuads(tx *sql.Tx, quads []extractor.Quad, sourceURL string, extractedAt time.Time, tag string) error {
+	for start := 0; start < len(quads); start += insertBatchSize {
+		end := min(start+insertBatchSize, len(quads))
+		if err := insertQuadBatch(tx, quads[start:end], sourceURL, extractedAt, tag); err != nil {
+			return err
+		}
 	}
-	defer stmt.Close()
+	return nil
+}
+
+// insertQuadBatch inserts a batch of quads with a single multi-row INSERT
+// statement, then indexes their citation domains. SQLite assigns the rows of
+// one INSERT consecutive IDs, ending at the last insert ID.
+func insertQuadBatch(tx *sql.Tx, quads []extractor.Quad, sourceURL string, extractedAt time.Time, tag string) error {
+	placeholders := make([]string, len(quads))
+	args := make([]interface{}, 0, len(quads)*8)
 	
-	for _, quad := range quads {
-		_, err := stmt.Exec(
+	for i, quad := range quads {
+		placeholders[i] = "(?, ?, ?, ?, ?, ?, ?, ?)"
+		args = append(args,
 			quad.Subject,
 			quad.Relationship,
 			quad.Value,
-			quad.Citation,
+			sql.NullString{String: quad.Citation, Valid: quad.Citation != ""},
+			sql.NullString{String: quad.SubjectURL, Valid: quad.SubjectURL != ""},
 			sourceURL,
 			extractedAt,
+			sql.NullString{String: tag, Valid: tag != ""},
 		)
-		if err != nil {
-			return fmt.Errorf("failed to insert quad: %w", err)
-		}
 	}
 	
-	return tx.Commit()
+	query := `INSERT INTO quads (subject, relationship, value, citation, subject_url, source_url, extracted_at, tag) VALUES ` +
+		strings.Join(placeholders, ", ")
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to insert quads: %w", err)
+	}
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get inserted quad IDs: %w", err)
+	}
+	
+	return insertCitationDomains(tx, quads, lastID-int64(len(quads))+1)
 }
 
 // GetBySubject retrieves all quads for a given subject
 func (s *SQLiteStorage) GetBySubject(subject string) ([]extractor.Quad, error) {
-	rows, err := s.db.Query(`
-		SELECT subject, relationship, value, citation
+	return s.queryQuads(`
+		SELECT subject, relationship, value, citation, subject_url
 		FROM quads
 		WHERE subject LIKE ?
 		ORDER BY extracted_at DESC
 	`, "%"+subject+"%")
+}
+
+// GetByRelationship retrieves all quads with a specific relationship
+func (s *SQLiteStorage) GetByRelationship(relationship string) ([]extractor.Quad, error) {
+	return s.queryQuads(`
+		SELECT subject, relationship, value, citation, subject_url
+		FROM quads
+		WHERE relationship LIKE ?
+		ORDER BY extracted_at DESC
+	`, "%"+relationship+"%")
+}
+
+// GetByValue retrieves all quads whose value contains value, ignoring case,
+// or, when exact is set, equals it exactly. Exact lookups use the value index,
+// so they stay fast on large databases; substring lookups scan every quad.
+func (s *SQLiteStorage) GetByValue(value string, exact bool) ([]extractor.Quad, error) {
+	if exact {
+		return s.queryQuads(`
+			SELECT subject, relationship, value, citation, subject_url
+			FROM quads
+			WHERE value = ?
+			ORDER BY extracted_at DESC
+		`, value)
+	}
+	return s.queryQuads(`
+		SELECT subject, relationship, value, citation, subject_url
+		FROM quads
+		WHERE value LIKE ?
+		ORDER BY extracted_at DESC
+	`, "%"+value+"%")
+}
+
+// GetBySourceURL retrieves all quads from a specific source URL
+func (s *SQLiteStorage) GetBySourceURL(sourceURL string) ([]extractor.Quad, error) {
+	return s.queryQuads(`
+		SELECT subject, relationship, value, citation, subject_url
+		FROM quads
+		WHERE source_url = ?
+		ORDER BY extracted_at DESC
+	`, sourceURL)
+}
+
+// GetByTag retrieves all quads stored with exactly the given tag
+func (s *SQLiteStorage) GetByTag(tag string) ([]extractor.Quad, error) {
+	return s.queryQuads(`
+		SELECT subject, relationship, value, citation, subject_url
+		FROM quads
+		WHERE tag = ?
+		ORDER BY extracted_at DESC
+	`, tag)
+}
+
+// Search searches quads by text in any field. Terms can be combined with
+// AND and OR, as described by parseSearchQuery.
+func (s *SQLiteStorage) Search(query string) ([]extractor.Quad, error) {
+	where, args := searchClause(query)
+	return s.queryQuads(`
+		SELECT subject, relationship, value, citation, subject_url
+		FROM quads
+		WHERE `+where+`
+		ORDER BY extracted_at DESC
+	`, args...)
+}
+
+// GetLatestBySourceURL retrieves the quads from the most recent extraction of a source URL
+func (s *SQLiteStorage) GetLatestBySourceURL(sourceURL string) ([]extractor.Quad, error) {
+	// julianday compares timestamps stored with different UTC offsets by
+	// the instant they name rather than as text
+	return s.queryQuads(`
+		SELECT subject, relationship, value, citation, subject_url
+		FROM quads
+		WHERE source_url = ?
+		AND julianday(extracted_at) = (SELECT MAX(julianday(extracted_at)) FROM quads WHERE source_url = ?)
+		ORDER BY id
+	`, sourceURL, sourceURL)
+}
+
+// GetByTimeRange retrieves all quads extracted within [since, until]. A zero
+// time leaves that end of the range unbounded.
+func (s *SQLiteStorage) GetByTimeRange(since, until time.Time) ([]extractor.Quad, error) {
+	// julianday normalizes timestamps stored with different UTC offsets
+	query := `
+		SELECT subject, relationship, value, citation, subject_url
+		FROM quads
+		WHERE 1 = 1`
+	var args []interface{}
+	
+	if !since.IsZero() {
+		query += " AND julianday(extracted_at) >= julianday(?)"
+		args = append(args, since.UTC())
+	}
+	if !until.IsZero() {
+		query += " AND julianday(extracted_at) <= julianday(?)"
+		args = append(args, until.UTC())
+	}
+	query += " ORDER BY extracted_at DESC"
+	
+	return s.queryQuads(query, args...)
+}
+
+// GetExtractionTimes lists when a source URL was extracted, most recent first
+func (s *SQLiteStorage) GetExtractionTimes(sourceURL string) ([]time.Time, error) {
+	rows, err := s.db.Query(`
+		SELECT DISTINCT extracted_at
+		FROM quads
+		WHERE source_url = ?
+		ORDER BY julianday(extracted_at) DESC
+	`, sourceURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query quads: %w", err)
+		return nil, fmt.Errorf("failed to query extraction times: %w", err)
 	}
 	defer rows.Close()
 	
-	var quads []extractor.Quad
+	var times []time.Time
 	for rows.Next() {
-		var quad extractor.Quad
-		err := rows.Scan(&quad.Subject, &quad.Relationship, &quad.Value, &quad.Citation)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan quad: %w", err)
+		var extractedAt time.Time
+		if err := rows.Scan(&extractedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan extraction time: %w", err)
 		}
-		quads = append(quads, quad)
+		times = append(times, extractedAt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read extraction times: %w", err)
 	}
 	
-	return quads, nil
+	return times, nil
 }
 
-// GetByRelationship retrieves all quads with a specific relationship
-func (s *SQLiteStorage) GetByRelationship(relationship string) ([]extractor.Quad, error) {
-	rows, err := s.db.Query(`
-		SELECT subject, relationship, value, citation
+// GetSnapshot retrieves the quads from the latest extraction of a source URL
+// at or before a time, along with when that extraction happened. If there is
+// no such extraction, no quads and a zero time are returned.
+func (s *SQLiteStorage) GetSnapshot(sourceURL string, at time.Time) ([]extractor.Quad, time.Time, error) {
+	// Match the stored timestamp text exactly rather than round-tripping it through time.Time
+	snapshot := `
+		SELECT extracted_at
 		FROM quads
-		WHERE relationship LIKE ?
-		ORDER BY extracted_at DESC
-	`, "%"+relationship+"%")
+		WHERE source_url = ? AND julianday(extracted_at) <= julianday(?)
+		ORDER BY julianday(extracted_at) DESC
+		LIMIT 1`
+	
+	var extractedAt time.Time
+	err := s.db.QueryRow(snapshot, sourceURL, at.UTC()).Scan(&extractedAt)
+	if err == sql.ErrNoRows {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to find snapshot: %w", err)
+	}
+	
+	quads, err := s.queryQuads(`
+		SELECT subject, relationship, value, citation, subject_url
+		FROM quads
+		WHERE source_url = ? AND extracted_at = (`+snapshot+`)
+		ORDER BY id
+	`, sourceURL, sourceURL, at.UTC())
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	
+	return quads, extractedAt, nil
+}
+
+// queryQuads runs a query selecting subject, relationship, value, citation and subject_url and scans the resulting quads
+func (s *SQLiteStorage) queryQuads(query string, args ...interface{}) ([]extractor.Quad, error) {
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query quads: %w", err)
 	}
@@ -184,66 +774,82 @@ func (s *SQLiteStorage) GetByRelationship(relationship string) ([]extractor.Quad
 	var quads []extractor.Quad
 	for rows.Next() {
 		var quad extractor.Quad
-		err := rows.Scan(&quad.Subject, &quad.Relationship, &quad.Value, &quad.Citation)
+		var citation, subjectURL sql.NullString
+		err := rows.Scan(&quad.Subject, &quad.Relationship, &quad.Value, &citation, &subjectURL)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan quad: %w", err)
 		}
+		quad.Citation = citation.String
+		quad.SubjectURL = subjectURL.String
 		quads = append(quads, quad)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read quads: %w", err)
+	}
 	
 	return quads, nil
 }
 
-// GetBySourceURL retrieves all quads from a specific source URL
-func (s *SQLiteStorage) GetBySourceURL(sourceURL string) ([]extractor.Quad, error) {
+// GetSubjectLinks retrieves the links between stored subjects. A quad links
+// its subject to another subject when its value is exactly that subject's
+// name. Each distinct link is returned once.
+func (s *SQLiteStorage) GetSubjectLinks() ([]SubjectLink, error) {
 	rows, err := s.db.Query(`
-		SELECT subject, relationship, value, citation
+		SELECT DISTINCT subject, relationship, value
 		FROM quads
-		WHERE source_url = ?
-		ORDER BY extracted_at DESC
-	`, sourceURL)
+		WHERE value != subject
+		AND value IN (SELECT subject FROM quads)
+		ORDER BY subject, relationship, value
+	`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query quads: %w", err)
+		return nil, fmt.Errorf("failed to query subject links: %w", err)
 	}
 	defer rows.Close()
 	
-	var quads []extractor.Quad
+	var links []SubjectLink
 	for rows.Next() {
-		var quad extractor.Quad
-		err := rows.Scan(&quad.Subject, &quad.Relationship, &quad.Value, &quad.Citation)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan quad: %w", err)
+		var link SubjectLink
+		if err := rows.Scan(&link.Subject, &link.Relationship, &link.Target); err != nil {
+			return nil, fmt.Errorf("failed to scan subject link: %w", err)
 		}
-		quads = append(quads, quad)
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read subject links: %w", err)
 	}
 	
-	return quads, nil
+	return links, nil
 }
 
-// Search searches quads by text in any field
-func (s *SQLiteStorage) Search(query string) ([]extractor.Quad, error) {
+// GetCorroborated retrieves the facts whose exact subject, relationship and
+// value were stored from at least minSources distinct source URLs, most
+// corroborated first
+func (s *SQLiteStorage) GetCorroborated(minSources int) ([]CorroboratedFact, error) {
 	rows, err := s.db.Query(`
-		SELECT subject, relationship, value, citation
+		SELECT subject, relationship, value, COUNT(DISTINCT source_url) AS sources
 		FROM quads
-		WHERE subject LIKE ? OR relationship LIKE ? OR value LIKE ? OR citation LIKE ?
-		ORDER BY extracted_at DESC
-	`, "%"+query+"%", "%"+query+"%", "%"+query+"%", "%"+query+"%")
+		GROUP BY subject, relationship, value
+		HAVING COUNT(DISTINCT source_url) >= ?
+		ORDER BY sources DESC, subject, relationship, value
+	`, minSources)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query quads: %w", err)
+		return nil, fmt.Errorf("failed to query corroborated facts: %w", err)
 	}
 	defer rows.Close()
 	
-	var quads []extractor.Quad
+	var facts []CorroboratedFact
 	for rows.Next() {
-		var quad extractor.Quad
-		err := rows.Scan(&quad.Subject, &quad.Relationship, &quad.Value, &quad.Citation)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan quad: %w", err)
+		var fact CorroboratedFact
+		if err := rows.Scan(&fact.Subject, &fact.Relationship, &fact.Value, &fact.Sources); err != nil {
+			return nil, fmt.Errorf("failed to scan corroborated fact: %w", err)
 		}
-		quads = append(quads, quad)
+		facts = append(facts, fact)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read corroborated facts: %w", err)
 	}
 	
-	return quads, nil
+	return facts, nil
 }
 
 // GetStats returns storage statistics
@@ -274,9 +880,166 @@ func (s *SQLiteStorage) GetStats() (*Stats, error) {
 		stats.LastExtraction = "Never"
 	}
 	
+	tags, err := s.getTagStats()
+	if err != nil {
+		return nil, err
+	}
+	stats.Tags = tags
+	
 	return &stats, nil
 }
 
+// getTagStats counts quads, subjects and sources per tag, untagged quads
+// last. It returns nothing when no quads are tagged.
+func (s *SQLiteStorage) getTagStats() ([]TagStats, error) {
+	rows, err := s.db.Query(`
+		SELECT COALESCE(tag, ''), COUNT(*), COUNT(DISTINCT subject), COUNT(DISTINCT source_url)
+		FROM quads
+		GROUP BY COALESCE(tag, '')
+		ORDER BY COALESCE(tag, '') = '', COALESCE(tag, '')
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag stats: %w", err)
+	}
+	defer rows.Close()
+	
+	var tags []TagStats
+	tagged := false
+	for rows.Next() {
+		var t TagStats
+		if err := rows.Scan(&t.Tag, &t.Quads, &t.Subjects, &t.Sources); err != nil {
+			return nil, fmt.Errorf("failed to scan tag stats: %w", err)
+		}
+		tagged = tagged || t.Tag != ""
+		tags = append(tags, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tag stats: %w", err)
+	}
+	
+	if !tagged {
+		return nil, nil
+	}
+	return tags, nil
+}
+
+// GetSource retrieves the fetch metadata recorded for a source URL, or nil if none exists
+func (s *SQLiteStorage) GetSource(sourceURL string) (*SourceRecord, error) {
+	var source SourceRecord
+	var etag, lastModified, contentHash sql.NullString
+	var pageID, revisionID sql.NullInt64
+	var pageLastModified sql.NullTime
+	
+	err := s.db.QueryRow(`
+		SELECT source_url, etag, last_modified, content_hash, page_id, revision_id, page_last_modified, fetched_at
+		FROM sources
+		WHERE source_url = ?
+	`, sourceURL).Scan(&source.SourceURL, &etag, &lastModified, &contentHash, &pageID, &revisionID, &pageLastModified, &source.FetchedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source: %w", err)
+	}
+	
+	source.ETag = etag.String
+	source.LastModified = lastModified.String
+	source.ContentHash = contentHash.String
+	source.PageID = pageID.Int64
+	source.RevisionID = revisionID.Int64
+	source.PageLastModified = pageLastModified.Time
+	
+	return &source, nil
+}
+
+// SaveSource records the fetch metadata for a source URL, replacing any previous record
+func (s *SQLiteStorage) SaveSource(source SourceRecord) error {
+	return saveSource(s.db, source)
+}
+
+// saveSource writes a source record with db, which may be a transaction
+func saveSource(db execer, source SourceRecord) error {
+	_, err := db.Exec(`
+		INSERT INTO sources (source_url, etag, last_modified, content_hash, page_id, revision_id, page_last_modified, fetched_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(source_url) DO UPDATE SET
+			etag = excluded.etag,
+			last_modified = excluded.last_modified,
+			content_hash = excluded.content_hash,
+			page_id = excluded.page_id,
+			revision_id = excluded.revision_id,
+			page_last_modified = excluded.page_last_modified,
+			fetched_at = excluded.fetched_at
+	`, source.SourceURL, source.ETag, source.LastModified, source.ContentHash, source.PageID, source.RevisionID,
+		sql.NullTime{Time: source.PageLastModified, Valid: !source.PageLastModified.IsZero()}, source.FetchedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save source: %w", err)
+	}
+	
+	return nil
+}
+
+// GetIdempotencyRecord retrieves the response recorded for an idempotency key, or nil if none exists
+func (s *SQLiteStorage) GetIdempotencyRecord(key string) (*IdempotencyRecord, error) {
+	var record IdempotencyRecord
+	err := s.db.QueryRow(`
+		SELECT key, request_hash, status_code, body, created_at
+		FROM idempotency_keys
+		WHERE key = ?
+	`, key).Scan(&record.Key, &record.RequestHash, &record.StatusCode, &record.Body, &record.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency record: %w", err)
+	}
+	
+	return &record, nil
+}
+
+// SaveIdempotencyRecord records the response for an idempotency key, replacing any previous record
+func (s *SQLiteStorage) SaveIdempotencyRecord(record IdempotencyRecord) error {
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO idempotency_keys (key, request_hash, status_code, body, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, record.Key, record.RequestHash, record.StatusCode, record.Body, record.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save idempotency record: %w", err)
+	}
+	
+	return nil
+}
+
+// DeleteIdempotencyRecordsBefore removes idempotency records created before a time
+func (s *SQLiteStorage) DeleteIdempotencyRecordsBefore(before time.Time) error {
+	_, err := s.db.Exec(`
+		DELETE FROM idempotency_keys
+		WHERE julianday(created_at) < julianday(?)
+	`, before.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to delete idempotency records: %w", err)
+	}
+	
+	return nil
+}
+
+// SetConnectionLimits overrides the connection pool size. Long-running
+// processes that share one storage across goroutines use it to size the pool
+// to their workload; maxOpen <= 0 means no limit.
+func (s *SQLiteStorage) SetConnectionLimits(maxOpen, maxIdle int) {
+	s.db.SetMaxOpenConns(maxOpen)
+	s.db.SetMaxIdleConns(maxIdle)
+}
+
+// Ping checks that the database answers a query, giving up when ctx is done
+func (s *SQLiteStorage) Ping(ctx context.Context) error {
+	var one int
+	if err := s.db.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+		return fmt.Errorf("database ping failed: %w", err)
+	}
+	return nil
+}
+
 // Close closes the storage connection
 func (s *SQLiteStorage) Close() error {
 	return s.db.Close()
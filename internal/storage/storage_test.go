@@ -0,0 +1,296 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+// newTestStorage opens a fresh database in a temporary directory
+func newTestStorage(t testing.TB) *SQLiteStorage {
+	t.Helper()
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// testQuads returns n quads about one subject, every third of them cited
+func testQuads(n int) []extractor.Quad {
+	quads := make([]extractor.Quad, n)
+	for i := range quads {
+		quads[i] = extractor.Quad{
+			Subject:      "Examplia",
+			Relationship: fmt.Sprintf("Fact %d", i),
+			Value:        fmt.Sprintf("Value %d", i),
+		}
+		if i%3 == 0 {
+			quads[i].Citation = fmt.Sprintf("https://source%d.example.org/page", i%7)
+		}
+	}
+	return quads
+}
+
+func TestStoreAcrossBatches(t *testing.T) {
+	store := newTestStorage(t)
+	const sourceURL = "https://en.wikipedia.org/wiki/Examplia"
+	quads := testQuads(2*insertBatchSize + 17)
+
+	if err := store.Store(quads, sourceURL, time.Now(), "batch"); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := store.GetBySourceURL(sourceURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != len(quads) {
+		t.Fatalf("stored %d quads, want %d", len(stored), len(quads))
+	}
+	byRelationship := make(map[string]extractor.Quad, len(stored))
+	for _, q := range stored {
+		byRelationship[q.Relationship] = q
+	}
+	for _, q := range quads {
+		got, ok := byRelationship[q.Relationship]
+		if !ok || got.Value != q.Value || got.Citation != q.Citation {
+			t.Fatalf("stored %+v, want %+v", got, q)
+		}
+	}
+
+	// Citation domains are indexed against the right rows in every batch
+	cited, err := store.GetByCitationDomain("source3.example.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := 0
+	for _, q := range quads {
+		if q.Citation == "https://source3.example.org/page" {
+			want++
+		}
+	}
+	if len(cited) != want {
+		t.Fatalf("GetByCitationDomain found %d quads, want %d", len(cited), want)
+	}
+	for _, q := range cited {
+		if q.Citation != "https://source3.example.org/page" {
+			t.Errorf("quad %q indexed under the wrong domain: %s", q.Relationship, q.Citation)
+		}
+	}
+}
+
+// BenchmarkStore measures Store's multi-row INSERT batches against
+// inserting the same quads one row per statement, as Store used to
+func BenchmarkStore(b *testing.B) {
+	for _, n := range []int{100, 1000, 10000} {
+		quads := testQuads(n)
+
+		b.Run(fmt.Sprintf("batched/%d", n), func(b *testing.B) {
+			store := newTestStorage(b)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := store.Store(quads, fmt.Sprintf("https://en.wikipedia.org/wiki/B%d", i), time.Now(), ""); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("row-by-row/%d", n), func(b *testing.B) {
+			store := newTestStorage(b)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := storeRowByRow(store, quads, fmt.Sprintf("https://en.wikipedia.org/wiki/R%d", i)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// storeRowByRow stores quads in one transaction with one INSERT per quad
+func storeRowByRow(store *SQLiteStorage, quads []extractor.Quad, sourceURL string) error {
+	tx, err := store.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	extractedAt := time.Now()
+	for i := range quads {
+		if err := insertQuadBatch(tx, quads[i:i+1], sourceURL, extractedAt, ""); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func TestGetLatestBySourceURLAcrossOffsets(t *testing.T) {
+	store := newTestStorage(t)
+	const sourceURL = "https://en.wikipedia.org/wiki/Examplia"
+
+	// 10:30 in UTC+2 sorts after 09:00 UTC as text but is the earlier instant
+	earlier := time.Date(2024, 1, 1, 10, 30, 0, 0, time.FixedZone("UTC+2", 2*60*60))
+	later := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	if err := store.Store([]extractor.Quad{{Subject: "Examplia", Relationship: "Population", Value: "100"}}, sourceURL, later, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Store([]extractor.Quad{{Subject: "Examplia", Relationship: "Population", Value: "90"}}, sourceURL, earlier, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	quads, err := store.GetLatestBySourceURL(sourceURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quads) != 1 || quads[0].Value != "100" {
+		t.Fatalf("GetLatestBySourceURL = %+v, want the extraction at 09:00 UTC", quads)
+	}
+}
+
+func TestReplaceSource(t *testing.T) {
+	store := newTestStorage(t)
+	const sourceURL = "https://en.wikipedia.org/wiki/Examplia"
+	first := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	for i, value := range []string{"90", "100"} {
+		if err := store.Store([]extractor.Quad{{Subject: "Examplia", Relationship: "Population", Value: value}}, sourceURL, first.Add(time.Duration(i)*time.Hour), "census"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other := "https://en.wikipedia.org/wiki/Otherland"
+	if err := store.Store(testQuads(2), other, first, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ReplaceSource([]extractor.Quad{{Subject: "Examplia", Relationship: "Population", Value: "120"}}, sourceURL, first.Add(24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	times, err := store.GetExtractionTimes(sourceURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(times) != 1 {
+		t.Fatalf("%d extractions stored, want only the replacement", len(times))
+	}
+	tagged, err := store.GetByTag("census")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tagged) != 1 || tagged[0].Value != "120" {
+		t.Errorf("quads tagged census = %+v, want the replacement", tagged)
+	}
+	kept, err := store.GetLatestBySourceURL(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) != 2 {
+		t.Errorf("%d quads left for another source, want 2", len(kept))
+	}
+}
+
+func TestRefreshSourceKeepsHistory(t *testing.T) {
+	store := newTestStorage(t)
+	const sourceURL = "https://en.wikipedia.org/wiki/Examplia"
+	first := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	second := first.Add(24 * time.Hour)
+
+	if err := store.Store([]extractor.Quad{{Subject: "Examplia", Relationship: "Population", Value: "100"}}, sourceURL, first, "census"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RefreshSource([]extractor.Quad{{Subject: "Examplia", Relationship: "Population", Value: "120"}}, sourceURL, second); err != nil {
+		t.Fatal(err)
+	}
+
+	times, err := store.GetExtractionTimes(sourceURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(times) != 2 {
+		t.Fatalf("%d extractions stored, want the original and the refresh", len(times))
+	}
+	previous, _, err := store.GetSnapshot(sourceURL, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(previous) != 1 || previous[0].Value != "100" {
+		t.Errorf("snapshot before the refresh = %+v, want the original extraction", previous)
+	}
+	latest, err := store.GetLatestBySourceURL(sourceURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(latest) != 1 || latest[0].Value != "120" {
+		t.Errorf("latest extraction = %+v, want the refresh", latest)
+	}
+
+	// The refresh keeps the source's tag
+	tagged, err := store.GetByTag("census")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tagged) != 2 {
+		t.Errorf("%d quads tagged census, want 2", len(tagged))
+	}
+}
+
+func TestMigrateRunsOnce(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	store, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A database written by an older version, before any migration ran
+	const insert = `INSERT INTO quads (subject, relationship, value, citation, source_url, extracted_at)
+		VALUES ('Examplia', 'Capital', 'Exampleburg', 'no citation', 'https://en.wikipedia.org/wiki/Examplia', ?)`
+	if _, err := store.db.Exec(insert, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.db.Exec("PRAGMA user_version = 0"); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	countPlaceholders := func(store *SQLiteStorage) int {
+		t.Helper()
+		var n int
+		if err := store.db.QueryRow("SELECT COUNT(*) FROM quads WHERE citation = 'no citation'").Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	store, err = NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := countPlaceholders(store); n != 0 {
+		t.Errorf("%d placeholder citations left after migrating", n)
+	}
+	var version int
+	if err := store.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != len(migrations) {
+		t.Errorf("user_version = %d, want %d", version, len(migrations))
+	}
+
+	// Opening a migrated database doesn't run the migration again
+	if _, err := store.db.Exec(insert, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+	store, err = NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if n := countPlaceholders(store); n != 1 {
+		t.Errorf("%d placeholder citations after reopening, want the 1 written since", n)
+	}
+}
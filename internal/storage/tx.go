@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chetankale/wikipedia-extraction/internal/extractor"
+)
+
+// Tx is a storage transaction grouping the quads and fetch metadata of
+// several sources into one atomic unit, for all-or-nothing bulk loads.
+// Nothing written through it is visible to other readers until Commit, and
+// Rollback discards all of it.
+//
+// While a Tx is open, write through it rather than through the Storage that
+// began it: SQLite allows one writer at a time, so another write waits for
+// the transaction to end and fails once the busy timeout runs out.
+type Tx interface {
+	// Store stores a collection of quads with metadata, like Storage.Store
+	Store(quads []extractor.Quad, sourceURL string, extractedAt time.Time, tag string) error
+
+	// SaveSource records the fetch metadata for a source URL, like Storage.SaveSource
+	SaveSource(source SourceRecord) error
+
+	// Commit makes everything written through the transaction permanent
+	Commit() error
+
+	// Rollback discards everything written through the transaction. It does
+	// nothing once the transaction is committed, so it can be deferred.
+	Rollback() error
+}
+
+// execer runs a statement on a database or within a transaction
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// sqliteTx implements Tx with a database/sql transaction
+type sqliteTx struct {
+	tx *sql.Tx
+}
+
+// Begin starts a transaction. SQLite takes the write lock on the first
+// write, and holds it until Commit or Rollback.
+func (s *SQLiteStorage) Begin() (Tx, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return &sqliteTx{tx: tx}, nil
+}
+
+func (t *sqliteTx) Store(quads []extractor.Quad, sourceURL string, extractedAt time.Time, tag string) error {
+	return insertQuads(t.tx, quads, sourceURL, extractedAt, tag)
+}
+
+func (t *sqliteTx) SaveSource(source SourceRecord) error {
+	return saveSource(t.tx, source)
+}
+
+func (t *sqliteTx) Commit() error {
+	if err := t.tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (t *sqliteTx) Rollback() error {
+	if err := t.tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		return fmt.Errorf("failed to roll back transaction: %w", err)
+	}
+	return nil
+}